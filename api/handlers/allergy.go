@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// @Summary List a patient's allergies
+// @Description Lists the substances the patient is allergic to. Patients see their own; clinical staff see everyone's.
+// @Tags Allergy
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient (user) ID"
+// @Success 200 {object} entity.ListAllergies
+// @Failure 403 {object} entity.Error
+// @Router /user/{id}/allergies [get]
+func (h *HandlerV1) ListAllergies(c *gin.Context) {
+	patientID := c.Param("id")
+
+	userID, role, err := h.caller(c)
+	if err != nil || (userID != patientID && role == entity.RoleUser) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	allergies, err := h.Service.Allergy().List(ctx, patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.ListAllergies{
+		Allergies:  allergies,
+		TotalCount: int64(len(allergies)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Record an allergy
+// @Description Records a substance the patient is allergic to. Prescriptions containing it raise an alert.
+// @Tags Allergy
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient (user) ID"
+// @Param allergy body entity.AllergyRequest true "Allergy"
+// @Success 201 {object} entity.Allergy
+// @Failure 400 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /user/{id}/allergy [post]
+func (h *HandlerV1) CreateAllergy(c *gin.Context) {
+	var body entity.AllergyRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	userID, _, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	patient, err := h.Service.User().Get(ctx, map[string]string{"id": c.Param("id")})
+	if err != nil {
+		c.JSON(http.StatusNotFound, entity.Error{Message: "Patient not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	allergy, err := h.Service.Allergy().Create(ctx, &entity.Allergy{
+		PatientID: patient.ID,
+		Substance: strings.ToLower(strings.TrimSpace(body.Substance)),
+		Reaction:  body.Reaction,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, allergy)
+}
+
+// @Security BearerAuth
+// @Summary Remove an allergy
+// @Description Removes an allergy recorded in error
+// @Tags Allergy
+// @Accept json
+// @Produce json
+// @Param id path string true "Patient (user) ID"
+// @Param allergy_id path int true "Allergy ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} entity.Error
+// @Router /user/{id}/allergy/{allergy_id} [delete]
+func (h *HandlerV1) DeleteAllergy(c *gin.Context) {
+	allergyID, err := strconv.ParseInt(c.Param("allergy_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	if err = h.Service.Allergy().Delete(ctx, c.Param("id"), allergyID); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Allergy not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Allergy deleted"})
+}
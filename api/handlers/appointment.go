@@ -7,23 +7,37 @@ import (
 	"time"
 
 	"github.com/Abdulazizxoshimov/Hospital/entity"
+	tokens "github.com/Abdulazizxoshimov/Hospital/pkg/token"
 	"github.com/gin-gonic/gin"
 )
 
 // @Security BearerAuth
 // @Summary Create an appointment
-// @Description API for creating a new appointment
+// @Description API for creating a new appointment. AppointmentTypeID selects the visit type; its (doctor specific) duration and buffer decide how much availability is consumed. Without a type the visit lasts one hour. Appointment_time.start_time must be RFC 3339 with an offset, e.g. 2025-03-01T09:00:00+05:00. Patients always book for themselves; receptionists and admins may book for another patient via UserID.
 // @Tags Appointment
 // @Accept json
 // @Produce json
 // @Param appointment body entity.Appointment true "Appointment details"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Param Idempotency-Key header string false "Retries with the same key and payload replay the first response"
 // @Success 201 {object} entity.Appointment
 // @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
 // @Failure 500 {object} entity.Error
+// @Failure 422 {object} entity.Error
 // @Router /appointment [post]
 func (h *HandlerV1) CreateAppointment(c *gin.Context) {
 	var appointment entity.Appointment
 
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
 	if err := c.ShouldBindJSON(&appointment); err != nil {
 		c.JSON(http.StatusBadRequest, entity.Error{
 			Message: "Invalid request data",
@@ -32,19 +46,30 @@ func (h *HandlerV1) CreateAppointment(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	// slots held or offered to a patient are only theirs to book, so
+	// patients always book for themselves
+	userID, role, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, entity.Error{Message: err.Error()})
+		return
+	}
+	if appointment.UserID == "" || (role != entity.RoleReceptionist && role != entity.RoleAdmin) {
+		appointment.UserID = userID
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	createdAppointment, err := h.Service.Appointment().CreateAppointment(ctx, &appointment)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, entity.Error{
-			Message: "Failed to create appointment",
+		c.JSON(statusFromError(err), entity.Error{
+			Message: "Failed to create appointment: " + err.Error(),
 		})
 		h.Logger.Error(err.Error())
 		return
 	}
 
-	c.JSON(http.StatusCreated, createdAppointment)
+	c.JSON(http.StatusCreated, localizeAppointment(createdAppointment, loc))
 }
 
 // @Security  		BearerAuth
@@ -55,10 +80,20 @@ func (h *HandlerV1) CreateAppointment(c *gin.Context) {
 // @Produce 		json
 // @Param page query string true "Page"
 // @Param limit query string true "Limit"
+// @Param doctor_id query string false "Only appointments of this doctor"
+// @Param branch_id query int false "Only appointments at this branch"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
 // @Success 200 {array} entity.ListAppointments
 // @Failure 400 {object} entity.Error
 // @Router /appointments [get]
 func (h *HandlerV1) GetAppointments(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
 	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
 	if err != nil || page < 1 {
 		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid page number"})
@@ -73,15 +108,31 @@ func (h *HandlerV1) GetAppointments(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	var branchID int64
+	if raw := c.Query("branch_id"); raw != "" {
+		if branchID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid branch_id"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
-	listApp, totalCount, err := h.Service.Appointment().ListAppointments(ctx, page, limit)
+	listApp, totalCount, err := h.Service.Appointment().ListAppointments(ctx, &entity.AppointmentFilter{
+		DoctorID: c.Query("doctor_id"),
+		BranchID: branchID,
+		Page:     page,
+		Limit:    limit,
+	})
 	if err != nil {
 		c.JSON(http.StatusNotFound, entity.Error{Message: "Appointments not found"})
 		h.Logger.Error(err.Error())
 		return
 	}
+	for _, appointment := range listApp {
+		localizeAppointment(appointment, loc)
+	}
 
 	c.JSON(http.StatusOK, entity.ListAppointments{
 		Appointments: listApp,
@@ -89,6 +140,169 @@ func (h *HandlerV1) GetAppointments(c *gin.Context) {
 	})
 }
 
+// @Security BearerAuth
+// @Summary List my appointments
+// @Description Returns the calling patient's appointments. upcoming lists visits from now on, earliest first; past lists earlier visits, latest first.
+// @Tags Appointment
+// @Accept json
+// @Produce json
+// @Param when query string false "upcoming (default), past or all"
+// @Param status query string false "Only appointments with this status"
+// @Param page query int false "Page, defaults to 1"
+// @Param limit query int false "Limit, defaults to 10"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListAppointments
+// @Failure 400 {object} entity.Error
+// @Failure 401 {object} entity.Error
+// @Router /me/appointments [get]
+func (h *HandlerV1) ListMyAppointments(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	userID, status := tokens.GetIdFromToken(c.Request, &h.Config)
+	if status != 0 {
+		c.JSON(status, entity.Error{Message: "Unauthorized"})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid page number"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid limit value"})
+		return
+	}
+
+	filter := &entity.AppointmentFilter{
+		PatientID: userID,
+		Page:      page,
+		Limit:     limit,
+	}
+	switch c.DefaultQuery("when", "upcoming") {
+	case "upcoming":
+		filter.StartFrom = time.Now()
+	case "past":
+		filter.StartTo = time.Now()
+		filter.Descending = true
+	case "all":
+	default:
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "when must be upcoming, past or all"})
+		return
+	}
+	if appointmentStatus := c.Query("status"); appointmentStatus != "" {
+		filter.Statuses = []string{appointmentStatus}
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointments, total, err := h.Service.Appointment().ListAppointments(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, appointment := range appointments {
+		localizeAppointment(appointment, loc)
+	}
+
+	c.JSON(http.StatusOK, entity.ListAppointments{
+		Appointments: appointments,
+		TotalCount:   int64(total),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Doctor agenda
+// @Description Returns the calling doctor's appointments for a day or a week (Monday to Sunday) with patient names. Cancelled appointments are left out unless include_cancelled is set. Admins pass doctor_id.
+// @Tags Appointment
+// @Accept json
+// @Produce json
+// @Param date query string false "Day, YYYY-MM-DD in the tz zone, defaults to today"
+// @Param range query string false "day (default) or week"
+// @Param include_cancelled query bool false "Include cancelled appointments"
+// @Param doctor_id query string false "Doctor ID, admins only"
+// @Param tz query string false "IANA time zone for the day boundaries and returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.DoctorAgenda
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Router /doctor/agenda [get]
+func (h *HandlerV1) GetDoctorAgenda(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if raw := c.Query("date"); raw != "" {
+		if from, err = time.ParseInLocation("2006-01-02", raw, loc); err != nil {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "date must be YYYY-MM-DD"})
+			return
+		}
+	}
+
+	var to time.Time
+	switch c.DefaultQuery("range", "day") {
+	case "day":
+		to = from.AddDate(0, 0, 1)
+	case "week":
+		from = from.AddDate(0, 0, -(int(from.Weekday())+6)%7)
+		to = from.AddDate(0, 0, 7)
+	default:
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "range must be day or week"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, c.Query("doctor_id"))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		return
+	}
+
+	filter := &entity.AppointmentFilter{
+		DoctorID:  doctorID,
+		StartFrom: from,
+		StartTo:   to,
+	}
+	if includeCancelled, _ := strconv.ParseBool(c.Query("include_cancelled")); !includeCancelled {
+		for _, appointmentStatus := range entity.AppointmentStatuses {
+			if appointmentStatus != entity.AppointmentStatusCancelled {
+				filter.Statuses = append(filter.Statuses, appointmentStatus)
+			}
+		}
+	}
+
+	entries, err := h.Service.Appointment().DoctorAgenda(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, entry := range entries {
+		localizeAppointment(&entry.Appointment, loc)
+	}
+
+	c.JSON(http.StatusOK, entity.DoctorAgenda{
+		DoctorID: doctorID,
+		From:     from,
+		To:       to,
+		Entries:  entries,
+	})
+}
+
 // @Security  		BearerAuth
 // @Summary   		List User
 // @Description 	Api for getting list user
@@ -96,10 +310,19 @@ func (h *HandlerV1) GetAppointments(c *gin.Context) {
 // @Accept 			json
 // @Produce 		json
 // @Param id path int true "Appointment ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
 // @Success 200 {object} entity.Appointment
+// @Failure 403 {object} entity.Error
 // @Failure 404 {object} entity.Error
 // @Router /appointment/{id} [get]
 func (h *HandlerV1) GetAppointmentByID(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
@@ -107,7 +330,7 @@ func (h *HandlerV1) GetAppointmentByID(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	appointment, err := h.Service.Appointment().GetAppointment(ctx, id)
@@ -117,20 +340,37 @@ func (h *HandlerV1) GetAppointmentByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, appointment)
+	// the patient, staff and the doctor seeing the patient may read it
+	allowed := false
+	for _, role := range []string{entity.RoleAdmin, entity.RoleReceptionist, entity.RoleDoctor, entity.RoleUser} {
+		if h.canAccessAppointment(ctx, c, appointment, role) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, localizeAppointment(appointment, loc))
 }
 
 // @Security  		BearerAuth
-// @Summary   		List User
-// @Description 	Api for getting list user
+// @Summary   		Reschedule Appointment
+// @Description 	Api for moving an appointment, subject to the cancellation policy
 // @Tags 			Appointment
 // @Accept 			json
 // @Produce 		json
 // @Param id path int true "Appointment ID"
 // @Param date query string true "New appointment date"
+// @Param override query bool false "Admin override of the policy, if the policy allows it"
 // @Success 200 {object} entity.UserCreateResponse
 // @Failure 400 {object} entity.Error
-// @Router /appointment [put]
+// @Failure 403 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Failure 422 {object} entity.Error
+// @Router /appointment/{id} [put]
 func (h *HandlerV1) UpdateAppointment(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -147,48 +387,69 @@ func (h *HandlerV1) UpdateAppointment(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
-	err = h.Service.Appointment().UpdateAppointment(ctx, id, parsedDate)
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, entity.Error{Message: "Failed to update appointment"})
+		c.JSON(statusFromError(err), entity.Error{Message: "Appointment not found"})
 		h.Logger.Error(err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, entity.UserCreateResponse{ID: strconv.Itoa(id)})
-}
+	allowed := false
+	for _, role := range []string{entity.RoleAdmin, entity.RoleReceptionist, entity.RoleUser} {
+		if h.canAccessAppointment(ctx, c, appointment, role) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
 
-// @Security  		BearerAuth
-// @Summary   		List User
-// @Description 	Api for getting list user
-// @Tags 			Appointment
-// @Accept 			json
-// @Produce 		json
-// @Param id path int true "Appointment ID"
-// @Success 200 {object} map[string]string
-// @Failure 404 {object} entity.Error
-// @Router /appointment/{id} [delete]
-func (h *HandlerV1) DeleteAppointment(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
+	policy, err := h.Service.CancellationPolicy().Get(ctx, appointment.AppointmentTypeID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
 		h.Logger.Error(err.Error())
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
-	defer cancel()
+	_, role, _ := h.caller(c)
+	if err = policy.CheckReschedule(appointment, time.Now(), h.policyOverride(c, role)); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		return
+	}
 
-	err = h.Service.Appointment().DeleteAppointment(ctx, id)
+	err = h.Service.Appointment().UpdateAppointment(ctx, id, parsedDate)
 	if err != nil {
-		c.JSON(http.StatusNotFound, entity.Error{Message: "Appointment not found"})
+		c.JSON(statusFromError(err), entity.Error{Message: "Failed to update appointment: " + err.Error()})
 		h.Logger.Error(err.Error())
 		return
 	}
+	h.offerFreedSlots(c, appointment.DoctorID)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Appointment deleted successfully"})
+	c.JSON(http.StatusOK, entity.UserCreateResponse{ID: strconv.Itoa(id)})
+}
+
+// @Security  		BearerAuth
+// @Summary   		Cancel Appointment
+// @Description 	Api for cancelling an appointment. The appointment is kept with status cancelled, or no_show for a late cancellation if the policy says so
+// @Tags 			Appointment
+// @Accept 			json
+// @Produce 		json
+// @Param id path int true "Appointment ID"
+// @Param override query bool false "Admin override of the policy, if the policy allows it"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.Appointment
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Failure 422 {object} entity.Error
+// @Router /appointment/{id} [delete]
+func (h *HandlerV1) DeleteAppointment(c *gin.Context) {
+	h.transitionAppointment(c, "cancel")
 }
 
 // @Security  		BearerAuth
@@ -199,14 +460,22 @@ func (h *HandlerV1) DeleteAppointment(c *gin.Context) {
 // @Produce 		json
 // @Param  page query string true "Page"
 // @Param  limit query string true "Limit"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
 // @Success 200 {array} entity.ListAvailabilities
 // @Failure 400 {object} entity.Error
 // @Router /availabilities [get]
 func (h *HandlerV1) GetDoctorAvailabilities(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
 	page := c.Query("page")
 	limit := c.Query("limit")
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	pageInt, err := strconv.Atoi(page)
@@ -233,6 +502,9 @@ func (h *HandlerV1) GetDoctorAvailabilities(c *gin.Context) {
 		h.Logger.Error(err.Error())
 		return
 	}
+	for _, availability := range ListAvailabilities {
+		localizeAvailability(availability, loc)
+	}
 	c.JSON(http.StatusAccepted, entity.ListAvailabilities{
 		Availabilities: ListAvailabilities,
 		TotalCount:   int64(totalCount),
@@ -246,12 +518,20 @@ func (h *HandlerV1) GetDoctorAvailabilities(c *gin.Context) {
 // @Accept 			json
 // @Produce 		json
 // @Param id path int true "doctor_availability ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
 // @Success 200 {object} entity.Availability
 // @Failure 404 {object} map[string]string
 // @Router /availability/{id} [get]
 func (h *HandlerV1) GetAvailabilityByID(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	id := c.Param("id")
@@ -271,5 +551,5 @@ func (h *HandlerV1) GetAvailabilityByID(c *gin.Context) {
 		})
 		return
 	}
-	c.JSON(http.StatusOK, availabilitie)
+	c.JSON(http.StatusOK, localizeAvailability(availabilitie, loc))
 }
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// @Summary Confirm an appointment
+// @Description scheduled -> confirmed. Allowed for the patient and receptionists.
+// @Tags AppointmentStatus
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param body body entity.AppointmentTransitionRequest false "Optional reason"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.Appointment
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /appointment/{id}/confirm [post]
+func (h *HandlerV1) ConfirmAppointment(c *gin.Context) {
+	h.transitionAppointment(c, "confirm")
+}
+
+// @Security BearerAuth
+// @Summary Check in an appointment
+// @Description scheduled|confirmed -> checked_in. Allowed for receptionists.
+// @Tags AppointmentStatus
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param body body entity.AppointmentTransitionRequest false "Optional reason"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.Appointment
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /appointment/{id}/check-in [post]
+func (h *HandlerV1) CheckInAppointment(c *gin.Context) {
+	h.transitionAppointment(c, "check-in")
+}
+
+// @Security BearerAuth
+// @Summary Start an appointment
+// @Description checked_in -> in_progress. Allowed for the appointment's doctor.
+// @Tags AppointmentStatus
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param body body entity.AppointmentTransitionRequest false "Optional reason"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.Appointment
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /appointment/{id}/start [post]
+func (h *HandlerV1) StartAppointment(c *gin.Context) {
+	h.transitionAppointment(c, "start")
+}
+
+// @Security BearerAuth
+// @Summary Complete an appointment
+// @Description in_progress -> completed. Allowed for the appointment's doctor.
+// @Tags AppointmentStatus
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param body body entity.AppointmentTransitionRequest false "Optional reason"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.Appointment
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /appointment/{id}/complete [post]
+func (h *HandlerV1) CompleteAppointment(c *gin.Context) {
+	h.transitionAppointment(c, "complete")
+}
+
+// @Security BearerAuth
+// @Summary Cancel an appointment
+// @Description scheduled|confirmed -> cancelled and frees the slot. Allowed for the patient, the appointment's doctor and receptionists.
+// @Tags AppointmentStatus
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param body body entity.AppointmentTransitionRequest false "Optional reason"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.Appointment
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /appointment/{id}/cancel [post]
+func (h *HandlerV1) CancelAppointment(c *gin.Context) {
+	h.transitionAppointment(c, "cancel")
+}
+
+// @Security BearerAuth
+// @Summary Mark an appointment as no-show
+// @Description scheduled|confirmed -> no_show. Allowed for the appointment's doctor and receptionists.
+// @Tags AppointmentStatus
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param body body entity.AppointmentTransitionRequest false "Optional reason"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.Appointment
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /appointment/{id}/no-show [post]
+func (h *HandlerV1) NoShowAppointment(c *gin.Context) {
+	h.transitionAppointment(c, "no-show")
+}
+
+// @Security BearerAuth
+// @Summary Appointment status history
+// @Description Returns every status change of the appointment with who made it and when
+// @Tags AppointmentStatus
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListAppointmentTransitions
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment/{id}/history [get]
+func (h *HandlerV1) GetAppointmentHistory(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Appointment not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	allowed := false
+	for _, role := range []string{entity.RoleUser, entity.RoleDoctor, entity.RoleReceptionist} {
+		if h.canAccessAppointment(ctx, c, appointment, role) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	transitions, err := h.Service.Appointment().ListAppointmentTransitions(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, transition := range transitions {
+		transition.ChangedAt = transition.ChangedAt.In(loc)
+	}
+
+	c.JSON(http.StatusOK, entity.ListAppointmentTransitions{
+		Transitions: transitions,
+		TotalCount:  int64(len(transitions)),
+	})
+}
+
+// transitionAppointment performs a lifecycle action after checking that
+// one of the roles allowed for it applies to the caller.
+func (h *HandlerV1) transitionAppointment(c *gin.Context, action string) {
+	rule := entity.AppointmentTransitions[action]
+
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	var body entity.AppointmentTransitionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+			h.Logger.Error(err.Error())
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Appointment not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	// the rule table decides which roles may act, canAccessAppointment
+	// whether the caller holds one of them for this appointment
+	allowed := false
+	for _, role := range []string{entity.RoleAdmin, entity.RoleReceptionist, entity.RoleDoctor, entity.RoleUser} {
+		if rule.AllowsRole(role) && h.canAccessAppointment(ctx, c, appointment, role) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	userID, role, _ := h.caller(c)
+
+	if action == "cancel" {
+		policy, err := h.Service.CancellationPolicy().Get(ctx, appointment.AppointmentTypeID)
+		if err != nil {
+			c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+			h.Logger.Error(err.Error())
+			return
+		}
+
+		status, err := policy.CancelStatus(appointment, time.Now(), h.policyOverride(c, role))
+		if err != nil {
+			c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+			return
+		}
+		// a late cancellation may count as a no-show under the policy
+		rule.To = status
+	}
+
+	updated, err := h.Service.Appointment().TransitionAppointment(ctx, id, rule, userID, body.Reason)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if updated.Status == entity.AppointmentStatusCancelled || updated.Status == entity.AppointmentStatusNoShow {
+		h.offerFreedSlots(c, updated.DoctorID)
+	}
+
+	c.JSON(http.StatusOK, localizeAppointment(updated, loc))
+}
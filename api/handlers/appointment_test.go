@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/config"
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/logger"
+	tokens "github.com/Abdulazizxoshimov/Hospital/pkg/token"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+const testSignInKey = "test"
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...zapcore.Field)        {}
+func (nopLogger) Info(string, ...zapcore.Field)         {}
+func (nopLogger) Warn(string, ...zapcore.Field)         {}
+func (nopLogger) Error(string, ...zapcore.Field)        {}
+func (nopLogger) Fatal(string, ...zapcore.Field)        {}
+func (l nopLogger) With(...zapcore.Field) logger.Logger { return l }
+func (nopLogger) Sync() error                           { return nil }
+
+// testStorage serves the appointment repo only; any other repo panics.
+type testStorage struct {
+	repo.StorageI
+	appointments interfaces.Appointment
+}
+
+func (s *testStorage) Appointment() interfaces.Appointment { return s.appointments }
+
+// heldSlotRepo stands in for a slot held for holder: like coveringSlots it
+// lets only bookings in the holder's name through.
+type heldSlotRepo struct {
+	interfaces.Appointment
+	holder string
+	booked []string
+}
+
+func (r *heldSlotRepo) CreateAppointment(ctx context.Context, appointment *entity.Appointment) (*entity.Appointment, error) {
+	if appointment.UserID != r.holder {
+		return nil, entity.ErrorSlotHeld
+	}
+	r.booked = append(r.booked, appointment.UserID)
+	appointment.ID = int64(len(r.booked))
+	return appointment, nil
+}
+
+func testHandler(storage repo.StorageI) *HandlerV1 {
+	var cfg config.Config
+	cfg.Token.SignInKey = testSignInKey
+	cfg.Context.Timeout = time.Second
+
+	return New(&HandlerV1Config{
+		Config:  cfg,
+		Logger:  nopLogger{},
+		Service: storage,
+	})
+}
+
+func testToken(t *testing.T, userID, role string) string {
+	t.Helper()
+
+	access, _, err := (&tokens.JWTHandler{
+		Sub:        userID,
+		Role:       role,
+		SigningKey: testSignInKey,
+		Log:        nopLogger{},
+	}).GenerateAuthJWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return access
+}
+
+func TestCreateAppointmentHeldByAnotherPatient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	slots := &heldSlotRepo{holder: "patient-a"}
+	h := testHandler(&testStorage{appointments: slots})
+	router := gin.New()
+	router.POST("/appointment", h.CreateAppointment)
+
+	book := func(callerID, role string) int {
+		body := `{"DoctorID":"doctor","UserID":"patient-a","Appointment_time":{"start_time":"2030-01-01T09:00:00Z"}}`
+		req := httptest.NewRequest(http.MethodPost, "/appointment", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+testToken(t, callerID, role))
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := book("patient-b", entity.RoleUser); code != http.StatusConflict {
+		t.Fatalf("patient booking in another patient's name got %d, want %d", code, http.StatusConflict)
+	}
+	if code := book("doctor-c", entity.RoleDoctor); code != http.StatusConflict {
+		t.Fatalf("doctor booking in a patient's name got %d, want %d", code, http.StatusConflict)
+	}
+	if code := book("patient-a", entity.RoleUser); code != http.StatusCreated {
+		t.Fatalf("holder booking their own slot got %d, want %d", code, http.StatusCreated)
+	}
+	if code := book("desk", entity.RoleReceptionist); code != http.StatusCreated {
+		t.Fatalf("receptionist booking for the holder got %d, want %d", code, http.StatusCreated)
+	}
+	if len(slots.booked) != 2 {
+		t.Fatalf("got %d bookings, want 2", len(slots.booked))
+	}
+}
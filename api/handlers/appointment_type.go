@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// @Summary Create an appointment type
+// @Description Adds a kind of visit (consultation, follow-up, procedure...) with its default duration and buffer. RequiredResources lists the resource categories (e.g. exam-room, ultrasound) a booking of the type reserves, one resource per entry.
+// @Tags AppointmentType
+// @Accept json
+// @Produce json
+// @Param appointment_type body entity.AppointmentType true "Appointment type"
+// @Success 201 {object} entity.AppointmentType
+// @Failure 400 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /appointment-type [post]
+func (h *HandlerV1) CreateAppointmentType(c *gin.Context) {
+	var body entity.AppointmentType
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if body.Name == "" || body.DurationMinutes <= 0 || body.BufferMinutes < 0 {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Name and a positive duration are required"})
+		return
+	}
+	if !validResourceCategories(body.RequiredResources) {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "RequiredResources must not contain empty categories"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointmentType, err := h.Service.AppointmentType().Create(ctx, &body)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointmentType)
+}
+
+// @Security BearerAuth
+// @Summary List appointment types
+// @Description Returns every appointment type that can be selected when booking
+// @Tags AppointmentType
+// @Accept json
+// @Produce json
+// @Success 200 {object} entity.ListAppointmentTypes
+// @Failure 500 {object} entity.Error
+// @Router /appointment-types [get]
+func (h *HandlerV1) ListAppointmentTypes(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointmentTypes, err := h.Service.AppointmentType().List(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.ListAppointmentTypes{
+		AppointmentTypes: appointmentTypes,
+		TotalCount:       int64(len(appointmentTypes)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Get an appointment type
+// @Description Returns an appointment type. With doctor_id the doctor's duration override is applied.
+// @Tags AppointmentType
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment type ID"
+// @Param doctor_id query string false "Doctor ID"
+// @Success 200 {object} entity.AppointmentType
+// @Failure 404 {object} entity.Error
+// @Router /appointment-type/{id} [get]
+func (h *HandlerV1) GetAppointmentType(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	var appointmentType *entity.AppointmentType
+	if doctorID := c.Query("doctor_id"); doctorID != "" {
+		appointmentType, err = h.Service.AppointmentType().Resolve(ctx, doctorID, id)
+	} else {
+		appointmentType, err = h.Service.AppointmentType().Get(ctx, id)
+	}
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.ObjectNotFount})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, appointmentType)
+}
+
+// @Security BearerAuth
+// @Summary Update an appointment type
+// @Description Changes the defaults of an appointment type. Existing appointments keep their booked length and resources.
+// @Tags AppointmentType
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment type ID"
+// @Param appointment_type body entity.AppointmentType true "Appointment type"
+// @Success 200 {object} entity.AppointmentType
+// @Failure 400 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment-type/{id} [put]
+func (h *HandlerV1) UpdateAppointmentType(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	var body entity.AppointmentType
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if body.Name == "" || body.DurationMinutes <= 0 || body.BufferMinutes < 0 {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Name and a positive duration are required"})
+		return
+	}
+	if !validResourceCategories(body.RequiredResources) {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "RequiredResources must not contain empty categories"})
+		return
+	}
+	body.ID = id
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointmentType, err := h.Service.AppointmentType().Update(ctx, &body)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, appointmentType)
+}
+
+// @Security BearerAuth
+// @Summary Delete an appointment type
+// @Description Removes an appointment type
+// @Tags AppointmentType
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment type ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} entity.Error
+// @Router /appointment-type/{id} [delete]
+func (h *HandlerV1) DeleteAppointmentType(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	if err = h.Service.AppointmentType().Delete(ctx, id); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.ObjectNotFount})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Appointment type deleted successfully"})
+}
+
+// @Security BearerAuth
+// @Summary Override appointment type duration for a doctor
+// @Description Sets the doctor's own duration and/or buffer for an appointment type. Omitted fields fall back to the type defaults. Doctors may only override their own durations.
+// @Tags AppointmentType
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment type ID"
+// @Param override body entity.DoctorAppointmentType true "Doctor override"
+// @Success 200 {object} entity.AppointmentType
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment-type/{id}/override [put]
+func (h *HandlerV1) SetAppointmentTypeOverride(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	var body entity.DoctorAppointmentType
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if (body.DurationMinutes != nil && *body.DurationMinutes <= 0) || (body.BufferMinutes != nil && *body.BufferMinutes < 0) {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Duration must be positive and buffer non-negative"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, body.DoctorID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+	body.DoctorID = doctorID
+	body.AppointmentTypeID = id
+
+	if err = h.Service.AppointmentType().SetDoctorOverride(ctx, &body); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	appointmentType, err := h.Service.AppointmentType().Resolve(ctx, doctorID, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.ObjectNotFount})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, appointmentType)
+}
+
+// @Security BearerAuth
+// @Summary Remove a doctor's appointment type override
+// @Description Reverts the doctor to the appointment type defaults
+// @Tags AppointmentType
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment type ID"
+// @Param doctor_id query string false "Doctor ID, required for admins"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment-type/{id}/override [delete]
+func (h *HandlerV1) DeleteAppointmentTypeOverride(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, c.Query("doctor_id"))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err = h.Service.AppointmentType().DeleteDoctorOverride(ctx, doctorID, id); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.ObjectNotFount})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Override deleted successfully"})
+}
+
+func validResourceCategories(categories []string) bool {
+	for _, category := range categories {
+		if category == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,582 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/filestore"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/signature"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxAttachmentNameLength bounds the stored file name
+const maxAttachmentNameLength = 255
+
+// @Security BearerAuth
+// @Summary Upload a user attachment
+// @Description Uploads a file, such as an ID scan or an outside report, for the user. The content type is detected from the file and has to be one of the configured types. Allowed for the user and staff.
+// @Tags Attachment
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "User ID"
+// @Param file formData file true "File"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 201 {object} entity.Attachment
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 413 {object} entity.Error
+// @Failure 415 {object} entity.Error
+// @Router /user/{id}/attachment [post]
+func (h *HandlerV1) UploadUserAttachment(c *gin.Context) {
+	h.uploadAttachment(c, func(ctx context.Context) (*entity.Attachment, bool) {
+		return h.userAttachmentOwner(ctx, c)
+	})
+}
+
+// @Security BearerAuth
+// @Summary User attachments
+// @Description Lists the files uploaded for the user, latest first. Allowed for the user and staff.
+// @Tags Attachment
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListAttachments
+// @Failure 403 {object} entity.Error
+// @Router /user/{id}/attachments [get]
+func (h *HandlerV1) ListUserAttachments(c *gin.Context) {
+	h.listAttachments(c, func(ctx context.Context) (*entity.AttachmentFilter, bool) {
+		owner, ok := h.userAttachmentOwner(ctx, c)
+		if !ok {
+			return nil, false
+		}
+		return &entity.AttachmentFilter{UserID: owner.UserID}, true
+	})
+}
+
+// @Security BearerAuth
+// @Summary Upload an appointment attachment
+// @Description Uploads a file for the appointment, such as a referral letter. The content type is detected from the file and has to be one of the configured types. Allowed for the patient, the appointment's doctor and receptionists.
+// @Tags Attachment
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param file formData file true "File"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 201 {object} entity.Attachment
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 413 {object} entity.Error
+// @Failure 415 {object} entity.Error
+// @Router /appointment/{id}/attachment [post]
+func (h *HandlerV1) UploadAppointmentAttachment(c *gin.Context) {
+	h.uploadAttachment(c, func(ctx context.Context) (*entity.Attachment, bool) {
+		return h.appointmentAttachmentOwner(ctx, c)
+	})
+}
+
+// @Security BearerAuth
+// @Summary Appointment attachments
+// @Description Lists the files uploaded for the appointment, latest first; those of its encounter are listed with the encounter. Allowed for the patient, the appointment's doctor and receptionists.
+// @Tags Attachment
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListAttachments
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment/{id}/attachments [get]
+func (h *HandlerV1) ListAppointmentAttachments(c *gin.Context) {
+	h.listAttachments(c, func(ctx context.Context) (*entity.AttachmentFilter, bool) {
+		owner, ok := h.appointmentAttachmentOwner(ctx, c)
+		if !ok {
+			return nil, false
+		}
+		return &entity.AttachmentFilter{AppointmentID: owner.AppointmentID}, true
+	})
+}
+
+// @Security BearerAuth
+// @Summary Upload an encounter attachment
+// @Description Uploads a file to the encounter of the appointment, such as a scan taken during the visit. Files can be added after signing too. The content type is detected from the file and has to be one of the configured types. Allowed for the appointment's doctor.
+// @Tags Attachment
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param file formData file true "File"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 201 {object} entity.Attachment
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 413 {object} entity.Error
+// @Failure 415 {object} entity.Error
+// @Router /appointment/{id}/encounter/attachment [post]
+func (h *HandlerV1) UploadEncounterAttachment(c *gin.Context) {
+	h.uploadAttachment(c, func(ctx context.Context) (*entity.Attachment, bool) {
+		return h.encounterAttachmentOwner(ctx, c, true)
+	})
+}
+
+// @Security BearerAuth
+// @Summary Encounter attachments
+// @Description Lists the files uploaded to the encounter of the appointment, latest first. Allowed for the appointment's doctor, and for the patient once the encounter is signed.
+// @Tags Attachment
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListAttachments
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment/{id}/encounter/attachments [get]
+func (h *HandlerV1) ListEncounterAttachments(c *gin.Context) {
+	h.listAttachments(c, func(ctx context.Context) (*entity.AttachmentFilter, bool) {
+		owner, ok := h.encounterAttachmentOwner(ctx, c, false)
+		if !ok {
+			return nil, false
+		}
+		return &entity.AttachmentFilter{EncounterID: owner.EncounterID}, true
+	})
+}
+
+// @Security BearerAuth
+// @Summary Attachment download link
+// @Description Returns a short-lived link that downloads the attachment without a token, e.g. from a browser or an image tag. Allowed for those who may list the attachment.
+// @Tags Attachment
+// @Accept json
+// @Produce json
+// @Param id path int true "Attachment ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.AttachmentURL
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /attachment/{id}/url [get]
+func (h *HandlerV1) GetAttachmentURL(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	attachment, ok := h.accessibleAttachment(ctx, c)
+	if !ok {
+		return
+	}
+
+	expires := time.Now().Add(h.Config.Attachment.URLTTL).Truncate(time.Second)
+	c.JSON(http.StatusOK, entity.AttachmentURL{
+		URL:       h.attachmentURL(c.GetInt64(tenant.Key), attachment.ID, expires),
+		ExpiresAt: expires.In(loc),
+	})
+}
+
+// @Summary Download an attachment
+// @Description Sends the attachment's file. The link comes from the attachment link endpoint and expires shortly after.
+// @Tags Attachment
+// @Produce octet-stream
+// @Param id path int true "Attachment ID"
+// @Param tenant query int true "Tenant ID"
+// @Param expires query int true "Expiry as a Unix time"
+// @Param sig query string true "Link signature"
+// @Success 200 {file} file "Attachment"
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /attachment/{id}/download [get]
+func (h *HandlerV1) DownloadAttachment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err == nil {
+		err = signature.Verify(h.Config.Token.LinkKey, attachmentLinkPayload(c.GetInt64(tenant.Key), id), time.Unix(expires, 0), c.Query("sig"))
+	}
+	if err != nil {
+		c.JSON(http.StatusForbidden, entity.Error{Message: "Link is not valid or has expired"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	attachment, err := h.Service.Attachment().Get(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Attachment not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	file, err := h.Files.Get(ctx, attachment.Key)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, filestore.ErrNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, entity.Error{Message: "Attachment not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, file, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// @Security BearerAuth
+// @Summary Delete an attachment
+// @Description Deletes the attachment and its file. Allowed for the uploader while they may still upload to the owner, and for admins.
+// @Tags Attachment
+// @Accept json
+// @Produce json
+// @Param id path int true "Attachment ID"
+// @Success 204
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /attachment/{id} [delete]
+func (h *HandlerV1) DeleteAttachment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	userID, role, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	attachment, err := h.Service.Attachment().Get(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Attachment not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if role != entity.RoleAdmin && (attachment.UploadedBy != userID || !h.canAccessAttachment(ctx, c, attachment, true)) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	if err = h.Service.Attachment().Delete(ctx, attachment.ID); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+	// the record is gone, so a file left behind is only logged
+	if err = h.Files.Delete(ctx, attachment.Key); err != nil {
+		h.Logger.Error(err.Error())
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// uploadAttachment stores the request's file for the owner returned by
+// owner, which writes the error response itself when the caller may not
+// upload.
+func (h *HandlerV1) uploadAttachment(c *gin.Context, owner func(context.Context) (*entity.Attachment, bool)) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	userID, _, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	attachment, ok := owner(ctx)
+	if !ok {
+		return
+	}
+
+	// leave room for the multipart envelope around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Config.Attachment.MaxSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, entity.Error{Message: "File is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "File is required"})
+		return
+	}
+	if header.Size > h.Config.Attachment.MaxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, entity.Error{Message: "File is too large"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "File is required"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	defer file.Close()
+
+	// the declared content type is not trusted, the file's own is used
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "File cannot be read"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	contentType := http.DetectContentType(sniff[:n])
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !slices.Contains(h.Config.Attachment.ContentTypes, mediaType) {
+		c.JSON(http.StatusUnsupportedMediaType, entity.Error{Message: fmt.Sprintf("Files of type %s are not accepted", contentType)})
+		return
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	attachment.Key = fmt.Sprintf("%d/%s", c.GetInt64(tenant.Key), uuid.NewString())
+	attachment.FileName = attachmentFileName(header.Filename)
+	attachment.ContentType = contentType
+	attachment.Size = header.Size
+	attachment.UploadedBy = userID
+
+	if err = h.Files.Put(ctx, attachment.Key, file, attachment.Size, contentType); err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	created, err := h.Service.Attachment().Create(ctx, attachment)
+	if err != nil {
+		if err := h.Files.Delete(ctx, attachment.Key); err != nil {
+			h.Logger.Error(err.Error())
+		}
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, localizeAttachment(created, loc))
+}
+
+// listAttachments lists the attachments selected by filter, which writes
+// the error response itself when the caller may not list them.
+func (h *HandlerV1) listAttachments(c *gin.Context, filter func(context.Context) (*entity.AttachmentFilter, bool)) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	f, ok := filter(ctx)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.Service.Attachment().List(ctx, f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, attachment := range attachments {
+		localizeAttachment(attachment, loc)
+	}
+
+	c.JSON(http.StatusOK, entity.ListAttachments{
+		Attachments: attachments,
+		TotalCount:  int64(len(attachments)),
+	})
+}
+
+// userAttachmentOwner returns the user of the request as an attachment
+// owner. It writes the error response itself.
+func (h *HandlerV1) userAttachmentOwner(ctx context.Context, c *gin.Context) (*entity.Attachment, bool) {
+	owner := &entity.Attachment{UserID: c.Param("id")}
+	if !h.canAccessAttachment(ctx, c, owner, true) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return nil, false
+	}
+
+	return owner, true
+}
+
+// appointmentAttachmentOwner returns the appointment of the request as an
+// attachment owner. It writes the error response itself.
+func (h *HandlerV1) appointmentAttachmentOwner(ctx context.Context, c *gin.Context) (*entity.Attachment, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return nil, false
+	}
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Appointment not found"})
+		h.Logger.Error(err.Error())
+		return nil, false
+	}
+
+	owner := &entity.Attachment{AppointmentID: appointment.ID}
+	if !h.canAccessAttachment(ctx, c, owner, true) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return nil, false
+	}
+
+	return owner, true
+}
+
+// encounterAttachmentOwner returns the encounter of the request's
+// appointment as an attachment owner, checking the caller may upload to
+// it or, unless write is set, list it. It writes the error response
+// itself.
+func (h *HandlerV1) encounterAttachmentOwner(ctx context.Context, c *gin.Context, write bool) (*entity.Attachment, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return nil, false
+	}
+
+	encounter, err := h.Service.Encounter().GetByAppointment(ctx, int64(id))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Encounter not found"})
+		h.Logger.Error(err.Error())
+		return nil, false
+	}
+
+	owner := &entity.Attachment{AppointmentID: encounter.AppointmentID, EncounterID: encounter.ID}
+	if !h.canAccessAttachment(ctx, c, owner, write) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return nil, false
+	}
+
+	return owner, true
+}
+
+// accessibleAttachment loads the attachment of the request and checks the
+// caller may read it. It writes the error response itself.
+func (h *HandlerV1) accessibleAttachment(ctx context.Context, c *gin.Context) (*entity.Attachment, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return nil, false
+	}
+
+	attachment, err := h.Service.Attachment().Get(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Attachment not found"})
+		h.Logger.Error(err.Error())
+		return nil, false
+	}
+	if !h.canAccessAttachment(ctx, c, attachment, false) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return nil, false
+	}
+
+	return attachment, true
+}
+
+// canAccessAttachment reports whether the caller may read, or with write
+// upload, the attachments of the owner of attachment:
+//   - a user's: the user and staff other than lab staff;
+//   - an appointment's: the patient, the appointment's doctor and
+//     receptionists;
+//   - an encounter's: the appointment's doctor, and the patient reading a
+//     signed encounter.
+func (h *HandlerV1) canAccessAttachment(ctx context.Context, c *gin.Context, attachment *entity.Attachment, write bool) bool {
+	userID, role, err := h.caller(c)
+	if err != nil {
+		return false
+	}
+
+	if attachment.AppointmentID == 0 {
+		return userID == attachment.UserID ||
+			role == entity.RoleReceptionist || role == entity.RoleDoctor || role == entity.RoleAdmin
+	}
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, int(attachment.AppointmentID))
+	if err != nil {
+		return false
+	}
+
+	if attachment.EncounterID == 0 {
+		return h.canAccessAppointment(ctx, c, appointment, entity.RoleUser) ||
+			h.canAccessAppointment(ctx, c, appointment, entity.RoleDoctor) ||
+			h.canAccessAppointment(ctx, c, appointment, entity.RoleReceptionist)
+	}
+
+	if h.canAccessAppointment(ctx, c, appointment, entity.RoleDoctor) {
+		return true
+	}
+	if write || !h.canAccessAppointment(ctx, c, appointment, entity.RoleUser) {
+		return false
+	}
+	encounter, err := h.Service.Encounter().GetByAppointment(ctx, attachment.AppointmentID)
+	return err == nil && encounter.Status == entity.EncounterStatusSigned
+}
+
+// attachmentURL links to the download of an attachment without a token
+// until expires.
+func (h *HandlerV1) attachmentURL(tenantID, attachmentID int64, expires time.Time) string {
+	return fmt.Sprintf("%s/attachment/%d/download?%s=%d&expires=%d&sig=%s",
+		h.Config.Server.PublicURL, attachmentID, tenant.Query, tenantID, expires.Unix(),
+		signature.Sign(h.Config.Token.LinkKey, attachmentLinkPayload(tenantID, attachmentID), expires))
+}
+
+func attachmentLinkPayload(tenantID, attachmentID int64) string {
+	return "attachment:" + strconv.FormatInt(tenantID, 10) + ":" + strconv.FormatInt(attachmentID, 10)
+}
+
+// attachmentFileName keeps the base name of an uploaded file, as clients
+// may send full paths.
+func attachmentFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" {
+		name = "file"
+	}
+	if runes := []rune(name); len(runes) > maxAttachmentNameLength {
+		name = string(runes[:maxAttachmentNameLength])
+	}
+	return name
+}
+
+func localizeAttachment(attachment *entity.Attachment, loc *time.Location) *entity.Attachment {
+	attachment.CreatedAt = attachment.CreatedAt.In(loc)
+	return attachment
+}
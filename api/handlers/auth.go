@@ -9,6 +9,7 @@ import (
 
 	"github.com/Abdulazizxoshimov/Hospital/entity"
 	"github.com/Abdulazizxoshimov/Hospital/pkg/gmail"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/tenant"
 	"github.com/Abdulazizxoshimov/Hospital/pkg/token"
 	"github.com/Abdulazizxoshimov/Hospital/pkg/validation"
 	govalidator "github.com/asaskevich/govalidator"
@@ -30,7 +31,7 @@ import (
 // @Failure 		500 {object} entity.Error
 // @Router 			/register [POST]
 func (h *HandlerV1) Register(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(7))
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), time.Second*time.Duration(7))
 	defer cancel()
 
 	var (
@@ -134,7 +135,7 @@ func (h *HandlerV1) Register(c *gin.Context) {
 // @Failure            500 {object} entity.Error
 // @Router             /users/verify [post]
 func (h *HandlerV1) Verify(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(7))
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), time.Second*time.Duration(7))
 	defer cancel()
 
 	code := c.Query("code")
@@ -171,6 +172,7 @@ func (h *HandlerV1) Verify(c *gin.Context) {
 	h.RefreshToken = token.JWTHandler{
 		Sub:        id,
 		Role:       "user",
+		TenantID:   c.GetInt64(tenant.Key),
 		SigningKey: h.Config.Token.SignInKey,
 		Log:        h.Logger,
 		Email:      user.Email,
@@ -243,7 +245,7 @@ func (h *HandlerV1) Verify(c *gin.Context) {
 // @Failure 		500 {object} entity.Error
 // @Router 			/login [POST]
 func (h *HandlerV1) Login(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	var body entity.Login
@@ -286,6 +288,7 @@ func (h *HandlerV1) Login(c *gin.Context) {
 	h.RefreshToken = token.JWTHandler{
 		Sub:        response.ID,
 		Role:       response.Role,
+		TenantID:   c.GetInt64(tenant.Key),
 		SigningKey: h.Config.Token.SignInKey,
 		Log:        h.Logger,
 		Email:      response.Email,
@@ -337,7 +340,7 @@ func (h *HandlerV1) Login(c *gin.Context) {
 // @Failure 		500 {object} entity.Error
 // @Router 			/forgot/{email} [POST]
 func (h *HandlerV1) Forgot(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	email := c.Param("email")
@@ -405,7 +408,7 @@ func (h *HandlerV1) Forgot(c *gin.Context) {
 // @Failure 		500 {object} entity.Error
 // @Router 			/verify [POST]
 func (h *HandlerV1) VerifyOTP(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(7))
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), time.Second*time.Duration(7))
 	defer cancel()
 
 	otp := c.Query("otp")
@@ -455,7 +458,7 @@ func (h *HandlerV1) ResetPassword(c *gin.Context) {
 	var (
 		body entity.ResetPassword
 	)
-	ctx, cancel := context.WithTimeout(context.TODO(), time.Second*time.Duration(7))
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), time.Second*time.Duration(7))
 	defer cancel()
 
 	err := c.ShouldBindJSON(&body)
@@ -532,30 +535,32 @@ func (h *HandlerV1) ResetPassword(c *gin.Context) {
 // @Failure 		500 {object} entity.Error
 // @Router 			/token/{refresh} [GET]
 func (h *HandlerV1) Token(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(7))
-	defer cancel()
-
 	RToken := c.Param("refresh")
 
-	user, err := h.Service.User().Get(ctx, map[string]string{
-			"refresh_token": RToken,
-		},
-	)
-
+	resclaim, err := token.ExtractClaim(RToken, []byte(h.Config.Token.SignInKey))
 	if err != nil {
 		c.JSON(500, entity.Error{
 			Message: err.Error(),
 		})
-		log.Println(err)
+		log.Println(err.Error())
 		return
 	}
 
-	resclaim, err := token.ExtractClaim(RToken, []byte(h.Config.Token.SignInKey))
+	// the refresh token only works at the hospital it was issued by
+	tenantID := cast.ToInt64(resclaim["tenant_id"])
+	ctx, cancel := context.WithTimeout(tenant.NewContext(context.Background(), tenantID), time.Second*time.Duration(7))
+	defer cancel()
+
+	user, err := h.Service.User().Get(ctx, map[string]string{
+			"refresh_token": RToken,
+		},
+	)
+
 	if err != nil {
 		c.JSON(500, entity.Error{
 			Message: err.Error(),
 		})
-		log.Println(err.Error())
+		log.Println(err)
 		return
 	}
 	Now_time := time.Now().Unix()
@@ -564,6 +569,7 @@ func (h *HandlerV1) Token(c *gin.Context) {
 		h.RefreshToken = token.JWTHandler{
 			Sub:        user.ID,
 			Role:       user.Role,
+			TenantID:   tenantID,
 			SigningKey: h.Config.Token.SignInKey,
 			Log:        h.Logger,
 			Email:      user.Email,
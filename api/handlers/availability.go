@@ -0,0 +1,433 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	timepkg "github.com/Abdulazizxoshimov/Hospital/pkg/time"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// @Summary Create an availability slot
+// @Description Publishes a single free slot. Doctors may only publish their own slots, admins any doctor's. With BranchID the slot is held at that branch: the doctor must be assigned to it and the slot must fall within its opening hours.
+// @Tags Availability
+// @Accept json
+// @Produce json
+// @Param availability body entity.Availability true "Slot details"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 201 {object} entity.Availability
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /availability [post]
+func (h *HandlerV1) CreateAvailability(c *gin.Context) {
+	var body entity.Availability
+
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, body.DoctorID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if !body.EndTime.After(body.StartTime) {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "End time must be after start time"})
+		return
+	}
+
+	availability, err := h.Service.Appointment().CreateAvailability(ctx, &entity.Availability{
+		DoctorID:  doctorID,
+		StartTime: body.StartTime,
+		EndTime:   body.EndTime,
+		BranchID:  body.BranchID,
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, localizeAvailability(availability, loc))
+}
+
+// @Security BearerAuth
+// @Summary Bulk create availability slots
+// @Description Publishes slots of SlotMinutes between StartTime and EndTime (HH:MM) on every matching day from FromDate to ToDate (YYYY-MM-DD), read as wall clock times in the tz zone, at the branch BranchID when set. The batch is rejected as a whole if any slot overlaps or falls outside the branch's opening hours.
+// @Tags Availability
+// @Accept json
+// @Produce json
+// @Param availability body entity.AvailabilityBulk true "Date range and daily window"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 201 {object} entity.ListAvailabilities
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /availabilities/bulk [post]
+func (h *HandlerV1) CreateAvailabilitiesBulk(c *gin.Context) {
+	var body entity.AvailabilityBulk
+
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, body.DoctorID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	slots, err := bulkSlots(doctorID, &body, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if len(slots) == 0 {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "No slots fit into the given range"})
+		return
+	}
+
+	availabilities, err := h.Service.Appointment().CreateAvailabilities(ctx, slots)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	for _, availability := range availabilities {
+		localizeAvailability(availability, loc)
+	}
+	c.JSON(http.StatusCreated, entity.ListAvailabilities{
+		Availabilities: availabilities,
+		TotalCount:     int64(len(availabilities)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Update an availability slot
+// @Description Moves a free slot, and to another branch when BranchID is set. Booked slots cannot be changed.
+// @Tags Availability
+// @Accept json
+// @Produce json
+// @Param id path int true "doctor_availability ID"
+// @Param availability body entity.Availability true "New slot times"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.Availability
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /availability/{id} [put]
+func (h *HandlerV1) UpdateAvailability(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	var body entity.Availability
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	current, err := h.Service.Appointment().GetAvailability(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.ObjectNotFount})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	doctorID, err := h.doctorScope(ctx, c, current.DoctorID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if !body.EndTime.After(body.StartTime) {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "End time must be after start time"})
+		return
+	}
+
+	availability, err := h.Service.Appointment().UpdateAvailability(ctx, &entity.Availability{
+		ID:        int64(id),
+		DoctorID:  doctorID,
+		StartTime: body.StartTime,
+		EndTime:   body.EndTime,
+		BranchID:  body.BranchID,
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, localizeAvailability(availability, loc))
+}
+
+// @Security BearerAuth
+// @Summary Delete an availability slot
+// @Description Removes a free slot. Booked slots cannot be removed.
+// @Tags Availability
+// @Accept json
+// @Produce json
+// @Param id path int true "doctor_availability ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /availability/{id} [delete]
+func (h *HandlerV1) DeleteAvailability(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	current, err := h.Service.Appointment().GetAvailability(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.ObjectNotFount})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if _, err = h.doctorScope(ctx, c, current.DoctorID); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err = h.Service.Appointment().DeleteAvailability(ctx, id); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Availability deleted successfully"})
+}
+
+// bulkSlots expands a bulk request into individual slots. Dates and clock
+// times are wall clock values in loc.
+func bulkSlots(doctorID string, req *entity.AvailabilityBulk, loc *time.Location) ([]*entity.Availability, error) {
+	from, err := time.Parse("2006-01-02", req.FromDate)
+	if err != nil {
+		return nil, err
+	}
+	to, err := time.Parse("2006-01-02", req.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("ToDate must not be before FromDate")
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		return nil, fmt.Errorf("date range must not exceed one year")
+	}
+	if req.SlotMinutes <= 0 {
+		return nil, fmt.Errorf("SlotMinutes must be positive")
+	}
+
+	dayStart, err := timepkg.ParseClock(req.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	dayEnd, err := timepkg.ParseClock(req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	weekdays := make(map[time.Weekday]bool, len(req.Weekdays))
+	for _, day := range req.Weekdays {
+		weekdays[time.Weekday(day)] = true
+	}
+
+	var slots []*entity.Availability
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if len(weekdays) != 0 && !weekdays[day.Weekday()] {
+			continue
+		}
+
+		start := timepkg.OnDate(day, dayStart, loc)
+		end := timepkg.OnDate(day, dayEnd, loc)
+		for _, interval := range timepkg.Split(start, end, time.Duration(req.SlotMinutes)*time.Minute) {
+			slots = append(slots, &entity.Availability{
+				DoctorID:  doctorID,
+				StartTime: interval.Start,
+				EndTime:   interval.End,
+				BranchID:  req.BranchID,
+			})
+		}
+	}
+
+	return slots, nil
+}
+
+// @Security BearerAuth
+// @Summary Search free slots across doctors
+// @Description Returns the earliest free slots of every doctor matching the filters, sorted by start time, e.g. the first available cardiologist. Dates and times of day are read in the tz zone.
+// @Tags Availability
+// @Accept json
+// @Produce json
+// @Param specialization query string false "Doctor specialization, e.g. cardiologist"
+// @Param language query string false "Language the doctor speaks"
+// @Param branch_id query int false "Only slots at this branch"
+// @Param from query string false "First date, YYYY-MM-DD, defaults to today"
+// @Param to query string false "Last date, YYYY-MM-DD, defaults to 30 days after from"
+// @Param time_from query string false "Earliest start time of day, HH:MM"
+// @Param time_to query string false "Latest end time of day, HH:MM"
+// @Param page query int false "Page, defaults to 1"
+// @Param limit query int false "Limit, defaults to 10, at most 100"
+// @Param tz query string false "IANA time zone for the filters and returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListAvailableSlots
+// @Failure 400 {object} entity.Error
+// @Router /availabilities/search [get]
+func (h *HandlerV1) SearchAvailabilities(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	search, err := slotSearch(c, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	slots, total, err := h.Service.Appointment().SearchAvailabilities(ctx, search)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, slot := range slots {
+		slot.StartTime = slot.StartTime.In(loc)
+		slot.EndTime = slot.EndTime.In(loc)
+	}
+
+	c.JSON(http.StatusOK, entity.ListAvailableSlots{
+		Slots:      slots,
+		TotalCount: int64(total),
+	})
+}
+
+// slotSearch reads the search filters from the query string. Dates are
+// whole days in loc; the range never starts in the past.
+func slotSearch(c *gin.Context, loc *time.Location) (*entity.AvailabilitySearch, error) {
+	search := &entity.AvailabilitySearch{
+		Specialization: c.Query("specialization"),
+		Language:       c.Query("language"),
+		TimeFrom:       c.Query("time_from"),
+		TimeTo:         c.Query("time_to"),
+		Location:       loc,
+	}
+
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if raw := c.Query("from"); raw != "" {
+		day, err := time.ParseInLocation("2006-01-02", raw, loc)
+		if err != nil {
+			return nil, fmt.Errorf("from must be a date, YYYY-MM-DD")
+		}
+		from = day
+	}
+	to := from.AddDate(0, 0, 30)
+	if raw := c.Query("to"); raw != "" {
+		day, err := time.ParseInLocation("2006-01-02", raw, loc)
+		if err != nil {
+			return nil, fmt.Errorf("to must be a date, YYYY-MM-DD")
+		}
+		to = day.AddDate(0, 0, 1)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+	if from.Before(now) {
+		from = now
+	}
+	search.From, search.To = from, to
+
+	for _, clock := range []string{search.TimeFrom, search.TimeTo} {
+		if clock == "" {
+			continue
+		}
+		if _, err := timepkg.ParseClock(clock); err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		return nil, fmt.Errorf("invalid page number")
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 100 {
+		return nil, fmt.Errorf("limit must be between 1 and 100")
+	}
+	search.Page, search.Limit = page, limit
+
+	if raw := c.Query("branch_id"); raw != "" {
+		if search.BranchID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid branch_id")
+		}
+	}
+
+	return search, nil
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// @Summary Create a branch
+// @Description Adds a site of the hospital. Timezone is an IANA zone and defaults to the hospital zone; Hours are the weekly opening hours on the branch's wall clock (Weekday 0 = Sunday, HH:MM), without any the branch is always open. Doctors are assigned with PUT /doctor/{id}/branches.
+// @Tags Branch
+// @Accept json
+// @Produce json
+// @Param branch body entity.BranchRequest true "Branch"
+// @Success 201 {object} entity.Branch
+// @Failure 400 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /branch [post]
+func (h *HandlerV1) CreateBranch(c *gin.Context) {
+	var body entity.BranchRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	branch, err := h.branchFromRequest(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	branch, err = h.Service.Branch().Create(ctx, branch)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, branch)
+}
+
+// @Summary List branches
+// @Description Lists the sites of the hospital with their contact details and opening hours
+// @Tags Branch
+// @Accept json
+// @Produce json
+// @Success 200 {object} entity.ListBranches
+// @Failure 500 {object} entity.Error
+// @Router /branches [get]
+func (h *HandlerV1) ListBranches(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	branches, err := h.Service.Branch().List(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.ListBranches{
+		Branches:   branches,
+		TotalCount: int64(len(branches)),
+	})
+}
+
+// @Summary Get a branch
+// @Description Returns a site of the hospital with its opening hours
+// @Tags Branch
+// @Accept json
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Success 200 {object} entity.Branch
+// @Failure 404 {object} entity.Error
+// @Router /branch/{id} [get]
+func (h *HandlerV1) GetBranch(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	branch, err := h.Service.Branch().Get(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Branch not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, branch)
+}
+
+// @Security BearerAuth
+// @Summary Update a branch
+// @Description Changes a branch and replaces its opening hours. Slots already published outside the new hours are kept.
+// @Tags Branch
+// @Accept json
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Param branch body entity.BranchRequest true "Branch"
+// @Success 200 {object} entity.Branch
+// @Failure 400 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /branch/{id} [put]
+func (h *HandlerV1) UpdateBranch(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	var body entity.BranchRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	branch, err := h.branchFromRequest(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		return
+	}
+	branch.ID = id
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	branch, err = h.Service.Branch().Update(ctx, branch)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, branch)
+}
+
+// @Security BearerAuth
+// @Summary Delete a branch
+// @Description Removes a branch with its closures and doctor assignments. Branches with upcoming appointments cannot be deleted.
+// @Tags Branch
+// @Accept json
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /branch/{id} [delete]
+func (h *HandlerV1) DeleteBranch(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	if err = h.Service.Branch().Delete(ctx, id); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Branch deleted"})
+}
+
+// @Security BearerAuth
+// @Summary Assign a doctor to branches
+// @Description Replaces the branches the doctor works at. Slots can only be published at these branches; slots already published elsewhere are kept.
+// @Tags Branch
+// @Accept json
+// @Produce json
+// @Param id path string true "Doctor ID"
+// @Param branches body entity.DoctorBranchesRequest true "Branch IDs"
+// @Success 200 {object} entity.Doctor
+// @Failure 400 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /doctor/{id}/branches [put]
+func (h *HandlerV1) SetDoctorBranches(c *gin.Context) {
+	var body entity.DoctorBranchesRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctor, err := h.Service.Doctor().Get(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, entity.Error{Message: "Doctor not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err = h.Service.Branch().SetDoctorBranches(ctx, doctor.ID, body.BranchIDs); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Branch not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if doctor, err = h.Service.Doctor().Get(ctx, doctor.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, doctor)
+}
+
+// branchFromRequest validates the request.
+func (h *HandlerV1) branchFromRequest(body *entity.BranchRequest) (*entity.Branch, error) {
+	if body.Timezone == "" {
+		body.Timezone = h.Config.Location.String()
+	}
+	if _, err := time.LoadLocation(body.Timezone); err != nil {
+		return nil, errors.New("Timezone must be an IANA time zone")
+	}
+	if err := validOpeningHours(body.Hours); err != nil {
+		return nil, err
+	}
+
+	branch := &entity.Branch{
+		Name:     body.Name,
+		Address:  body.Address,
+		Timezone: body.Timezone,
+		Phone:    body.Phone,
+		Email:    body.Email,
+		Hours:    body.Hours,
+	}
+	if branch.Hours == nil {
+		branch.Hours = []*entity.OpeningHours{}
+	}
+
+	return branch, nil
+}
+
+// validOpeningHours checks weekly opening hours of a branch or resource.
+func validOpeningHours(hours []*entity.OpeningHours) error {
+	for _, h := range hours {
+		from, fromErr := time.Parse("15:04", h.StartTime)
+		to, toErr := time.Parse("15:04", h.EndTime)
+		if h.Weekday < 0 || h.Weekday > 6 || fromErr != nil || toErr != nil || !to.After(from) {
+			return errors.New("Hours need a Weekday from 0 to 6 and HH:MM times with EndTime after StartTime")
+		}
+	}
+	return nil
+}
+
+// branchLocation returns the zone of the branch, or the hospital zone when
+// branchID is 0.
+func (h *HandlerV1) branchLocation(ctx context.Context, branchID int64) (*time.Location, error) {
+	if branchID == 0 {
+		return h.Config.Location, nil
+	}
+
+	branch, err := h.Service.Branch().Get(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	return time.LoadLocation(branch.Timezone)
+}
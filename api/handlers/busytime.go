@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/ical"
+	"github.com/gin-gonic/gin"
+)
+
+// maxCalendarImportSize caps uploaded and local calendar files
+const maxCalendarImportSize = 5 << 20
+
+var (
+	errCalendarMissing      = errors.New("upload a calendar file or give the path of one in the import directory")
+	errCalendarPathDisabled = errors.New("importing calendars by path is disabled")
+	errCalendarTooLarge     = errors.New("calendar file is too large")
+)
+
+// @Security BearerAuth
+// @Summary Import external busy times
+// @Description Imports a doctor's outside commitments from an iCalendar file, uploaded as file or read from path inside the configured import directory. Free slots overlapping a busy time can no longer be booked. Importing the same source again reconciles it: moved events are updated and removed ones unblock their slots. Appointments already booked into a busy time are returned as Conflicts and left for staff to resolve. Doctors import for themselves, admins for doctor_id.
+// @Tags Availability
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file false "iCalendar file"
+// @Param path formData string false "Calendar file path relative to the import directory"
+// @Param source formData string false "Name of the calendar, defaults to the file name"
+// @Param doctor_id formData string false "Doctor ID, required for admins"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.BusyTimeImport
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Router /doctor/busy-times/import [post]
+func (h *HandlerV1) ImportBusyTimes(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, c.PostForm("doctor_id"))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	data, name, err := h.calendarImportData(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	source := c.PostForm("source")
+	if source == "" {
+		source = name
+	}
+
+	busyTimes, err := h.parseBusyTimes(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	result, err := h.Service.BusyTime().Import(ctx, doctorID, source, busyTimes)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if result.Removed > 0 || result.Updated > 0 {
+		h.offerFreedSlots(c, doctorID)
+	}
+	for _, appointment := range result.Conflicts {
+		localizeAppointment(appointment, loc)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Security BearerAuth
+// @Summary List external busy times
+// @Description Lists the doctor's imported busy times from every source overlapping from..to (RFC 3339), by default the coming import horizon.
+// @Tags Availability
+// @Accept json
+// @Produce json
+// @Param doctor_id query string false "Doctor ID, required for admins"
+// @Param from query string false "Start of the range (RFC 3339)"
+// @Param to query string false "End of the range (RFC 3339)"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListBusyTimes
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Router /doctor/busy-times [get]
+func (h *HandlerV1) ListBusyTimes(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	from, to := time.Now(), time.Now().AddDate(0, 0, 7*h.Config.Calendar.ImportWeeks)
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid from, expected RFC 3339"})
+			return
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid to, expected RFC 3339"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, c.Query("doctor_id"))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	busyTimes, err := h.Service.BusyTime().List(ctx, doctorID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, busyTime := range busyTimes {
+		busyTime.StartTime = busyTime.StartTime.In(loc)
+		busyTime.EndTime = busyTime.EndTime.In(loc)
+		busyTime.UpdatedAt = busyTime.UpdatedAt.In(loc)
+	}
+
+	c.JSON(http.StatusOK, entity.ListBusyTimes{
+		BusyTimes:  busyTimes,
+		TotalCount: int64(len(busyTimes)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Remove an external calendar
+// @Description Drops every busy time imported from source, unblocking the slots they covered.
+// @Tags Availability
+// @Accept json
+// @Produce json
+// @Param source query string true "Calendar source name"
+// @Param doctor_id query string false "Doctor ID, required for admins"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /doctor/busy-times [delete]
+func (h *HandlerV1) DeleteBusyTimes(c *gin.Context) {
+	source := c.Query("source")
+	if source == "" {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "source is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, c.Query("doctor_id"))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err = h.Service.BusyTime().DeleteSource(ctx, doctorID, source); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Calendar source not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	h.offerFreedSlots(c, doctorID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar source removed"})
+}
+
+// calendarImportData reads the uploaded calendar file, or the file at the
+// path form value confined to the configured import directory, and returns
+// it with its base name.
+func (h *HandlerV1) calendarImportData(c *gin.Context) ([]byte, string, error) {
+	if header, err := c.FormFile("file"); err == nil {
+		if header.Size > maxCalendarImportSize {
+			return nil, "", errCalendarTooLarge
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(io.LimitReader(file, maxCalendarImportSize))
+		return data, filepath.Base(header.Filename), err
+	}
+
+	path := c.PostForm("path")
+	if path == "" {
+		return nil, "", errCalendarMissing
+	}
+	if h.Config.Calendar.ImportDir == "" {
+		return nil, "", errCalendarPathDisabled
+	}
+
+	// cleaning against the root keeps ".." from leaving the directory
+	file, err := os.Open(filepath.Join(h.Config.Calendar.ImportDir, filepath.Clean("/"+path)))
+	if err != nil {
+		return nil, "", errCalendarMissing
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxCalendarImportSize+1))
+	if len(data) > maxCalendarImportSize {
+		return nil, "", errCalendarTooLarge
+	}
+
+	return data, filepath.Base(path), err
+}
+
+// parseBusyTimes turns the calendar's opaque events within the import
+// horizon into busy times. Cancelled and free (TRANSP:TRANSPARENT) events
+// do not block anything.
+func (h *HandlerV1) parseBusyTimes(data []byte) ([]*entity.BusyTime, error) {
+	calendar, err := ical.Parse(bytes.NewReader(data), h.Config.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Now()
+	events, err := calendar.Expand(from, from.AddDate(0, 0, 7*h.Config.Calendar.ImportWeeks))
+	if err != nil {
+		return nil, err
+	}
+
+	busyTimes := []*entity.BusyTime{}
+	for _, event := range events {
+		if event.Status == ical.StatusCancelled || event.Transparent {
+			continue
+		}
+
+		uid := event.UID
+		if !event.RecurrenceID.IsZero() {
+			uid += "/" + event.RecurrenceID.UTC().Format(time.RFC3339)
+		}
+		busyTimes = append(busyTimes, &entity.BusyTime{
+			UID:       uid,
+			Summary:   event.Summary,
+			StartTime: event.Start,
+			EndTime:   event.End,
+		})
+	}
+
+	return busyTimes, nil
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/ical"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	calendarContentType = "text/calendar; charset=utf-8"
+
+	// feeds reach this far back so recent visits stay visible
+	calendarFeedHistory = 90 * 24 * time.Hour
+	calendarFeedLimit   = 1000
+)
+
+// @Security BearerAuth
+// @Summary Download an appointment as iCalendar
+// @Description Returns the appointment as an .ics file. Cancelled appointments carry STATUS:CANCELLED so importing the file again removes the event.
+// @Tags Calendar
+// @Produce text/calendar
+// @Param id path int true "Appointment ID"
+// @Success 200 {string} string "iCalendar document"
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment/{id}/ics [get]
+func (h *HandlerV1) DownloadAppointmentICS(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Appointment not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	allowed := false
+	for _, role := range []string{entity.RoleUser, entity.RoleDoctor, entity.RoleReceptionist} {
+		if h.canAccessAppointment(ctx, c, appointment, role) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="appointment-%d.ics"`, appointment.ID))
+	h.writeCalendar(c, &ical.Calendar{
+		Timezone: h.Config.Timezone,
+		Events:   []ical.Event{appointmentEvent(appointment, "Hospital appointment")},
+	})
+}
+
+// @Security BearerAuth
+// @Summary Create a calendar feed
+// @Description Issues a secret iCalendar feed URL for the caller that calendar apps can subscribe to: a doctor's agenda, or a patient's bookings. Calling it again rotates the URL and revokes the old one.
+// @Tags Calendar
+// @Accept json
+// @Produce json
+// @Success 201 {object} entity.CalendarFeed
+// @Failure 401 {object} entity.Error
+// @Router /me/calendar-feed [post]
+func (h *HandlerV1) CreateCalendarFeed(c *gin.Context) {
+	userID, _, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, entity.Error{Message: "Unauthorized"})
+		return
+	}
+
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	feed, err := h.Service.CalendarFeed().Save(ctx, &entity.CalendarFeed{
+		UserID: userID,
+		Token:  hex.EncodeToString(secret),
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	feed.URL = calendarFeedURL(c, feed.Token)
+
+	c.JSON(http.StatusCreated, feed)
+}
+
+// @Security BearerAuth
+// @Summary Revoke the calendar feed
+// @Description Disables the caller's calendar feed URL.
+// @Tags Calendar
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} entity.Error
+// @Router /me/calendar-feed [delete]
+func (h *HandlerV1) DeleteCalendarFeed(c *gin.Context) {
+	userID, _, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, entity.Error{Message: "Unauthorized"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	if err = h.Service.CalendarFeed().Delete(ctx, userID); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Calendar feed not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed revoked"})
+}
+
+// @Summary Calendar feed
+// @Description Public iCalendar feed addressed by its secret token. Doctors get their agenda with patient names, patients their own bookings. Cancelled appointments stay in the feed with STATUS:CANCELLED so subscribed calendars drop them.
+// @Tags Calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token, optionally followed by .ics"
+// @Success 200 {string} string "iCalendar document"
+// @Failure 404 {object} entity.Error
+// @Router /calendar/{token} [get]
+func (h *HandlerV1) GetCalendarFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	feed, err := h.Service.CalendarFeed().GetByToken(ctx, token)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Calendar feed not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	filter := &entity.AppointmentFilter{
+		StartFrom: time.Now().Add(-calendarFeedHistory),
+		Page:      1,
+		Limit:     calendarFeedLimit,
+	}
+	calendar := &ical.Calendar{Timezone: h.Config.Timezone}
+
+	if doctor, err := h.Service.Doctor().GetByUserID(ctx, feed.UserID); err == nil {
+		filter.DoctorID = doctor.ID
+		entries, err := h.Service.Appointment().DoctorAgenda(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+			h.Logger.Error(err.Error())
+			return
+		}
+
+		calendar.Name = "Hospital agenda"
+		for _, entry := range entries {
+			event := appointmentEvent(&entry.Appointment, "Appointment: "+entry.PatientName)
+			if entry.PatientPhone != "" {
+				event.Description = "Patient phone: " + entry.PatientPhone
+			}
+			calendar.Events = append(calendar.Events, event)
+		}
+	} else {
+		filter.PatientID = feed.UserID
+		appointments, _, err := h.Service.Appointment().ListAppointments(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+			h.Logger.Error(err.Error())
+			return
+		}
+
+		calendar.Name = "Hospital appointments"
+		for _, appointment := range appointments {
+			calendar.Events = append(calendar.Events, appointmentEvent(appointment, "Hospital appointment"))
+		}
+	}
+
+	h.writeCalendar(c, calendar)
+}
+
+func (h *HandlerV1) writeCalendar(c *gin.Context, calendar *ical.Calendar) {
+	var buf bytes.Buffer
+	if err := ical.Write(&buf, calendar); err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.Data(http.StatusOK, calendarContentType, buf.Bytes())
+}
+
+// appointmentEvent maps an appointment onto a calendar event. The sequence
+// grows with every reschedule and with cancellation so clients replace
+// the copy they already have.
+func appointmentEvent(appointment *entity.Appointment, summary string) ical.Event {
+	event := ical.Event{
+		UID:      fmt.Sprintf("appointment-%d@hospital", appointment.ID),
+		Summary:  summary,
+		Start:    appointment.StartTime,
+		End:      appointment.EndTime,
+		Status:   ical.StatusConfirmed,
+		Sequence: appointment.RescheduleCount,
+	}
+
+	switch appointment.Status {
+	case entity.AppointmentStatusScheduled:
+		event.Status = ical.StatusTentative
+	case entity.AppointmentStatusCancelled, entity.AppointmentStatusNoShow:
+		event.Status = ical.StatusCancelled
+		event.Sequence++
+	}
+
+	return event
+}
+
+func calendarFeedURL(c *gin.Context, token string) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	// calendar apps fetch the feed without a token, so it names its tenant
+	return scheme + "://" + c.Request.Host + "/calendar/" + token + ".ics?" +
+		tenant.Query + "=" + strconv.FormatInt(c.GetInt64(tenant.Key), 10)
+}
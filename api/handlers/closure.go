@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// @Summary Create a closure
+// @Description Closes the hospital, or only the branch BranchID, from Date to EndDate (YYYY-MM-DD, inclusive, in the zone of the branch or hospital), or, with FromTime and ToTime (HH:MM), for part of the single day Date. No slots can be generated, created or booked during a closure. Appointments already booked are left for staff to handle.
+// @Tags Closure
+// @Accept json
+// @Produce json
+// @Param closure body entity.ClosureRequest true "Closure"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 201 {object} entity.Closure
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Router /closure [post]
+func (h *HandlerV1) CreateClosure(c *gin.Context) {
+	var body entity.ClosureRequest
+
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	var branchID int64
+	if body.BranchID != nil {
+		branchID = *body.BranchID
+	}
+	branchLoc, err := h.branchLocation(ctx, branchID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Branch not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	closure, err := closureFromRequest(&body, branchLoc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		return
+	}
+
+	closure, err = h.Service.Closure().Create(ctx, closure)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, localizeClosure(closure, loc))
+}
+
+// @Summary List upcoming closures
+// @Description Lists the current and upcoming closures of the hospital and its branches, earliest first.
+// @Tags Closure
+// @Accept json
+// @Produce json
+// @Param branch_id query int false "Only closures affecting this branch, including hospital-wide ones"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListClosures
+// @Failure 400 {object} entity.Error
+// @Router /closures [get]
+func (h *HandlerV1) ListClosures(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	var branchID int64
+	if raw := c.Query("branch_id"); raw != "" {
+		if branchID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid branch_id"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	closures, err := h.Service.Closure().List(ctx, time.Now(), branchID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, closure := range closures {
+		localizeClosure(closure, loc)
+	}
+
+	c.JSON(http.StatusOK, entity.ListClosures{
+		Closures:   closures,
+		TotalCount: int64(len(closures)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Delete a closure
+// @Description Withdraws a closure; free slots in it become bookable again.
+// @Tags Closure
+// @Accept json
+// @Produce json
+// @Param id path int true "Closure ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /closure/{id} [delete]
+func (h *HandlerV1) DeleteClosure(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid closure ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	if err = h.Service.Closure().Delete(ctx, id); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Closure not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Closure deleted"})
+}
+
+// closureFromRequest validates the request and works out the closed time
+// range on the wall clock of loc, the zone of the closed branch or of the
+// hospital.
+func closureFromRequest(body *entity.ClosureRequest, loc *time.Location) (*entity.Closure, error) {
+	if body.EndDate == "" {
+		body.EndDate = body.Date
+	}
+	start, startErr := time.ParseInLocation("2006-01-02", body.Date, loc)
+	end, endErr := time.ParseInLocation("2006-01-02", body.EndDate, loc)
+	if startErr != nil || endErr != nil || end.Before(start) {
+		return nil, errors.New("Date and EndDate must be YYYY-MM-DD with EndDate not before Date")
+	}
+
+	closure := &entity.Closure{
+		BranchID:  body.BranchID,
+		Date:      body.Date,
+		EndDate:   body.EndDate,
+		Reason:    body.Reason,
+		StartTime: start,
+		EndTime:   end.AddDate(0, 0, 1),
+	}
+	if body.FromTime == "" && body.ToTime == "" {
+		return closure, nil
+	}
+
+	if body.EndDate != body.Date {
+		return nil, errors.New("a partial closure covers a single day")
+	}
+	from, fromErr := time.ParseInLocation("2006-01-02 15:04", body.Date+" "+body.FromTime, loc)
+	to, toErr := time.ParseInLocation("2006-01-02 15:04", body.Date+" "+body.ToTime, loc)
+	if fromErr != nil || toErr != nil || !to.After(from) {
+		return nil, errors.New("FromTime and ToTime must be HH:MM with ToTime after FromTime")
+	}
+
+	closure.FromTime = from.Format("15:04")
+	closure.ToTime = to.Format("15:04")
+	closure.StartTime = from
+	closure.EndTime = to
+	return closure, nil
+}
+
+func localizeClosure(closure *entity.Closure, loc *time.Location) *entity.Closure {
+	closure.StartTime = closure.StartTime.In(loc)
+	closure.EndTime = closure.EndTime.In(loc)
+	closure.CreatedAt = closure.CreatedAt.In(loc)
+	return closure
+}
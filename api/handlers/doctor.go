@@ -7,10 +7,10 @@ import (
 	"strconv"
 
 	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/schedule"
 	"github.com/Abdulazizxoshimov/Hospital/pkg/time"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/k0kubun/pp"
 )
 
 // @Security BearerAuth
@@ -26,22 +26,22 @@ import (
 // @Router /doctor [post]
 func (h *HandlerV1) CreateDoctor(c *gin.Context) {
 	var body entity.Doctor
-	pp.Println("eeeeeeeeeee")
 
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request body"})
 		log.Println(err)
 		return
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
-	startTime, EndTime, err := time.ParseWorkTime(body.Working_hour)
+	startTime, endTime, err := time.ParseWorkTime(body.Working_hour)
 	if err != nil {
-		println(err.Error())
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		log.Println(err)
+		return
 	}
 
-	pp.Println(startTime, EndTime)
 	body.ID = uuid.New().String()
 	doctor, err := h.Service.Doctor().Create(ctx, &body)
 
@@ -51,6 +51,29 @@ func (h *HandlerV1) CreateDoctor(c *gin.Context) {
 		return
 	}
 
+	// seed a Monday to Friday schedule from the working hours so the doctor
+	// gets bookable slots without publishing them by hand
+	workDay := entity.ScheduleDay{
+		StartTime: startTime.Format("15:04"),
+		EndTime:   endTime.Format("15:04"),
+	}
+	defaultSchedule := &entity.Schedule{
+		DoctorID:    doctor.ID,
+		SlotMinutes: h.Config.Schedule.SlotMinutes,
+	}
+	for weekday := 1; weekday <= 5; weekday++ {
+		workDay.Weekday = weekday
+		defaultSchedule.Days = append(defaultSchedule.Days, workDay)
+	}
+
+	if err = schedule.Validate(defaultSchedule); err != nil {
+		log.Println(err)
+	} else if saved, err := h.Service.Schedule().Upsert(ctx, defaultSchedule); err != nil {
+		log.Println(err)
+	} else if _, err = schedule.NewGenerator(h.Service, h.Logger, h.Config.Schedule.WeeksAhead, h.Config.Location).Generate(ctx, saved); err != nil {
+		log.Println(err)
+	}
+
 	c.JSON(http.StatusCreated, entity.UserCreateResponse{
 		ID: doctor.ID,
 	})
@@ -69,7 +92,7 @@ func (h *HandlerV1) CreateDoctor(c *gin.Context) {
 func (h *HandlerV1) GetDoctor(c *gin.Context) {
 	id := c.Param("id")
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	doctor, err := h.Service.Doctor().Get(ctx, id)
@@ -103,7 +126,7 @@ func (h *HandlerV1) UpdateDoctor(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	doctor, err := h.Service.Doctor().Update(ctx, &body)
@@ -131,7 +154,7 @@ func (h *HandlerV1) UpdateDoctor(c *gin.Context) {
 func (h *HandlerV1) DeleteDoctor(c *gin.Context) {
 	id := c.Param("id")
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	if err := h.Service.Doctor().Delete(ctx, id); err != nil {
@@ -153,6 +176,7 @@ func (h *HandlerV1) DeleteDoctor(c *gin.Context) {
 // @Param  limit query string true "Limit"
 // @Param   name query string false "Name"
 // @Param   specialization query string false "Specialization"
+// @Param   branch_id query int false "Only doctors working at this branch"
 // @Success 200 {object} entity.ListDoctorRes
 // @Failure 400 {object} entity.Error
 // @Failure 500 {object} entity.Error
@@ -192,13 +216,20 @@ func (h *HandlerV1) ListDoctors(c *gin.Context) {
 	if specialization != "" {
 		req.Filter["specialization"] = specialization
 	}
+	if branchID := c.Query("branch_id"); branchID != "" {
+		if _, err := strconv.ParseInt(branchID, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid branch_id"})
+			return
+		}
+		req.Filter["branch_id"] = branchID
+	}
 
 	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request parameters"})
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	doctors, err := h.Service.Doctor().List(ctx, req)
@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// @Summary Write the encounter of an appointment
+// @Description Creates the draft clinical record of the visit or replaces its contents. Allowed for the appointment's doctor once the patient has checked in. Signed records cannot be changed; add an addendum instead.
+// @Tags Encounter
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param encounter body entity.EncounterRequest true "Chief complaint, SOAP notes and diagnoses"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.Encounter
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /appointment/{id}/encounter [put]
+func (h *HandlerV1) SaveEncounter(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	var body entity.EncounterRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, userID, ok := h.encounterAppointment(ctx, c)
+	if !ok {
+		return
+	}
+
+	// notes are written during or after the visit, not for one that
+	// never took place
+	switch appointment.Status {
+	case entity.AppointmentStatusCheckedIn, entity.AppointmentStatusInProgress, entity.AppointmentStatusCompleted:
+	default:
+		c.JSON(http.StatusConflict, entity.Error{Message: entity.ErrorIllegalTransition.Error()})
+		return
+	}
+
+	encounter := &entity.Encounter{
+		AppointmentID:  appointment.ID,
+		ChiefComplaint: body.ChiefComplaint,
+		Subjective:     body.Subjective,
+		Objective:      body.Objective,
+		Assessment:     body.Assessment,
+		Plan:           body.Plan,
+		Diagnoses:      body.Diagnoses,
+		CreatedBy:      userID,
+	}
+	if encounter.Diagnoses == nil {
+		encounter.Diagnoses = []*entity.Diagnosis{}
+	}
+
+	encounter, err = h.Service.Encounter().Save(ctx, encounter)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, localizeEncounter(encounter, loc))
+}
+
+// @Security BearerAuth
+// @Summary Get the encounter of an appointment
+// @Description Returns the full clinical record of the visit with its addenda. Allowed for the appointment's doctor; patients read signed records with GET /appointment/{id}/summary.
+// @Tags Encounter
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.Encounter
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment/{id}/encounter [get]
+func (h *HandlerV1) GetEncounter(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, _, ok := h.encounterAppointment(ctx, c)
+	if !ok {
+		return
+	}
+
+	encounter, err := h.Service.Encounter().GetByAppointment(ctx, appointment.ID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Encounter not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, localizeEncounter(encounter, loc))
+}
+
+// @Security BearerAuth
+// @Summary Sign the encounter of an appointment
+// @Description Makes the draft record final. Signed records cannot be changed, only amended with addenda, and become visible to the patient. Allowed for the appointment's doctor.
+// @Tags Encounter
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.Encounter
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /appointment/{id}/encounter/sign [post]
+func (h *HandlerV1) SignEncounter(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, userID, ok := h.encounterAppointment(ctx, c)
+	if !ok {
+		return
+	}
+
+	encounter, err := h.Service.Encounter().Sign(ctx, appointment.ID, userID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, localizeEncounter(encounter, loc))
+}
+
+// @Security BearerAuth
+// @Summary Amend a signed encounter
+// @Description Adds an addendum to the signed record of the visit; the record itself stays unchanged. Allowed for the appointment's doctor.
+// @Tags Encounter
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param addendum body entity.AddendumRequest true "Addendum"
+// @Success 201 {object} entity.Addendum
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /appointment/{id}/encounter/addendum [post]
+func (h *HandlerV1) AddEncounterAddendum(c *gin.Context) {
+	var body entity.AddendumRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, userID, ok := h.encounterAppointment(ctx, c)
+	if !ok {
+		return
+	}
+
+	addendum, err := h.Service.Encounter().AddAddendum(ctx, appointment.ID, &entity.Addendum{
+		Text:      body.Text,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, addendum)
+}
+
+// @Security BearerAuth
+// @Summary Visit summary of an appointment
+// @Description Returns the patient's summary of the signed record of the visit: chief complaint, diagnoses, plan and addenda. Allowed for the patient of the appointment.
+// @Tags Encounter
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.EncounterSummary
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment/{id}/summary [get]
+func (h *HandlerV1) GetEncounterSummary(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Appointment not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if !h.canAccessAppointment(ctx, c, appointment, entity.RoleUser) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	// drafts are not shown to the patient
+	encounter, err := h.Service.Encounter().GetByAppointment(ctx, appointment.ID)
+	if err == nil && encounter.Status != entity.EncounterStatusSigned {
+		err = entity.ErrorNotFound
+	}
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Visit summary not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, localizeEncounterSummary(&entity.EncounterSummary{
+		EncounterID:    encounter.ID,
+		AppointmentID:  encounter.AppointmentID,
+		DoctorID:       encounter.DoctorID,
+		StartTime:      appointment.StartTime,
+		ChiefComplaint: encounter.ChiefComplaint,
+		Diagnoses:      encounter.Diagnoses,
+		Plan:           encounter.Plan,
+		Addenda:        encounter.Addenda,
+		SignedAt:       *encounter.SignedAt,
+	}, loc))
+}
+
+// @Security BearerAuth
+// @Summary My visit summaries
+// @Description Lists the summaries of the caller's signed visit records, latest visit first
+// @Tags Encounter
+// @Accept json
+// @Produce json
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListEncounterSummaries
+// @Failure 401 {object} entity.Error
+// @Router /me/encounters [get]
+func (h *HandlerV1) ListMyEncounters(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	userID, _, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, entity.Error{Message: "Unauthorized"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	summaries, err := h.Service.Encounter().Summaries(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, summary := range summaries {
+		localizeEncounterSummary(summary, loc)
+	}
+
+	c.JSON(http.StatusOK, entity.ListEncounterSummaries{
+		Summaries:  summaries,
+		TotalCount: int64(len(summaries)),
+	})
+}
+
+// encounterAppointment loads the appointment of the request and checks the
+// caller is its doctor. It returns the caller's user ID and writes the
+// error response itself.
+func (h *HandlerV1) encounterAppointment(ctx context.Context, c *gin.Context) (*entity.Appointment, string, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return nil, "", false
+	}
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Appointment not found"})
+		h.Logger.Error(err.Error())
+		return nil, "", false
+	}
+
+	userID, _, err := h.caller(c)
+	if err != nil || !h.canAccessAppointment(ctx, c, appointment, entity.RoleDoctor) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return nil, "", false
+	}
+
+	return appointment, userID, true
+}
+
+func localizeEncounter(encounter *entity.Encounter, loc *time.Location) *entity.Encounter {
+	encounter.CreatedAt = encounter.CreatedAt.In(loc)
+	encounter.UpdatedAt = encounter.UpdatedAt.In(loc)
+	if encounter.SignedAt != nil {
+		signedAt := encounter.SignedAt.In(loc)
+		encounter.SignedAt = &signedAt
+	}
+	for _, addendum := range encounter.Addenda {
+		addendum.CreatedAt = addendum.CreatedAt.In(loc)
+	}
+	return encounter
+}
+
+func localizeEncounterSummary(summary *entity.EncounterSummary, loc *time.Location) *entity.EncounterSummary {
+	summary.StartTime = summary.StartTime.In(loc)
+	summary.SignedAt = summary.SignedAt.In(loc)
+	for _, addendum := range summary.Addenda {
+		addendum.CreatedAt = addendum.CreatedAt.In(loc)
+	}
+	return summary
+}
@@ -1,15 +1,24 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Abdulazizxoshimov/Hospital/config"
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/prescription"
 	"github.com/Abdulazizxoshimov/Hospital/internal/repo"
 	redis "github.com/Abdulazizxoshimov/Hospital/internal/repo/redisdb"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/filestore"
 	"github.com/Abdulazizxoshimov/Hospital/pkg/logger"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/tenant"
 	tokens "github.com/Abdulazizxoshimov/Hospital/pkg/token"
 
 	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
 )
 
 type HandlerV1 struct {
@@ -20,6 +29,8 @@ type HandlerV1 struct {
 	RefreshToken   tokens.JWTHandler
 	Enforcer       *casbin.Enforcer
 	Service        repo.StorageI
+	Files          filestore.Store
+	Interactions   []*prescription.Rule
 }
 
 // HandlerV1Config ...
@@ -31,6 +42,8 @@ type HandlerV1Config struct {
 	RefreshToken   tokens.JWTHandler
 	Enforcer       *casbin.Enforcer
 	Service        repo.StorageI
+	Files          filestore.Store
+	Interactions   []*prescription.Rule
 }
 
 // New ...
@@ -43,5 +56,166 @@ func New(c *HandlerV1Config) *HandlerV1 {
 		Enforcer:       c.Enforcer,
 		RefreshToken:   c.RefreshToken,
 		Service:        c.Service,
+		Files:          c.Files,
+		Interactions:   c.Interactions,
 	}
 }
+
+// statusFromError maps repository errors onto HTTP status codes.
+func statusFromError(err error) int {
+	var (
+		notFound *entity.ErrNotFound
+		conflict *entity.ErrConflict
+		noParam  *entity.ErrNoRequiredParameter
+	)
+
+	switch {
+	case errors.As(err, &noParam):
+		return http.StatusBadRequest
+	case errors.As(err, &notFound):
+		return http.StatusNotFound
+	case errors.As(err, &conflict),
+		errors.Is(err, entity.ErrorSlotOverlap),
+		errors.Is(err, entity.ErrorSlotBooked),
+		errors.Is(err, entity.ErrorSlotUnavailable),
+		errors.Is(err, entity.ErrorSlotTaken),
+		errors.Is(err, entity.ErrorIllegalTransition),
+		errors.Is(err, entity.ErrorOfferClosed),
+		errors.Is(err, entity.ErrorSlotHeld),
+		errors.Is(err, entity.ErrorHoldExpired),
+		errors.Is(err, entity.ErrorNoSubstitute),
+		errors.Is(err, entity.ErrorClosed),
+		errors.Is(err, entity.ErrorSeriesUnavailable),
+		errors.Is(err, entity.ErrorResourceUnavailable),
+		errors.Is(err, entity.ErrorResourceInUse),
+		errors.Is(err, entity.ErrorBranchNotAssigned),
+		errors.Is(err, entity.ErrorBranchInUse),
+		errors.Is(err, entity.ErrorEncounterSigned),
+		errors.Is(err, entity.ErrorEncounterNotSigned),
+		errors.Is(err, entity.ErrorLabOrderStatus):
+		return http.StatusConflict
+	case errors.Is(err, entity.ErrorForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, entity.ErrorPolicyViolation):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// tenantContext returns a context scoped to the hospital the request was
+// resolved to, which the database limits every query to.
+func (h *HandlerV1) tenantContext(c *gin.Context) context.Context {
+	return tenant.NewContext(context.Background(), c.GetInt64(tenant.Key))
+}
+
+// doctorScope resolves the doctor the caller may act on. Admins act on the
+// requested doctor, doctors only ever on their own profile.
+func (h *HandlerV1) doctorScope(ctx context.Context, c *gin.Context, requested string) (string, error) {
+	role, status := tokens.GetRoleFromToken(c.Request, &h.Config)
+	if status != 0 {
+		return "", entity.ErrorForbidden
+	}
+	if role == "admin" {
+		if requested == "" {
+			return "", entity.NewErrNoRequiredParameter("doctor_id")
+		}
+		return requested, nil
+	}
+
+	userID, status := tokens.GetIdFromToken(c.Request, &h.Config)
+	if status != 0 {
+		return "", entity.ErrorForbidden
+	}
+
+	doctor, err := h.Service.Doctor().GetByUserID(ctx, userID)
+	if err != nil {
+		return "", entity.ErrorForbidden
+	}
+	if requested != "" && requested != doctor.ID {
+		return "", entity.ErrorForbidden
+	}
+
+	return doctor.ID, nil
+}
+
+// requestLocation returns the zone the caller wants times rendered in: the
+// tz query parameter, then the Time-Zone header, then the hospital zone.
+func (h *HandlerV1) requestLocation(c *gin.Context) (*time.Location, error) {
+	name := c.Query("tz")
+	if name == "" {
+		name = c.GetHeader("Time-Zone")
+	}
+	if name == "" {
+		return h.hospitalLocation(), nil
+	}
+
+	return time.LoadLocation(name)
+}
+
+func (h *HandlerV1) hospitalLocation() *time.Location {
+	if h.Config.Location == nil {
+		return time.UTC
+	}
+	return h.Config.Location
+}
+
+func localizeAppointment(appointment *entity.Appointment, loc *time.Location) *entity.Appointment {
+	appointment.StartTime = appointment.StartTime.In(loc)
+	appointment.EndTime = appointment.EndTime.In(loc)
+	return appointment
+}
+
+func localizeAvailability(availability *entity.Availability, loc *time.Location) *entity.Availability {
+	availability.StartTime = availability.StartTime.In(loc)
+	availability.EndTime = availability.EndTime.In(loc)
+	return availability
+}
+
+// caller returns the user ID and role carried by the request's access token.
+func (h *HandlerV1) caller(c *gin.Context) (string, string, error) {
+	userID, status := tokens.GetIdFromToken(c.Request, &h.Config)
+	if status != 0 {
+		return "", "", entity.ErrorForbidden
+	}
+	role, status := tokens.GetRoleFromToken(c.Request, &h.Config)
+	if status != 0 {
+		return "", "", entity.ErrorForbidden
+	}
+
+	return userID, role, nil
+}
+
+// policyOverride reports whether an admin asked to bypass the cancellation
+// policy with ?override=true. The policy decides if that is honoured.
+func (h *HandlerV1) policyOverride(c *gin.Context, role string) bool {
+	override, _ := strconv.ParseBool(c.Query("override"))
+	return override && role == entity.RoleAdmin
+}
+
+// canAccessAppointment reports whether the caller may act on the
+// appointment in the given role: patients on their own bookings, doctors on
+// their own agenda, receptionists and admins on everything.
+func (h *HandlerV1) canAccessAppointment(ctx context.Context, c *gin.Context, appointment *entity.Appointment, role string) bool {
+	userID, callerRole, err := h.caller(c)
+	if err != nil {
+		return false
+	}
+
+	switch role {
+	case entity.RoleUser:
+		return userID == appointment.UserID
+	case entity.RoleDoctor:
+		if callerRole != entity.RoleDoctor && callerRole != entity.RoleAdmin {
+			return false
+		}
+		_, err := h.doctorScope(ctx, c, appointment.DoctorID)
+		return err == nil
+	case entity.RoleReceptionist:
+		return callerRole == entity.RoleReceptionist || callerRole == entity.RoleAdmin
+	case entity.RoleAdmin:
+		return callerRole == entity.RoleAdmin
+	}
+
+	return false
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// holdKeyPrefix keys the hold by its token
+const holdKeyPrefix = "hold:"
+
+// @Security BearerAuth
+// @Summary Hold slots for checkout
+// @Description First step of a booking: holds the slots a visit of AppointmentTypeID at StartTime (RFC 3339) would use, for a limited time. Confirm the hold to book it; an abandoned hold expires on its own. Receptionists and admins may hold for another patient via PatientID.
+// @Tags Appointment
+// @Accept json
+// @Produce json
+// @Param hold body entity.SlotHold true "Slot hold"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Param Idempotency-Key header string false "Retries with the same key and payload replay the first response"
+// @Success 201 {object} entity.SlotHold
+// @Failure 400 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Failure 422 {object} entity.Error
+// @Router /slot-hold [post]
+func (h *HandlerV1) HoldSlot(c *gin.Context) {
+	var body entity.SlotHold
+
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if body.DoctorID == "" || !body.StartTime.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "DoctorID and a future StartTime are required"})
+		return
+	}
+
+	userID, role, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, entity.Error{Message: err.Error()})
+		return
+	}
+	if body.PatientID == "" || (role != entity.RoleReceptionist && role != entity.RoleAdmin) {
+		body.PatientID = userID
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	hold := &entity.SlotHold{
+		Token:             uuid.NewString(),
+		DoctorID:          body.DoctorID,
+		PatientID:         body.PatientID,
+		AppointmentTypeID: body.AppointmentTypeID,
+		StartTime:         body.StartTime,
+		ExpiresAt:         time.Now().Add(h.Config.Booking.HoldDuration),
+	}
+
+	// the slots are held in the database, so every booking path, not only
+	// this checkout, leaves them to the patient until the hold expires
+	if err = h.Service.Appointment().HoldSlots(ctx, hold); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err = h.redisStorage.Set(ctx, holdKeyPrefix+hold.Token, hold, h.Config.Booking.HoldDuration); err != nil {
+		h.releaseHold(ctx, hold)
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, localizeSlotHold(hold, loc))
+}
+
+// @Security BearerAuth
+// @Summary Confirm a slot hold
+// @Description Second step of a booking: turns the hold into an appointment. Fails with 409 once the hold has expired.
+// @Tags Appointment
+// @Accept json
+// @Produce json
+// @Param token path string true "Hold token"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Param Idempotency-Key header string false "Retries with the same key and payload replay the first response"
+// @Success 201 {object} entity.Appointment
+// @Failure 403 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Failure 422 {object} entity.Error
+// @Router /slot-hold/{token}/confirm [post]
+func (h *HandlerV1) ConfirmSlotHold(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	hold, ok := h.ownSlotHold(ctx, c, c.Param("token"))
+	if !ok {
+		return
+	}
+
+	appointment, err := h.Service.Appointment().CreateAppointment(ctx, &entity.Appointment{
+		DoctorID:          hold.DoctorID,
+		UserID:            hold.PatientID,
+		AppointmentTypeID: hold.AppointmentTypeID,
+		Appointment_time: map[string]interface{}{
+			"start_time": hold.StartTime.Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Failed to create appointment: " + err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+	h.releaseHold(ctx, hold)
+
+	c.JSON(http.StatusCreated, localizeAppointment(appointment, loc))
+}
+
+// @Security BearerAuth
+// @Summary Release a slot hold
+// @Description Gives the held slots back before the hold expires, e.g. when the checkout is abandoned.
+// @Tags Appointment
+// @Accept json
+// @Produce json
+// @Param token path string true "Hold token"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /slot-hold/{token} [delete]
+func (h *HandlerV1) ReleaseSlotHold(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	hold, ok := h.ownSlotHold(ctx, c, c.Param("token"))
+	if !ok {
+		return
+	}
+	h.releaseHold(ctx, hold)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Hold released"})
+}
+
+// getSlotHold loads a hold by token. A missing key means it expired.
+func (h *HandlerV1) getSlotHold(ctx context.Context, token string) (*entity.SlotHold, error) {
+	data, err := h.redisStorage.Get(ctx, holdKeyPrefix+token)
+	if err != nil {
+		return nil, entity.ErrorHoldExpired
+	}
+
+	var hold entity.SlotHold
+	if err = json.Unmarshal(data, &hold); err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+// ownSlotHold loads the hold and checks it belongs to the caller, or that
+// the caller is staff. It writes the error response itself.
+func (h *HandlerV1) ownSlotHold(ctx context.Context, c *gin.Context, token string) (*entity.SlotHold, bool) {
+	hold, err := h.getSlotHold(ctx, token)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		return nil, false
+	}
+
+	userID, role, err := h.caller(c)
+	if err != nil || (hold.PatientID != userID && role != entity.RoleReceptionist && role != entity.RoleAdmin) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return nil, false
+	}
+
+	return hold, true
+}
+
+// releaseHold gives the held slots back and drops the hold.
+func (h *HandlerV1) releaseHold(ctx context.Context, hold *entity.SlotHold) {
+	if err := h.Service.Appointment().ReleaseSlotHold(ctx, hold.Token); err != nil {
+		h.Logger.Error(err.Error())
+	}
+	if err := h.redisStorage.Del(ctx, holdKeyPrefix+hold.Token); err != nil {
+		h.Logger.Error(err.Error())
+	}
+}
+
+func localizeSlotHold(hold *entity.SlotHold, loc *time.Location) *entity.SlotHold {
+	hold.StartTime = hold.StartTime.In(loc)
+	hold.EndTime = hold.EndTime.In(loc)
+	hold.ExpiresAt = hold.ExpiresAt.In(loc)
+	return hold
+}
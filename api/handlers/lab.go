@@ -0,0 +1,502 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// @Summary Order a lab test
+// @Description Orders a lab test for the patient of the appointment. Tests cannot be ordered for cancelled or missed appointments. Allowed for the appointment's doctor.
+// @Tags Lab
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param order body entity.LabOrderRequest true "Lab order"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 201 {object} entity.LabOrder
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /appointment/{id}/lab-order [post]
+func (h *HandlerV1) CreateLabOrder(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	var body entity.LabOrderRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, userID, ok := h.encounterAppointment(ctx, c)
+	if !ok {
+		return
+	}
+	if appointment.Status == entity.AppointmentStatusCancelled || appointment.Status == entity.AppointmentStatusNoShow {
+		c.JSON(http.StatusConflict, entity.Error{Message: entity.ErrorIllegalTransition.Error()})
+		return
+	}
+
+	order, err := h.Service.LabOrder().Create(ctx, &entity.LabOrder{
+		AppointmentID: appointment.ID,
+		TestCode:      body.TestCode,
+		TestName:      body.TestName,
+		Notes:         body.Notes,
+		OrderedBy:     userID,
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, presentLabOrder(order, entity.RoleDoctor, loc))
+}
+
+// @Security BearerAuth
+// @Summary Lab orders of an appointment
+// @Description Lists the lab tests ordered at the appointment, oldest first. Results are only shown once the lab released them. Allowed for the patient, the appointment's doctor and lab staff.
+// @Tags Lab
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListLabOrders
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment/{id}/lab-orders [get]
+func (h *HandlerV1) ListAppointmentLabOrders(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Appointment not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	role, ok := h.labOrderReader(ctx, c, appointment)
+	if !ok {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	h.listLabOrders(ctx, c, &entity.LabOrderFilter{AppointmentID: appointment.ID}, role, loc)
+}
+
+// @Security BearerAuth
+// @Summary My lab orders
+// @Description Lists the lab tests ordered for the caller, oldest first. Results are only shown once the lab released them.
+// @Tags Lab
+// @Accept json
+// @Produce json
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListLabOrders
+// @Failure 401 {object} entity.Error
+// @Router /me/lab-orders [get]
+func (h *HandlerV1) ListMyLabOrders(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	userID, _, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, entity.Error{Message: "Unauthorized"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	h.listLabOrders(ctx, c, &entity.LabOrderFilter{PatientID: userID}, entity.RoleUser, loc)
+}
+
+// @Security BearerAuth
+// @Summary Lab worklist
+// @Description Lists lab orders for the lab to work on, oldest first, optionally only those in one status (ordered, resulted, released or cancelled). Allowed for lab staff.
+// @Tags Lab
+// @Accept json
+// @Produce json
+// @Param status query string false "Order status"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListLabOrders
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Router /lab-orders [get]
+func (h *HandlerV1) ListLabOrders(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", entity.LabOrderStatusOrdered, entity.LabOrderStatusResulted, entity.LabOrderStatusReleased, entity.LabOrderStatusCancelled:
+	default:
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid status"})
+		return
+	}
+
+	_, role, err := h.caller(c)
+	if err != nil || !isLabStaff(role) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	h.listLabOrders(ctx, c, &entity.LabOrderFilter{Status: status}, role, loc)
+}
+
+// @Security BearerAuth
+// @Summary Get a lab order
+// @Description Returns a lab order. Its results are only shown once the lab released them. Allowed for the patient, the appointment's doctor and lab staff.
+// @Tags Lab
+// @Accept json
+// @Produce json
+// @Param id path int true "Lab order ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.LabOrder
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /lab-order/{id} [get]
+func (h *HandlerV1) GetLabOrder(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	order, role, ok := h.readableLabOrder(ctx, c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, presentLabOrder(order, role, loc))
+}
+
+// @Security BearerAuth
+// @Summary Record lab results
+// @Description Records the results of a lab order, replacing those recorded before, until they are released. Numeric results are flagged low or high against their reference range; text results can be flagged with Abnormal. Tests without separate results take a narrative Report. Allowed for lab staff.
+// @Tags Lab
+// @Accept json
+// @Produce json
+// @Param id path int true "Lab order ID"
+// @Param results body entity.LabResultsRequest true "Results"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.LabOrder
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /lab-order/{id}/results [put]
+func (h *HandlerV1) RecordLabResults(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	var body entity.LabResultsRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if len(body.Results) == 0 && body.Report == "" {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Results or a report are required"})
+		return
+	}
+
+	results := make([]*entity.LabResult, 0, len(body.Results))
+	for _, result := range body.Results {
+		if result.Value == nil && result.Text == "" {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "Result " + result.Analyte + " needs a value or a text"})
+			return
+		}
+		if result.ReferenceLow != nil && result.ReferenceHigh != nil && *result.ReferenceLow > *result.ReferenceHigh {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "Reference range of " + result.Analyte + " is inverted"})
+			return
+		}
+		results = append(results, &entity.LabResult{
+			Analyte:       result.Analyte,
+			Value:         result.Value,
+			Text:          result.Text,
+			Unit:          result.Unit,
+			ReferenceLow:  result.ReferenceLow,
+			ReferenceHigh: result.ReferenceHigh,
+			Flag:          labResultFlag(result),
+		})
+	}
+
+	userID, role, err := h.caller(c)
+	if err != nil || !isLabStaff(role) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	order, err := h.Service.LabOrder().RecordResults(ctx, id, results, body.Report, userID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, presentLabOrder(order, role, loc))
+}
+
+// @Security BearerAuth
+// @Summary Release lab results
+// @Description Releases the recorded results of a lab order to the ordering doctor and the patient. Released results are final. Allowed for lab staff.
+// @Tags Lab
+// @Accept json
+// @Produce json
+// @Param id path int true "Lab order ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.LabOrder
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /lab-order/{id}/release [post]
+func (h *HandlerV1) ReleaseLabOrder(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	userID, role, err := h.caller(c)
+	if err != nil || !isLabStaff(role) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	order, err := h.Service.LabOrder().Release(ctx, id, userID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, presentLabOrder(order, role, loc))
+}
+
+// @Security BearerAuth
+// @Summary Cancel a lab order
+// @Description Cancels a lab order the lab has not recorded results for yet. Allowed for the appointment's doctor.
+// @Tags Lab
+// @Accept json
+// @Produce json
+// @Param id path int true "Lab order ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.LabOrder
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /lab-order/{id}/cancel [post]
+func (h *HandlerV1) CancelLabOrder(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	order, _, ok := h.readableLabOrder(ctx, c)
+	if !ok {
+		return
+	}
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, int(order.AppointmentID))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Lab order not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if !h.canAccessAppointment(ctx, c, appointment, entity.RoleDoctor) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	order, err = h.Service.LabOrder().Cancel(ctx, order.ID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, presentLabOrder(order, entity.RoleDoctor, loc))
+}
+
+func (h *HandlerV1) listLabOrders(ctx context.Context, c *gin.Context, filter *entity.LabOrderFilter, role string, loc *time.Location) {
+	orders, err := h.Service.LabOrder().List(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, order := range orders {
+		presentLabOrder(order, role, loc)
+	}
+
+	c.JSON(http.StatusOK, entity.ListLabOrders{
+		LabOrders:  orders,
+		TotalCount: int64(len(orders)),
+	})
+}
+
+// readableLabOrder loads the lab order of the request and checks the caller
+// may read it, returning the role it is read as. It writes the error
+// response itself.
+func (h *HandlerV1) readableLabOrder(ctx context.Context, c *gin.Context) (*entity.LabOrder, string, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return nil, "", false
+	}
+
+	order, err := h.Service.LabOrder().Get(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Lab order not found"})
+		h.Logger.Error(err.Error())
+		return nil, "", false
+	}
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, int(order.AppointmentID))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Lab order not found"})
+		h.Logger.Error(err.Error())
+		return nil, "", false
+	}
+	role, ok := h.labOrderReader(ctx, c, appointment)
+	if !ok {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return nil, "", false
+	}
+
+	return order, role, true
+}
+
+// labOrderReader reports whether the caller may read the lab orders of the
+// appointment: lab staff, the patient and the appointment's doctor may.
+// The role returned decides whether unreleased results are shown.
+func (h *HandlerV1) labOrderReader(ctx context.Context, c *gin.Context, appointment *entity.Appointment) (string, bool) {
+	_, role, err := h.caller(c)
+	if err != nil {
+		return "", false
+	}
+
+	switch {
+	case isLabStaff(role):
+		return role, true
+	case h.canAccessAppointment(ctx, c, appointment, entity.RoleDoctor):
+		return entity.RoleDoctor, true
+	case h.canAccessAppointment(ctx, c, appointment, entity.RoleUser):
+		return entity.RoleUser, true
+	}
+
+	return "", false
+}
+
+func isLabStaff(role string) bool {
+	return role == entity.RoleLab || role == entity.RoleAdmin
+}
+
+// labResultFlag flags a numeric result outside its reference range, or any
+// result the lab marked abnormal.
+func labResultFlag(result *entity.LabResultRequest) string {
+	if result.Value != nil {
+		if result.ReferenceLow != nil && *result.Value < *result.ReferenceLow {
+			return entity.LabFlagLow
+		}
+		if result.ReferenceHigh != nil && *result.Value > *result.ReferenceHigh {
+			return entity.LabFlagHigh
+		}
+	}
+	if result.Abnormal {
+		return entity.LabFlagAbnormal
+	}
+	return ""
+}
+
+// presentLabOrder localizes the order and, unless the caller is lab staff,
+// hides results that are not released yet.
+func presentLabOrder(order *entity.LabOrder, role string, loc *time.Location) *entity.LabOrder {
+	if !isLabStaff(role) && order.Status != entity.LabOrderStatusReleased {
+		order.Results = []*entity.LabResult{}
+		order.Report = ""
+		order.Abnormal = false
+	}
+
+	order.CreatedAt = order.CreatedAt.In(loc)
+	if order.ResultedAt != nil {
+		resultedAt := order.ResultedAt.In(loc)
+		order.ResultedAt = &resultedAt
+	}
+	if order.ReleasedAt != nil {
+		releasedAt := order.ReleasedAt.In(loc)
+		order.ReleasedAt = &releasedAt
+	}
+	return order
+}
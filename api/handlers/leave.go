@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/gmail"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	appointmentChangeTemplate = "./pkg/gmail/appointmentchange.html"
+
+	// leaveAppointmentLimit bounds how many appointments one leave handles
+	leaveAppointmentLimit = 1000
+)
+
+// @Security BearerAuth
+// @Summary Create a doctor leave
+// @Description Records a leave from StartDate to EndDate (YYYY-MM-DD, inclusive, in the tz zone). No new bookings can be made with the doctor during it. The appointments already booked in the leave are returned; with Action "cancel" they are cancelled, with "reassign" handed to ReassignTo or any free doctor of the same specialization. Affected patients are notified by email. Doctors create their own leaves, admins any doctor's.
+// @Tags Leave
+// @Accept json
+// @Produce json
+// @Param leave body entity.DoctorLeaveRequest true "Leave"
+// @Param tz query string false "IANA time zone of the dates and returned times, defaults to the hospital zone"
+// @Success 201 {object} entity.DoctorLeaveResult
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Router /doctor/leave [post]
+func (h *HandlerV1) CreateDoctorLeave(c *gin.Context) {
+	var body entity.DoctorLeaveRequest
+
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if !validLeaveAction(body.Action) {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Action must be empty, cancel or reassign"})
+		return
+	}
+
+	start, startErr := time.ParseInLocation("2006-01-02", body.StartDate, loc)
+	end, endErr := time.ParseInLocation("2006-01-02", body.EndDate, loc)
+	if startErr != nil || endErr != nil || end.Before(start) {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "StartDate and EndDate must be YYYY-MM-DD with EndDate not before StartDate"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, body.DoctorID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+	userID, _, _ := h.caller(c)
+
+	leave, err := h.Service.DoctorLeave().Create(ctx, &entity.DoctorLeave{
+		DoctorID:  doctorID,
+		StartDate: body.StartDate,
+		EndDate:   body.EndDate,
+		StartTime: start,
+		EndTime:   end.AddDate(0, 0, 1),
+		Reason:    body.Reason,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	result, err := h.resolveLeave(ctx, c, leave, &body.LeaveResolution, loc)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// @Security BearerAuth
+// @Summary List doctor leaves
+// @Description Lists the doctor's current and upcoming leaves.
+// @Tags Leave
+// @Accept json
+// @Produce json
+// @Param doctor_id query string false "Doctor ID, required for admins"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListDoctorLeaves
+// @Failure 403 {object} entity.Error
+// @Router /doctor/leaves [get]
+func (h *HandlerV1) ListDoctorLeaves(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, c.Query("doctor_id"))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	leaves, err := h.Service.DoctorLeave().List(ctx, doctorID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, leave := range leaves {
+		localizeDoctorLeave(leave, loc)
+	}
+
+	c.JSON(http.StatusOK, entity.ListDoctorLeaves{
+		Leaves:     leaves,
+		TotalCount: int64(len(leaves)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Appointments affected by a leave
+// @Description Lists the appointments still booked during the leave.
+// @Tags Leave
+// @Accept json
+// @Produce json
+// @Param id path int true "Leave ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.DoctorLeaveResult
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /doctor/leave/{id}/appointments [get]
+func (h *HandlerV1) GetLeaveAppointments(c *gin.Context) {
+	h.handleLeaveAppointments(c, &entity.LeaveResolution{})
+}
+
+// @Security BearerAuth
+// @Summary Resolve the appointments of a leave
+// @Description Cancels the appointments still booked during the leave, or reassigns them to ReassignTo or any free doctor of the same specialization. Patients are notified by email. Appointments that cannot be handled are reported with Outcome "failed".
+// @Tags Leave
+// @Accept json
+// @Produce json
+// @Param id path int true "Leave ID"
+// @Param resolution body entity.LeaveResolution true "What to do"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.DoctorLeaveResult
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /doctor/leave/{id}/resolve [post]
+func (h *HandlerV1) ResolveDoctorLeave(c *gin.Context) {
+	var body entity.LeaveResolution
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if body.Action == entity.LeaveActionNone || !validLeaveAction(body.Action) {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Action must be cancel or reassign"})
+		return
+	}
+
+	h.handleLeaveAppointments(c, &body)
+}
+
+// @Security BearerAuth
+// @Summary Delete a doctor leave
+// @Description Ends the leave early or withdraws it; the doctor's slots in it become bookable again. Appointments already cancelled or reassigned stay so.
+// @Tags Leave
+// @Accept json
+// @Produce json
+// @Param id path int true "Leave ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /doctor/leave/{id} [delete]
+func (h *HandlerV1) DeleteDoctorLeave(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	leave, ok := h.ownDoctorLeave(ctx, c, id)
+	if !ok {
+		return
+	}
+
+	if err = h.Service.DoctorLeave().Delete(ctx, id); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Leave not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	h.offerFreedSlots(c, leave.DoctorID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Leave deleted"})
+}
+
+func (h *HandlerV1) handleLeaveAppointments(c *gin.Context, resolution *entity.LeaveResolution) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	leave, ok := h.ownDoctorLeave(ctx, c, id)
+	if !ok {
+		return
+	}
+
+	result, err := h.resolveLeave(ctx, c, leave, resolution, loc)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ownDoctorLeave loads the leave and checks the caller may manage it. It
+// writes the error response itself.
+func (h *HandlerV1) ownDoctorLeave(ctx context.Context, c *gin.Context, id int64) (*entity.DoctorLeave, bool) {
+	leave, err := h.Service.DoctorLeave().Get(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Leave not found"})
+		h.Logger.Error(err.Error())
+		return nil, false
+	}
+
+	if _, err = h.doctorScope(ctx, c, leave.DoctorID); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		return nil, false
+	}
+
+	return leave, true
+}
+
+// resolveLeave lists the live appointments booked during the leave and
+// applies the resolution to each of them. A failure on one appointment is
+// reported in its outcome and does not stop the others.
+func (h *HandlerV1) resolveLeave(ctx context.Context, c *gin.Context, leave *entity.DoctorLeave, resolution *entity.LeaveResolution, loc *time.Location) (*entity.DoctorLeaveResult, error) {
+	appointments, _, err := h.Service.Appointment().ListAppointments(ctx, &entity.AppointmentFilter{
+		DoctorID:  leave.DoctorID,
+		StartFrom: leave.StartTime,
+		StartTo:   leave.EndTime,
+		Statuses:  []string{entity.AppointmentStatusScheduled, entity.AppointmentStatusConfirmed},
+		Page:      1,
+		Limit:     leaveAppointmentLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	userID, _, _ := h.caller(c)
+	result := &entity.DoctorLeaveResult{
+		Leave:        localizeDoctorLeave(leave, loc),
+		Appointments: []*entity.LeaveAppointment{},
+	}
+	for _, appointment := range appointments {
+		entry := &entity.LeaveAppointment{
+			Appointment: *appointment,
+			Outcome:     entity.LeaveOutcomeKept,
+		}
+
+		var updated *entity.Appointment
+		switch resolution.Action {
+		case entity.LeaveActionCancel:
+			updated, err = h.Service.Appointment().TransitionAppointment(ctx, int(appointment.ID),
+				entity.AppointmentTransitions["cancel"], userID, "doctor on leave: "+leave.Reason)
+			entry.Outcome = entity.LeaveOutcomeCancelled
+		case entity.LeaveActionReassign:
+			updated, err = h.Service.Appointment().ReassignAppointment(ctx, int(appointment.ID), resolution.ReassignTo)
+			entry.Outcome = entity.LeaveOutcomeReassigned
+		}
+
+		switch {
+		case err != nil:
+			entry.Outcome, entry.Error = entity.LeaveOutcomeFailed, err.Error()
+			h.Logger.Error(err.Error())
+			err = nil
+		case updated != nil:
+			entry.Appointment = *updated
+			h.notifyAppointmentChange(c, updated, leaveChangeMessage(entry.Outcome))
+		}
+
+		localizeAppointment(&entry.Appointment, loc)
+		result.Appointments = append(result.Appointments, entry)
+	}
+
+	if resolution.Action == entity.LeaveActionReassign {
+		h.offerFreedSlots(c, leave.DoctorID)
+	}
+
+	return result, nil
+}
+
+func leaveChangeMessage(outcome string) string {
+	if outcome == entity.LeaveOutcomeReassigned {
+		return "Your doctor is unavailable at this time, so your appointment has been moved to another doctor at the same time."
+	}
+	return "Your doctor is unavailable at this time, so your appointment has been cancelled. Please book a new time."
+}
+
+// notifyAppointmentChange mails the patient about a change the hospital
+// made to their appointment, without holding up the response.
+func (h *HandlerV1) notifyAppointmentChange(c *gin.Context, appointment *entity.Appointment, message string) {
+	base := h.tenantContext(c)
+	go func() {
+		ctx, cancel := context.WithTimeout(base, h.Config.Context.Timeout)
+		defer cancel()
+
+		user, err := h.Service.User().Get(ctx, map[string]string{"id": appointment.UserID})
+		if err != nil {
+			h.Logger.Error(err.Error())
+			return
+		}
+		if user.Email == "" {
+			return
+		}
+
+		if err = gmail.SendTemplateGmail(user.Email, "Hospital\n", appointmentChangeTemplate, struct {
+			Message   string
+			StartTime string
+		}{
+			Message:   message,
+			StartTime: appointment.StartTime.In(h.Config.Location).Format("2006-01-02 15:04"),
+		}, h.Config); err != nil {
+			h.Logger.Error(err.Error())
+		}
+	}()
+}
+
+func validLeaveAction(action string) bool {
+	return action == entity.LeaveActionNone || action == entity.LeaveActionCancel || action == entity.LeaveActionReassign
+}
+
+func localizeDoctorLeave(leave *entity.DoctorLeave, loc *time.Location) *entity.DoctorLeave {
+	leave.StartTime = leave.StartTime.In(loc)
+	leave.EndTime = leave.EndTime.In(loc)
+	leave.CreatedAt = leave.CreatedAt.In(loc)
+	return leave
+}
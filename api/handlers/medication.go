@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/prescription"
+	"github.com/gin-gonic/gin"
+)
+
+// maxCatalogueImportSize caps uploaded medication catalogues
+const maxCatalogueImportSize = 10 << 20
+
+var errCatalogueMissing = errors.New("upload the catalogue as file")
+
+// @Security BearerAuth
+// @Summary Add a medication
+// @Description Adds a medication to the catalogue. Ingredients are the active substances that allergies and interaction rules are matched against.
+// @Tags Medication
+// @Accept json
+// @Produce json
+// @Param medication body entity.MedicationRequest true "Medication"
+// @Success 201 {object} entity.Medication
+// @Failure 400 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /medication [post]
+func (h *HandlerV1) CreateMedication(c *gin.Context) {
+	var body entity.MedicationRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	medication, err := h.Service.Medication().Create(ctx, medicationFromRequest(&body))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, medication)
+}
+
+// @Security BearerAuth
+// @Summary Update a medication
+// @Description Replaces a catalogue entry. Retire a medication by setting Active to false; prescriptions already written keep it.
+// @Tags Medication
+// @Accept json
+// @Produce json
+// @Param id path int true "Medication ID"
+// @Param medication body entity.MedicationRequest true "Medication"
+// @Success 200 {object} entity.Medication
+// @Failure 400 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /medication/{id} [put]
+func (h *HandlerV1) UpdateMedication(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	var body entity.MedicationRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	medication := medicationFromRequest(&body)
+	medication.ID = id
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	medication, err = h.Service.Medication().Update(ctx, medication)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, medication)
+}
+
+// @Security BearerAuth
+// @Summary List medications
+// @Description Lists the catalogue by name. search matches names and ingredients; retired medications are left out unless all=true.
+// @Tags Medication
+// @Accept json
+// @Produce json
+// @Param search query string false "Part of the name or an ingredient"
+// @Param all query bool false "Include retired medications"
+// @Success 200 {object} entity.ListMedications
+// @Failure 500 {object} entity.Error
+// @Router /medications [get]
+func (h *HandlerV1) ListMedications(c *gin.Context) {
+	all, _ := strconv.ParseBool(c.Query("all"))
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	medications, err := h.Service.Medication().List(ctx, c.Query("search"), !all)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.ListMedications{
+		Medications: medications,
+		TotalCount:  int64(len(medications)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Import medications
+// @Description Imports a CSV catalogue. The header row names the columns name, ingredients, form and strength in any order; only name is required and ingredients are separated by semicolons. Medications of the same name, form and strength are replaced and made active again. Rows that cannot be read are reported and skipped.
+// @Tags Medication
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV catalogue"
+// @Success 200 {object} entity.MedicationImport
+// @Failure 400 {object} entity.Error
+// @Router /medications/import [post]
+func (h *HandlerV1) ImportMedications(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: errCatalogueMissing.Error()})
+		return
+	}
+	if header.Size > maxCatalogueImportSize {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "catalogue file is too large"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: errCatalogueMissing.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxCatalogueImportSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: errCatalogueMissing.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	medications, problems, err := prescription.ParseCatalogue(bytes.NewReader(data))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	created, updated, err := h.Service.Medication().Import(ctx, medications)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.MedicationImport{
+		Created: created,
+		Updated: updated,
+		Errors:  problems,
+	})
+}
+
+func medicationFromRequest(body *entity.MedicationRequest) *entity.Medication {
+	return &entity.Medication{
+		Name:        body.Name,
+		Ingredients: prescription.Ingredients(body.Ingredients),
+		Form:        body.Form,
+		Strength:    body.Strength,
+		Active:      body.Active == nil || *body.Active,
+	}
+}
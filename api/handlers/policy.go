@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// @Summary List cancellation policies
+// @Description Returns the hospital default policy followed by the appointment type specific ones
+// @Tags CancellationPolicy
+// @Accept json
+// @Produce json
+// @Success 200 {object} entity.ListCancellationPolicies
+// @Failure 500 {object} entity.Error
+// @Router /cancellation-policies [get]
+func (h *HandlerV1) ListCancellationPolicies(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	policies, err := h.Service.CancellationPolicy().List(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.ListCancellationPolicies{
+		Policies:   policies,
+		TotalCount: int64(len(policies)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Get the effective cancellation policy
+// @Description Returns the policy that applies to an appointment type, or the hospital default when the type has none or appointment_type_id is omitted
+// @Tags CancellationPolicy
+// @Accept json
+// @Produce json
+// @Param appointment_type_id query int false "Appointment type ID"
+// @Success 200 {object} entity.CancellationPolicy
+// @Failure 400 {object} entity.Error
+// @Router /cancellation-policy [get]
+func (h *HandlerV1) GetCancellationPolicy(c *gin.Context) {
+	var appointmentTypeID int64
+	if raw := c.Query("appointment_type_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid appointment type ID"})
+			h.Logger.Error(err.Error())
+			return
+		}
+		appointmentTypeID = id
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	policy, err := h.Service.CancellationPolicy().Get(ctx, appointmentTypeID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// @Security BearerAuth
+// @Summary Save a cancellation policy
+// @Description Updates the hospital default policy, or creates/replaces the policy of AppointmentTypeID when set. A negative MaxReschedules means unlimited.
+// @Tags CancellationPolicy
+// @Accept json
+// @Produce json
+// @Param policy body entity.CancellationPolicy true "Cancellation policy"
+// @Success 200 {object} entity.CancellationPolicy
+// @Failure 400 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /cancellation-policy [put]
+func (h *HandlerV1) SaveCancellationPolicy(c *gin.Context) {
+	var body entity.CancellationPolicy
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if body.CutoffMinutes < 0 {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "CutoffMinutes cannot be negative"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	policy, err := h.Service.CancellationPolicy().Upsert(ctx, &body)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// @Security BearerAuth
+// @Summary Delete an appointment type policy
+// @Description Removes the policy of an appointment type so it falls back to the hospital default
+// @Tags CancellationPolicy
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment type ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} entity.Error
+// @Router /cancellation-policy/{id} [delete]
+func (h *HandlerV1) DeleteCancellationPolicy(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	if err = h.Service.CancellationPolicy().Delete(ctx, id); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Policy not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Policy deleted successfully"})
+}
@@ -0,0 +1,404 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/prescription"
+	"github.com/gin-gonic/gin"
+)
+
+// prescriptionDocument is the printable prescription handed to the patient.
+var prescriptionDocument = template.Must(template.New("prescription").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<title>Prescription #{{ .Prescription.ID }}</title>
+<style>
+    body { font-family: sans-serif; max-width: 800px; margin: 2em auto; }
+    table { width: 100%; border-collapse: collapse; }
+    th, td { text-align: left; padding: 0.4em; border-bottom: 1px solid #ccc; }
+    .signature { margin-top: 4em; border-top: 1px solid #000; width: 40%; }
+    @media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+    <h1>Hospital</h1>
+    <h2>Prescription #{{ .Prescription.ID }}</h2>
+    <p>Date: {{ .Date }}</p>
+    <p>Patient: {{ .Patient }}</p>
+    <p>Doctor: {{ .Doctor }}</p>
+    <table>
+        <tr><th>Medication</th><th>Dosage</th><th>Frequency</th><th>Duration</th><th>Instructions</th></tr>
+        {{ range .Prescription.Items }}
+        <tr>
+            <td>{{ .Medication }} {{ .Form }} {{ .Strength }}</td>
+            <td>{{ .Dosage }}</td>
+            <td>{{ .Frequency }}</td>
+            <td>{{ .DurationDays }} days</td>
+            <td>{{ .Instructions }}</td>
+        </tr>
+        {{ end }}
+    </table>
+    {{ if .Prescription.Notes }}<p>{{ .Prescription.Notes }}</p>{{ end }}
+    <div class="signature">{{ .Doctor }}</div>
+</body>
+</html>
+`))
+
+// @Security BearerAuth
+// @Summary Write a prescription
+// @Description Prescribes medications from the catalogue as part of the appointment's encounter, which has to be written first. The prescription is checked against the patient's allergies and against interactions between the medications and with those the patient is still taking; when it raises alerts it is refused with 409 and the alerts until it is sent again with AcknowledgeAlerts. Allowed for the appointment's doctor.
+// @Tags Prescription
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param prescription body entity.PrescriptionRequest true "Prescription"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 201 {object} entity.Prescription
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.PrescriptionAlerts
+// @Router /appointment/{id}/prescription [post]
+func (h *HandlerV1) CreatePrescription(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	var body entity.PrescriptionRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, userID, ok := h.encounterAppointment(ctx, c)
+	if !ok {
+		return
+	}
+
+	encounter, err := h.Service.Encounter().GetByAppointment(ctx, appointment.ID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Encounter not found, write the encounter first"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ids := make([]int64, 0, len(body.Items))
+	for _, item := range body.Items {
+		ids = append(ids, item.MedicationID)
+	}
+	medications, err := h.Service.Medication().GetMany(ctx, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	byID := make(map[int64]*entity.Medication, len(medications))
+	for _, medication := range medications {
+		if medication.Active {
+			byID[medication.ID] = medication
+		}
+	}
+
+	prescribed := make([]*entity.Medication, 0, len(body.Items))
+	items := make([]*entity.PrescriptionItem, 0, len(body.Items))
+	for _, item := range body.Items {
+		medication, ok := byID[item.MedicationID]
+		if !ok {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: fmt.Sprintf("Medication %d is not in the catalogue", item.MedicationID)})
+			return
+		}
+		prescribed = append(prescribed, medication)
+		items = append(items, &entity.PrescriptionItem{
+			MedicationID: item.MedicationID,
+			Dosage:       item.Dosage,
+			Frequency:    item.Frequency,
+			DurationDays: item.DurationDays,
+			Instructions: item.Instructions,
+		})
+	}
+
+	alerts, err := h.prescriptionAlerts(ctx, appointment.UserID, prescribed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if len(alerts) != 0 && !body.AcknowledgeAlerts {
+		c.JSON(http.StatusConflict, entity.PrescriptionAlerts{
+			Message: "The prescription raised alerts; review them and send it again with AcknowledgeAlerts",
+			Alerts:  alerts,
+		})
+		return
+	}
+
+	created, err := h.Service.Prescription().Create(ctx, &entity.Prescription{
+		EncounterID: encounter.ID,
+		Items:       items,
+		Notes:       body.Notes,
+		Alerts:      alerts,
+		CreatedBy:   userID,
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, localizePrescription(created, loc))
+}
+
+// @Security BearerAuth
+// @Summary Prescriptions of an appointment
+// @Description Lists the prescriptions written at the appointment, latest first. Allowed for the patient and the appointment's doctor.
+// @Tags Prescription
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListPrescriptions
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment/{id}/prescriptions [get]
+func (h *HandlerV1) ListAppointmentPrescriptions(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Appointment not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if !h.canReadPrescriptions(ctx, c, appointment) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	prescriptions, err := h.Service.Prescription().List(ctx, &entity.PrescriptionFilter{AppointmentID: appointment.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, p := range prescriptions {
+		localizePrescription(p, loc)
+	}
+
+	c.JSON(http.StatusOK, entity.ListPrescriptions{
+		Prescriptions: prescriptions,
+		TotalCount:    int64(len(prescriptions)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary My prescriptions
+// @Description Lists the caller's prescriptions, latest first
+// @Tags Prescription
+// @Accept json
+// @Produce json
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListPrescriptions
+// @Failure 401 {object} entity.Error
+// @Router /me/prescriptions [get]
+func (h *HandlerV1) ListMyPrescriptions(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	userID, _, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, entity.Error{Message: "Unauthorized"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	prescriptions, err := h.Service.Prescription().List(ctx, &entity.PrescriptionFilter{PatientID: userID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, p := range prescriptions {
+		localizePrescription(p, loc)
+	}
+
+	c.JSON(http.StatusOK, entity.ListPrescriptions{
+		Prescriptions: prescriptions,
+		TotalCount:    int64(len(prescriptions)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Get a prescription
+// @Description Returns a prescription with its items and the alerts acknowledged when it was written. Allowed for the patient and the appointment's doctor.
+// @Tags Prescription
+// @Accept json
+// @Produce json
+// @Param id path int true "Prescription ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.Prescription
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /prescription/{id} [get]
+func (h *HandlerV1) GetPrescription(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	p, ok := h.readablePrescription(ctx, c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, localizePrescription(p, loc))
+}
+
+// @Security BearerAuth
+// @Summary Printable prescription
+// @Description Renders the prescription as an HTML document ready to print. With download=true it is sent as a file to save. Allowed for the patient and the appointment's doctor.
+// @Tags Prescription
+// @Produce html
+// @Param id path int true "Prescription ID"
+// @Param download query bool false "Send as a file"
+// @Param tz query string false "IANA time zone for the date, defaults to the hospital zone"
+// @Success 200 {string} string "HTML document"
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /prescription/{id}/document [get]
+func (h *HandlerV1) GetPrescriptionDocument(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	p, ok := h.readablePrescription(ctx, c)
+	if !ok {
+		return
+	}
+
+	var patientName, doctorName string
+	if patient, err := h.Service.User().Get(ctx, map[string]string{"id": p.PatientID}); err == nil {
+		patientName = patient.FullName
+	}
+	if doctor, err := h.Service.Doctor().Get(ctx, p.DoctorID); err == nil {
+		if user, err := h.Service.User().Get(ctx, map[string]string{"id": doctor.UserID}); err == nil {
+			doctorName = user.FullName
+		}
+	}
+
+	if download, _ := strconv.ParseBool(c.Query("download")); download {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="prescription-%d.html"`, p.ID))
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := prescriptionDocument.Execute(c.Writer, struct {
+		Prescription *entity.Prescription
+		Date         string
+		Patient      string
+		Doctor       string
+	}{p, p.CreatedAt.In(loc).Format("2006-01-02"), patientName, doctorName}); err != nil {
+		h.Logger.Error(err.Error())
+	}
+}
+
+// prescriptionAlerts checks medications prescribed to the patient against
+// their allergies, the medications they still take and the interaction
+// rules loaded at startup.
+func (h *HandlerV1) prescriptionAlerts(ctx context.Context, patientID string, prescribed []*entity.Medication) ([]*entity.PrescriptionAlert, error) {
+	allergies, err := h.Service.Allergy().List(ctx, patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := h.Service.Prescription().ActiveMedications(ctx, patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	return prescription.Check(prescribed, current, allergies, h.Interactions), nil
+}
+
+// readablePrescription loads the prescription of the request and checks the
+// caller may read it. It writes the error response itself.
+func (h *HandlerV1) readablePrescription(ctx context.Context, c *gin.Context) (*entity.Prescription, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return nil, false
+	}
+
+	p, err := h.Service.Prescription().Get(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Prescription not found"})
+		h.Logger.Error(err.Error())
+		return nil, false
+	}
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, int(p.AppointmentID))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Prescription not found"})
+		h.Logger.Error(err.Error())
+		return nil, false
+	}
+	if !h.canReadPrescriptions(ctx, c, appointment) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return nil, false
+	}
+
+	return p, true
+}
+
+// canReadPrescriptions reports whether the caller is the patient or the
+// doctor of the appointment.
+func (h *HandlerV1) canReadPrescriptions(ctx context.Context, c *gin.Context, appointment *entity.Appointment) bool {
+	return h.canAccessAppointment(ctx, c, appointment, entity.RoleUser) ||
+		h.canAccessAppointment(ctx, c, appointment, entity.RoleDoctor)
+}
+
+func localizePrescription(p *entity.Prescription, loc *time.Location) *entity.Prescription {
+	p.CreatedAt = p.CreatedAt.In(loc)
+	return p
+}
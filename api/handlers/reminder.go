@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/reminder"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+// reminderPage is shown for reminder links. Opening a link only asks for
+// confirmation; the action runs on the form post, so mail scanners that
+// follow links cannot cancel appointments.
+var reminderPage = template.Must(template.New("reminder").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="UTF-8"><title>Hospital</title></head>
+<body style="font-family: sans-serif; text-align: center;">
+    <p>{{ .Message }}</p>
+    {{ if .Action }}<form method="post"><button type="submit">{{ .Action }}</button></form>{{ end }}
+</body>
+</html>`))
+
+// @Summary Open a reminder link
+// @Description Landing page of the confirm and cancel links in appointment reminders. It asks the patient to confirm the action, which is then posted back to the same link.
+// @Tags AppointmentStatus
+// @Produce html
+// @Param id path int true "Appointment ID"
+// @Param action path string true "confirm or cancel"
+// @Param expires query int true "Link expiry (Unix time)"
+// @Param sig query string true "Link signature"
+// @Success 200 {string} string "HTML page"
+// @Failure 403 {string} string "HTML page"
+// @Router /reminder/{id}/{action} [get]
+func (h *HandlerV1) OpenReminderLink(c *gin.Context) {
+	id, action, ok := h.reminderLink(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, int(id))
+	if err != nil {
+		h.renderReminderPage(c, statusFromError(err), "Appointment not found.", "")
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	when := appointment.StartTime.In(h.Config.Location).Format("2006-01-02 15:04")
+	if action == reminder.ActionCancel {
+		h.renderReminderPage(c, http.StatusOK, "Cancel your appointment on "+when+"?", "Cancel appointment")
+		return
+	}
+	h.renderReminderPage(c, http.StatusOK, "Confirm your appointment on "+when+"?", "Confirm appointment")
+}
+
+// @Summary Act on a reminder link
+// @Description Confirms or cancels the appointment named by a signed reminder link. Cancellations follow the cancellation policy.
+// @Tags AppointmentStatus
+// @Produce html
+// @Param id path int true "Appointment ID"
+// @Param action path string true "confirm or cancel"
+// @Param expires query int true "Link expiry (Unix time)"
+// @Param sig query string true "Link signature"
+// @Success 200 {string} string "HTML page"
+// @Failure 403 {string} string "HTML page"
+// @Failure 409 {string} string "HTML page"
+// @Failure 422 {string} string "HTML page"
+// @Router /reminder/{id}/{action} [post]
+func (h *HandlerV1) ActOnReminderLink(c *gin.Context) {
+	id, action, ok := h.reminderLink(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, int(id))
+	if err != nil {
+		h.renderReminderPage(c, statusFromError(err), "Appointment not found.", "")
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	rule := entity.AppointmentTransitions[action]
+	if action == reminder.ActionConfirm && appointment.Status == entity.AppointmentStatusConfirmed {
+		h.renderReminderPage(c, http.StatusOK, "Your appointment is already confirmed.", "")
+		return
+	}
+	if action == reminder.ActionCancel {
+		policy, err := h.Service.CancellationPolicy().Get(ctx, appointment.AppointmentTypeID)
+		if err != nil {
+			h.renderReminderPage(c, statusFromError(err), entity.SomethingWentWrong, "")
+			h.Logger.Error(err.Error())
+			return
+		}
+
+		if rule.To, err = policy.CancelStatus(appointment, time.Now(), false); err != nil {
+			h.renderReminderPage(c, statusFromError(err), "The appointment can no longer be cancelled online, please call the hospital.", "")
+			return
+		}
+	}
+
+	updated, err := h.Service.Appointment().TransitionAppointment(ctx, int(id), rule, appointment.UserID, "via reminder link")
+	if err != nil {
+		h.renderReminderPage(c, statusFromError(err), "The appointment can no longer be changed.", "")
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if action == reminder.ActionCancel {
+		h.offerFreedSlots(c, updated.DoctorID)
+		h.renderReminderPage(c, http.StatusOK, "Your appointment has been cancelled.", "")
+		return
+	}
+	h.renderReminderPage(c, http.StatusOK, "Thank you, your appointment is confirmed.", "")
+}
+
+// reminderLink parses and verifies a reminder link. It writes the error
+// page itself.
+func (h *HandlerV1) reminderLink(c *gin.Context) (int64, string, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	action := c.Param("action")
+	if err != nil || (action != reminder.ActionConfirm && action != reminder.ActionCancel) {
+		h.renderReminderPage(c, http.StatusNotFound, "This link is not valid.", "")
+		return 0, "", false
+	}
+
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err == nil {
+		err = reminder.VerifyLink(h.Config, c.GetInt64(tenant.Key), id, action, time.Unix(expires, 0), c.Query("sig"))
+	}
+	if err != nil {
+		h.renderReminderPage(c, http.StatusForbidden, "This link is not valid or has expired.", "")
+		return 0, "", false
+	}
+
+	return id, action, true
+}
+
+func (h *HandlerV1) renderReminderPage(c *gin.Context, status int, message, action string) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	if err := reminderPage.Execute(c.Writer, struct {
+		Message string
+		Action  string
+	}{message, action}); err != nil {
+		h.Logger.Error(err.Error())
+	}
+}
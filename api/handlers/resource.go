@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// @Summary Create a resource
+// @Description Adds a room or a piece of equipment. Appointment types list the categories they need in RequiredResources; booking such a visit reserves one free, active resource of each. Hours are the weekly opening hours in the hospital zone (Weekday 0 = Sunday, HH:MM); without any the resource is always open. Active defaults to true.
+// @Tags Resource
+// @Accept json
+// @Produce json
+// @Param resource body entity.ResourceRequest true "Resource"
+// @Success 201 {object} entity.Resource
+// @Failure 400 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /resource [post]
+func (h *HandlerV1) CreateResource(c *gin.Context) {
+	var body entity.ResourceRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	resource, err := resourceFromRequest(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	resource, err = h.Service.Resource().Create(ctx, resource)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, resource)
+}
+
+// @Security BearerAuth
+// @Summary List resources
+// @Description Lists the rooms and equipment, optionally of one kind or category
+// @Tags Resource
+// @Accept json
+// @Produce json
+// @Param kind query string false "room or equipment"
+// @Param category query string false "Resource category"
+// @Success 200 {object} entity.ListResources
+// @Failure 500 {object} entity.Error
+// @Router /resources [get]
+func (h *HandlerV1) ListResources(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	resources, err := h.Service.Resource().List(ctx, c.Query("kind"), c.Query("category"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.ListResources{
+		Resources:  resources,
+		TotalCount: int64(len(resources)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Get a resource
+// @Description Returns a room or piece of equipment with its opening hours
+// @Tags Resource
+// @Accept json
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Success 200 {object} entity.Resource
+// @Failure 404 {object} entity.Error
+// @Router /resource/{id} [get]
+func (h *HandlerV1) GetResource(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	resource, err := h.Service.Resource().Get(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Resource not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, resource)
+}
+
+// @Security BearerAuth
+// @Summary Update a resource
+// @Description Changes a resource and replaces its opening hours. Deactivating a resource keeps its existing bookings but takes it out of new ones.
+// @Tags Resource
+// @Accept json
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Param resource body entity.ResourceRequest true "Resource"
+// @Success 200 {object} entity.Resource
+// @Failure 400 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /resource/{id} [put]
+func (h *HandlerV1) UpdateResource(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	var body entity.ResourceRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	resource, err := resourceFromRequest(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		return
+	}
+	resource.ID = id
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	resource, err = h.Service.Resource().Update(ctx, resource)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, resource)
+}
+
+// @Security BearerAuth
+// @Summary Delete a resource
+// @Description Removes a resource. Resources with upcoming bookings cannot be deleted; deactivate them instead.
+// @Tags Resource
+// @Accept json
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /resource/{id} [delete]
+func (h *HandlerV1) DeleteResource(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	if err = h.Service.Resource().Delete(ctx, id); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Resource deleted"})
+}
+
+// @Security BearerAuth
+// @Summary Resource schedule
+// @Description Lists the bookings of a room or piece of equipment overlapping from..to (RFC 3339), by default the coming week. Booked times include the appointment type's buffer.
+// @Tags Resource
+// @Accept json
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Param from query string false "Start of the range (RFC 3339)"
+// @Param to query string false "End of the range (RFC 3339)"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListResourceBookings
+// @Failure 400 {object} entity.Error
+// @Router /resource/{id}/bookings [get]
+func (h *HandlerV1) ListResourceBookings(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	from, to := time.Now(), time.Now().AddDate(0, 0, 7)
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid from, expected RFC 3339"})
+			return
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid to, expected RFC 3339"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	bookings, err := h.Service.Resource().Bookings(ctx, id, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.ListResourceBookings{
+		Bookings:   localizeResourceBookings(bookings, loc),
+		TotalCount: int64(len(bookings)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Resources of an appointment
+// @Description Lists the rooms and equipment reserved for the appointment
+// @Tags Resource
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListResourceBookings
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment/{id}/resources [get]
+func (h *HandlerV1) GetAppointmentResources(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	appointment, err := h.Service.Appointment().GetAppointment(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Appointment not found"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	allowed := false
+	for _, role := range []string{entity.RoleAdmin, entity.RoleReceptionist, entity.RoleDoctor, entity.RoleUser} {
+		if h.canAccessAppointment(ctx, c, appointment, role) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return
+	}
+
+	bookings, err := h.Service.Resource().AppointmentBookings(ctx, appointment.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.ListResourceBookings{
+		Bookings:   localizeResourceBookings(bookings, loc),
+		TotalCount: int64(len(bookings)),
+	})
+}
+
+// resourceFromRequest validates the request.
+func resourceFromRequest(body *entity.ResourceRequest) (*entity.Resource, error) {
+	if body.Name == "" || body.Category == "" {
+		return nil, errors.New("Name and Category are required")
+	}
+	if body.Kind != entity.ResourceKindRoom && body.Kind != entity.ResourceKindEquipment {
+		return nil, errors.New("Kind must be room or equipment")
+	}
+
+	if err := validOpeningHours(body.Hours); err != nil {
+		return nil, err
+	}
+
+	resource := &entity.Resource{
+		Name:        body.Name,
+		Kind:        body.Kind,
+		Category:    body.Category,
+		Description: body.Description,
+		Active:      body.Active == nil || *body.Active,
+		Hours:       body.Hours,
+	}
+	if resource.Hours == nil {
+		resource.Hours = []*entity.OpeningHours{}
+	}
+
+	return resource, nil
+}
+
+func localizeResourceBookings(bookings []*entity.ResourceBooking, loc *time.Location) []*entity.ResourceBooking {
+	for _, booking := range bookings {
+		booking.StartTime = booking.StartTime.In(loc)
+		booking.EndTime = booking.EndTime.In(loc)
+	}
+	return bookings
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/schedule"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// @Summary Save weekly schedule
+// @Description Creates or replaces a doctor's weekly schedule template and immediately generates availability for the configured number of weeks ahead. Doctors may only edit their own schedule.
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param schedule body entity.Schedule true "Weekly schedule"
+// @Success 200 {object} entity.Schedule
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /schedule [put]
+func (h *HandlerV1) SaveSchedule(c *gin.Context) {
+	var body entity.Schedule
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, body.DoctorID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+	body.DoctorID = doctorID
+
+	if err = schedule.Validate(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		return
+	}
+
+	saved, err := h.Service.Schedule().Upsert(ctx, &body)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Failed to save schedule"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	generator := schedule.NewGenerator(h.Service, h.Logger, h.Config.Schedule.WeeksAhead, h.Config.Location)
+	if _, err = generator.Generate(ctx, saved); err != nil {
+		h.Logger.Error(err.Error())
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+// @Security BearerAuth
+// @Summary Get weekly schedule
+// @Description Returns the weekly schedule template of a doctor
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param id path string true "Doctor ID"
+// @Success 200 {object} entity.Schedule
+// @Failure 404 {object} entity.Error
+// @Router /schedule/{id} [get]
+func (h *HandlerV1) GetSchedule(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	saved, err := h.Service.Schedule().Get(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.ObjectNotFount})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+// @Security BearerAuth
+// @Summary Delete weekly schedule
+// @Description Stops generating availability for a doctor. Slots that were already generated are kept.
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param id path string true "Doctor ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /schedule/{id} [delete]
+func (h *HandlerV1) DeleteSchedule(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err = h.Service.Schedule().Delete(ctx, doctorID); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.ObjectNotFount})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule deleted successfully"})
+}
+
+// @Security BearerAuth
+// @Summary Generate availability from schedule
+// @Description Tops up the doctor's availability from the weekly schedule right away instead of waiting for the background generator
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param id path string true "Doctor ID"
+// @Success 200 {object} entity.ScheduleGenerateResponse
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /schedule/{id}/generate [post]
+func (h *HandlerV1) GenerateSchedule(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	doctorID, err := h.doctorScope(ctx, c, c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	saved, err := h.Service.Schedule().Get(ctx, doctorID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.ObjectNotFount})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	generator := schedule.NewGenerator(h.Service, h.Logger, h.Config.Schedule.WeeksAhead, h.Config.Location)
+	created, err := generator.Generate(ctx, saved)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.ScheduleGenerateResponse{Created: created})
+}
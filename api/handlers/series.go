@@ -0,0 +1,459 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/gin-gonic/gin"
+)
+
+var errSeriesTooLong = errors.New("a series can book at most " + strconv.Itoa(entity.MaxSeriesOccurrences) + " visits")
+
+// @Security BearerAuth
+// @Summary Book a recurring appointment series
+// @Description Books the same visit every week or every other week (IntervalWeeks 1 or 2), starting at StartTime, for Occurrences visits or until the Until date. In all_or_nothing mode (the default) nothing is booked unless every visit is available; in best_effort mode the available visits are booked and the others reported. The result lists every occurrence; on 409 it tells which ones are not available. Receptionists and admins may book for another patient via UserID, everybody else books for themselves.
+// @Tags AppointmentSeries
+// @Accept json
+// @Produce json
+// @Param series body entity.AppointmentSeriesRequest true "Series"
+// @Param tz query string false "IANA time zone of Until, the weekly wall-clock time and the returned times, defaults to the hospital zone"
+// @Param Idempotency-Key header string false "Retries with the same key and payload replay the first response"
+// @Success 201 {object} entity.AppointmentSeriesResult
+// @Failure 400 {object} entity.Error
+// @Failure 409 {object} entity.AppointmentSeriesResult
+// @Router /appointment-series [post]
+func (h *HandlerV1) CreateAppointmentSeries(c *gin.Context) {
+	var body entity.AppointmentSeriesRequest
+
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	userID, role, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, entity.Error{Message: err.Error()})
+		return
+	}
+	if body.UserID == "" || (role != entity.RoleReceptionist && role != entity.RoleAdmin) {
+		body.UserID = userID
+	}
+
+	series, starts, err := seriesFromRequest(&body, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	result, err := h.Service.Appointment().CreateSeries(ctx, series, starts)
+	if err != nil && !errors.Is(err, entity.ErrorSeriesUnavailable) {
+		c.JSON(statusFromError(err), entity.Error{Message: "Failed to book the series: " + err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+	localizeSeriesResult(result, loc)
+
+	if err != nil {
+		c.JSON(http.StatusConflict, result)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// @Security BearerAuth
+// @Summary Get an appointment series
+// @Description Returns the series with all its appointments, earliest first.
+// @Tags AppointmentSeries
+// @Accept json
+// @Produce json
+// @Param id path int true "Series ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.AppointmentSeries
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment-series/{id} [get]
+func (h *HandlerV1) GetAppointmentSeries(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	series, ok := h.accessibleSeries(ctx, c, entity.RoleUser, entity.RoleDoctor, entity.RoleReceptionist)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, localizeSeries(series, loc))
+}
+
+// @Security BearerAuth
+// @Summary Reschedule the rest of a series
+// @Description Moves the visit FromAppointmentID to StartTime and every later live visit of the series by the same number of days, to the same new time of day in the tz zone. Mode defaults to the series' booking mode: all_or_nothing moves nothing unless every visit can be moved, best_effort moves what it can. Each visit is subject to the cancellation policy. To move a single visit use PUT /appointment/{id}.
+// @Tags AppointmentSeries
+// @Accept json
+// @Produce json
+// @Param id path int true "Series ID"
+// @Param reschedule body entity.SeriesRescheduleRequest true "New time of the first visit to move"
+// @Param override query bool false "Admin override of the policy, if the policy allows it"
+// @Param tz query string false "IANA time zone of the weekly wall-clock time and the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.AppointmentSeriesResult
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.AppointmentSeriesResult
+// @Router /appointment-series/{id}/reschedule [post]
+func (h *HandlerV1) RescheduleAppointmentSeries(c *gin.Context) {
+	var body entity.SeriesRescheduleRequest
+
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	newStart, err := time.Parse(time.RFC3339, body.StartTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "StartTime must be RFC 3339 with an offset"})
+		return
+	}
+	if body.Mode != "" && !validSeriesMode(body.Mode) {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Mode must be all_or_nothing or best_effort"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	series, ok := h.accessibleSeries(ctx, c, entity.RoleUser, entity.RoleReceptionist)
+	if !ok {
+		return
+	}
+	if body.Mode == "" {
+		body.Mode = series.Mode
+	}
+
+	from := seriesAppointment(series, body.FromAppointmentID)
+	if from == nil {
+		c.JSON(http.StatusNotFound, entity.Error{Message: "Appointment is not part of the series"})
+		return
+	}
+
+	policy, err := h.Service.CancellationPolicy().Get(ctx, series.AppointmentTypeID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	_, role, _ := h.caller(c)
+
+	// the shift is counted in calendar days so the visits keep their wall
+	// clock time across daylight saving changes
+	newStart = newStart.In(loc)
+	days := int(civilDate(newStart).Sub(civilDate(from.StartTime.In(loc))) / (24 * time.Hour))
+
+	result := &entity.AppointmentSeriesResult{Series: series}
+	var moves []*entity.SeriesOccurrence
+	for _, appointment := range series.Appointments {
+		if appointment.StartTime.Before(from.StartTime) || !liveAppointment(appointment) {
+			continue
+		}
+
+		day := appointment.StartTime.In(loc).AddDate(0, 0, days)
+		occurrence := &entity.SeriesOccurrence{
+			StartTime: time.Date(day.Year(), day.Month(), day.Day(),
+				newStart.Hour(), newStart.Minute(), newStart.Second(), 0, loc),
+			Appointment: appointment,
+		}
+		result.Occurrences = append(result.Occurrences, occurrence)
+
+		if err := policy.CheckReschedule(appointment, time.Now(), h.policyOverride(c, role)); err != nil {
+			occurrence.Error = err.Error()
+			result.Failed++
+			continue
+		}
+		moves = append(moves, occurrence)
+	}
+
+	err = entity.ErrorSeriesUnavailable
+	if len(moves) > 0 && (result.Failed == 0 || body.Mode == entity.SeriesModeBestEffort) {
+		err = h.Service.Appointment().RescheduleSeries(ctx, moves, body.Mode == entity.SeriesModeAllOrNothing)
+	}
+	if err != nil && !errors.Is(err, entity.ErrorSeriesUnavailable) {
+		c.JSON(statusFromError(err), entity.Error{Message: "Failed to reschedule the series: " + err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	for _, occurrence := range moves {
+		if occurrence.Error != "" {
+			result.Failed++
+		} else if err == nil {
+			result.Succeeded++
+		}
+	}
+	series.Appointments = nil
+	localizeSeriesResult(result, loc)
+
+	if err != nil {
+		c.JSON(http.StatusConflict, result)
+		return
+	}
+	h.offerFreedSlots(c, series.DoctorID)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Security BearerAuth
+// @Summary Cancel the rest of a series
+// @Description Cancels the visit FromAppointmentID and every later live visit of the series, or every upcoming visit when FromAppointmentID is omitted. Each visit is cancelled under the cancellation policy; visits it does not allow to cancel are reported and left booked. To cancel a single visit use POST /appointment/{id}/cancel.
+// @Tags AppointmentSeries
+// @Accept json
+// @Produce json
+// @Param id path int true "Series ID"
+// @Param cancel body entity.SeriesCancelRequest false "Where to start"
+// @Param override query bool false "Admin override of the policy, if the policy allows it"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.AppointmentSeriesResult
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Router /appointment-series/{id}/cancel [post]
+func (h *HandlerV1) CancelAppointmentSeries(c *gin.Context) {
+	var body entity.SeriesCancelRequest
+
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+			h.Logger.Error(err.Error())
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	rule := entity.AppointmentTransitions["cancel"]
+	series, ok := h.accessibleSeries(ctx, c, rule.Roles...)
+	if !ok {
+		return
+	}
+
+	from := time.Now()
+	if body.FromAppointmentID != 0 {
+		appointment := seriesAppointment(series, body.FromAppointmentID)
+		if appointment == nil {
+			c.JSON(http.StatusNotFound, entity.Error{Message: "Appointment is not part of the series"})
+			return
+		}
+		from = appointment.StartTime
+	}
+
+	policy, err := h.Service.CancellationPolicy().Get(ctx, series.AppointmentTypeID)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	userID, role, _ := h.caller(c)
+
+	result := &entity.AppointmentSeriesResult{Series: series}
+	for _, appointment := range series.Appointments {
+		if appointment.StartTime.Before(from) || !liveAppointment(appointment) {
+			continue
+		}
+
+		occurrence := &entity.SeriesOccurrence{StartTime: appointment.StartTime, Appointment: appointment}
+		result.Occurrences = append(result.Occurrences, occurrence)
+
+		rule.To, err = policy.CancelStatus(appointment, time.Now(), h.policyOverride(c, role))
+		if err == nil {
+			occurrence.Appointment, err = h.Service.Appointment().TransitionAppointment(ctx, int(appointment.ID), rule, userID, body.Reason)
+		}
+		if err != nil {
+			occurrence.Appointment = appointment
+			occurrence.Error = err.Error()
+			result.Failed++
+			continue
+		}
+		result.Succeeded++
+	}
+	if result.Succeeded > 0 {
+		h.offerFreedSlots(c, series.DoctorID)
+	}
+	series.Appointments = nil
+
+	c.JSON(http.StatusOK, localizeSeriesResult(result, loc))
+}
+
+// accessibleSeries loads the series named by the id path parameter and
+// checks the caller may act on it in one of roles, or as admin. It writes
+// the error response itself.
+func (h *HandlerV1) accessibleSeries(ctx context.Context, c *gin.Context, roles ...string) (*entity.AppointmentSeries, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		return nil, false
+	}
+
+	series, err := h.Service.Appointment().GetSeries(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Series not found"})
+		h.Logger.Error(err.Error())
+		return nil, false
+	}
+
+	owner := &entity.Appointment{DoctorID: series.DoctorID, UserID: series.UserID}
+	for _, role := range append([]string{entity.RoleAdmin}, roles...) {
+		if h.canAccessAppointment(ctx, c, owner, role) {
+			return series, true
+		}
+	}
+
+	c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+	return nil, false
+}
+
+// seriesFromRequest validates the request and lists the start of every
+// visit. Visits keep their wall clock time in loc across daylight saving
+// changes.
+func seriesFromRequest(body *entity.AppointmentSeriesRequest, loc *time.Location) (*entity.AppointmentSeries, []time.Time, error) {
+	if body.IntervalWeeks == 0 {
+		body.IntervalWeeks = 1
+	}
+	if body.IntervalWeeks != 1 && body.IntervalWeeks != 2 {
+		return nil, nil, errors.New("IntervalWeeks must be 1 or 2")
+	}
+	if body.Mode == "" {
+		body.Mode = entity.SeriesModeAllOrNothing
+	}
+	if !validSeriesMode(body.Mode) {
+		return nil, nil, errors.New("Mode must be all_or_nothing or best_effort")
+	}
+
+	start, err := time.Parse(time.RFC3339, body.StartTime)
+	if err != nil {
+		return nil, nil, errors.New("StartTime must be RFC 3339 with an offset")
+	}
+	start = start.In(loc)
+
+	var until time.Time
+	switch {
+	case body.Occurrences != 0 && body.Until != "":
+		return nil, nil, errors.New("give either Occurrences or Until, not both")
+	case body.Occurrences > entity.MaxSeriesOccurrences:
+		return nil, nil, errSeriesTooLong
+	case body.Occurrences > 0:
+	case body.Until != "":
+		if until, err = time.ParseInLocation("2006-01-02", body.Until, loc); err != nil || body.Until < start.Format("2006-01-02") {
+			return nil, nil, errors.New("Until must be YYYY-MM-DD, not before the first visit")
+		}
+	default:
+		return nil, nil, errors.New("Occurrences or Until is required")
+	}
+
+	var starts []time.Time
+	for i := 0; ; i++ {
+		next := start.AddDate(0, 0, 7*body.IntervalWeeks*i)
+		if body.Occurrences > 0 && i == body.Occurrences {
+			break
+		}
+		if body.Until != "" && !next.Before(until.AddDate(0, 0, 1)) {
+			break
+		}
+		if i == entity.MaxSeriesOccurrences {
+			return nil, nil, errSeriesTooLong
+		}
+		starts = append(starts, next)
+	}
+
+	return &entity.AppointmentSeries{
+		DoctorID:          body.DoctorID,
+		UserID:            body.UserID,
+		AppointmentTypeID: body.AppointmentTypeID,
+		StartTime:         start,
+		IntervalWeeks:     body.IntervalWeeks,
+		Occurrences:       body.Occurrences,
+		Until:             body.Until,
+		Mode:              body.Mode,
+	}, starts, nil
+}
+
+func validSeriesMode(mode string) bool {
+	return mode == entity.SeriesModeAllOrNothing || mode == entity.SeriesModeBestEffort
+}
+
+// seriesAppointment returns the series' appointment with the given ID.
+func seriesAppointment(series *entity.AppointmentSeries, id int64) *entity.Appointment {
+	for _, appointment := range series.Appointments {
+		if appointment.ID == id {
+			return appointment
+		}
+	}
+	return nil
+}
+
+// liveAppointment reports whether the appointment still holds its slots
+// and can be moved or cancelled.
+func liveAppointment(appointment *entity.Appointment) bool {
+	return appointment.Status == entity.AppointmentStatusScheduled ||
+		appointment.Status == entity.AppointmentStatusConfirmed
+}
+
+// civilDate is t's calendar date as midnight UTC, so whole days between
+// dates can be counted without daylight saving getting in the way.
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func localizeSeries(series *entity.AppointmentSeries, loc *time.Location) *entity.AppointmentSeries {
+	series.StartTime = series.StartTime.In(loc)
+	series.CreatedAt = series.CreatedAt.In(loc)
+	for _, appointment := range series.Appointments {
+		localizeAppointment(appointment, loc)
+	}
+	return series
+}
+
+func localizeSeriesResult(result *entity.AppointmentSeriesResult, loc *time.Location) *entity.AppointmentSeriesResult {
+	localizeSeries(result.Series, loc)
+	for _, occurrence := range result.Occurrences {
+		occurrence.StartTime = occurrence.StartTime.In(loc)
+		if occurrence.Appointment != nil {
+			localizeAppointment(occurrence.Appointment, loc)
+		}
+	}
+	return result
+}
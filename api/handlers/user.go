@@ -32,7 +32,7 @@ func (h *HandlerV1) CreateUser(c *gin.Context) {
 		body entity.UserRegister
 	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	err := c.ShouldBindJSON(&body)
@@ -138,7 +138,7 @@ func (h *HandlerV1) UpdateUser(c *gin.Context) {
 		body entity.UserUpdate
 	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	err := c.ShouldBindJSON(&body)
@@ -238,7 +238,7 @@ func (h *HandlerV1) UpdateUser(c *gin.Context) {
 // @Failure 		500 {object} entity.Error
 // @Router 			/user/{id} [DELETE]
 func (h *HandlerV1) DeleteUser(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	userID := c.Param("id")
@@ -289,7 +289,7 @@ func (h *HandlerV1) DeleteUser(c *gin.Context) {
 // @Router 			/user/{id} [GET]
 func (h *HandlerV1) GetUser(c *gin.Context) {
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	userID := c.Param("id")
@@ -338,7 +338,7 @@ func (h *HandlerV1) GetUser(c *gin.Context) {
 // @Failure 		500 {object} entity.Error
 // @Router 			/users [GET]
 func (h *HandlerV1) ListUsers(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 	page := c.Query("page")
 	limit := c.Query("limit")
@@ -413,7 +413,7 @@ func (h *HandlerV1) UpdatePassword(c *gin.Context) {
 		body entity.UpdatePassword
 	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.Config.Context.Timeout)
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
 	defer cancel()
 
 	err := c.ShouldBindJSON(&body)
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/waitlist"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// @Summary Join a doctor's waitlist
+// @Description Puts the patient on the waitlist of a doctor for visits between FromTime and ToTime (RFC 3339). With BranchID only slots at that branch are offered. When a matching slot is or becomes free the patient is mailed an offer and the slot is held for them for a limited time. Receptionists and admins may enlist another patient via PatientID.
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param entry body entity.WaitlistEntry true "Waitlist entry"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Param Idempotency-Key header string false "Retries with the same key and payload replay the first response"
+// @Success 201 {object} entity.WaitlistEntry
+// @Failure 400 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 422 {object} entity.Error
+// @Router /waitlist [post]
+func (h *HandlerV1) JoinWaitlist(c *gin.Context) {
+	var body entity.WaitlistEntry
+
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if body.DoctorID == "" || !body.ToTime.After(body.FromTime) || !body.ToTime.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "DoctorID and a future FromTime/ToTime range are required"})
+		return
+	}
+
+	userID, role, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, entity.Error{Message: err.Error()})
+		return
+	}
+	if body.PatientID == "" || (role != entity.RoleReceptionist && role != entity.RoleAdmin) {
+		body.PatientID = userID
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	entry, err := h.Service.Waitlist().Create(ctx, &body)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	// a slot may already be free, in which case the offer goes out now
+	h.offerFreedSlots(c, entry.DoctorID)
+
+	c.JSON(http.StatusCreated, localizeWaitlistEntry(entry, loc))
+}
+
+// @Security BearerAuth
+// @Summary List waitlist entries
+// @Description Patients see their own entries. Receptionists and admins may filter by doctor_id, patient_id and status.
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param doctor_id query string false "Doctor ID"
+// @Param patient_id query string false "Patient ID"
+// @Param status query string false "waiting, offered, accepted, expired or cancelled"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Success 200 {object} entity.ListWaitlistEntries
+// @Failure 400 {object} entity.Error
+// @Router /waitlist [get]
+func (h *HandlerV1) ListWaitlist(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	userID, role, err := h.caller(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, entity.Error{Message: err.Error()})
+		return
+	}
+
+	params := map[string]string{}
+	for _, key := range []string{"doctor_id", "patient_id", "status"} {
+		if value := c.Query(key); value != "" {
+			params[key] = value
+		}
+	}
+	if role != entity.RoleReceptionist && role != entity.RoleAdmin {
+		params["patient_id"] = userID
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	entries, err := h.Service.Waitlist().List(ctx, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, entity.Error{Message: entity.SomethingWentWrong})
+		h.Logger.Error(err.Error())
+		return
+	}
+	for _, entry := range entries {
+		localizeWaitlistEntry(entry, loc)
+	}
+
+	c.JSON(http.StatusOK, entity.ListWaitlistEntries{
+		Entries:    entries,
+		TotalCount: int64(len(entries)),
+	})
+}
+
+// @Security BearerAuth
+// @Summary Accept a waitlist offer
+// @Description Books the held slot for the patient. Fails with 409 once the offer has expired or was withdrawn.
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param id path int true "Waitlist entry ID"
+// @Param tz query string false "IANA time zone for the returned times, defaults to the hospital zone"
+// @Param Idempotency-Key header string false "Retries with the same key and payload replay the first response"
+// @Success 201 {object} entity.Appointment
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Failure 422 {object} entity.Error
+// @Router /waitlist/{id}/accept [post]
+func (h *HandlerV1) AcceptWaitlistOffer(c *gin.Context) {
+	loc, err := h.requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid time zone"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	entry, ok := h.ownWaitlistEntry(ctx, c, id)
+	if !ok {
+		return
+	}
+	if entry.Status != entity.WaitlistStatusOffered || entry.OfferedStartTime == nil || !entry.OfferExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusConflict, entity.Error{Message: entity.ErrorOfferClosed.Error()})
+		return
+	}
+
+	appointment, err := h.Service.Waitlist().Accept(ctx, entry.ID, &entity.Appointment{
+		DoctorID:          entry.DoctorID,
+		UserID:            entry.PatientID,
+		AppointmentTypeID: entry.AppointmentTypeID,
+	}, *entry.OfferedStartTime)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Failed to create appointment: " + err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, localizeAppointment(appointment, loc))
+}
+
+// @Security BearerAuth
+// @Summary Leave the waitlist
+// @Description Takes the entry off the waitlist. An open offer is declined and passed on to the next patient.
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param id path int true "Waitlist entry ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Router /waitlist/{id} [delete]
+func (h *HandlerV1) LeaveWaitlist(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.Error{Message: "Invalid ID format"})
+		h.Logger.Error(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.tenantContext(c), h.Config.Context.Timeout)
+	defer cancel()
+
+	entry, ok := h.ownWaitlistEntry(ctx, c, id)
+	if !ok {
+		return
+	}
+
+	if err = h.Service.Waitlist().Cancel(ctx, id); err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: err.Error()})
+		h.Logger.Error(err.Error())
+		return
+	}
+	if entry.Status == entity.WaitlistStatusOffered {
+		h.offerFreedSlots(c, entry.DoctorID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left the waitlist"})
+}
+
+// ownWaitlistEntry loads the entry and checks it belongs to the caller,
+// or that the caller is staff. It writes the error response itself.
+func (h *HandlerV1) ownWaitlistEntry(ctx context.Context, c *gin.Context, id int64) (*entity.WaitlistEntry, bool) {
+	entry, err := h.Service.Waitlist().Get(ctx, id)
+	if err != nil {
+		c.JSON(statusFromError(err), entity.Error{Message: "Waitlist entry not found"})
+		h.Logger.Error(err.Error())
+		return nil, false
+	}
+
+	userID, role, err := h.caller(c)
+	if err != nil || (entry.PatientID != userID && role != entity.RoleReceptionist && role != entity.RoleAdmin) {
+		c.JSON(http.StatusForbidden, entity.Error{Message: entity.ErrorForbidden.Error()})
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// offerFreedSlots passes the doctor's free slots, e.g. those released by a
+// cancellation or reschedule, on to the waitlist in the background, so
+// mailing the offers never holds up the response.
+func (h *HandlerV1) offerFreedSlots(c *gin.Context, doctorID string) {
+	base := h.tenantContext(c)
+	go func() {
+		ctx, cancel := context.WithTimeout(base, h.Config.Context.Timeout)
+		defer cancel()
+
+		if err := waitlist.NewDispatcher(h.Service, h.Logger, h.Config).Offer(ctx, doctorID); err != nil {
+			h.Logger.Error(err.Error())
+		}
+	}()
+}
+
+func localizeWaitlistEntry(entry *entity.WaitlistEntry, loc *time.Location) *entity.WaitlistEntry {
+	entry.FromTime = entry.FromTime.In(loc)
+	entry.ToTime = entry.ToTime.In(loc)
+	entry.CreatedAt = entry.CreatedAt.In(loc)
+	if entry.OfferedStartTime != nil {
+		start := entry.OfferedStartTime.In(loc)
+		entry.OfferedStartTime = &start
+	}
+	if entry.OfferExpiresAt != nil {
+		expires := entry.OfferExpiresAt.In(loc)
+		entry.OfferExpiresAt = &expires
+	}
+
+	return entry
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/config"
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	redis "github.com/Abdulazizxoshimov/Hospital/internal/repo/redisdb"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/logger"
+	tokens "github.com/Abdulazizxoshimov/Hospital/pkg/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyHeader = "Idempotency-Key"
+	idempotencyPrefix = "idempotency:"
+	// idempotencyGrace is how long the placeholder outlives the handler's
+	// context timeout, so it cannot expire while the first request runs
+	idempotencyGrace = time.Minute
+	// idempotencyStoreTimeout bounds the Redis writes, which run detached
+	// from the request so a client hanging up cannot cancel them
+	idempotencyStoreTimeout = 5 * time.Second
+)
+
+// idempotentResponse is what is kept in Redis under an idempotency key.
+// While the first request is running only the fingerprint is stored.
+type idempotentResponse struct {
+	Fingerprint string
+	Done        bool
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// responseRecorder keeps a copy of everything the handler writes.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes a route safe to retry. A request carrying an
+// Idempotency-Key header runs once; retries with the same key and payload
+// get the first response replayed, and reusing the key for a different
+// payload is rejected with 422. Keys are scoped to the caller and route.
+// Server errors are not stored so the client can retry them.
+func Idempotency(cache redis.Cache, cfg config.Config, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, entity.Error{Message: "Invalid request data"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		userID, _ := tokens.GetIdFromToken(c.Request, &cfg)
+		storeKey := idempotencyPrefix + userID + ":" + c.Request.Method + " " + c.Request.URL.Path + ":" + key
+
+		sum := sha256.Sum256(append([]byte(c.Request.URL.RawQuery+"\n"), body...))
+		fingerprint := hex.EncodeToString(sum[:])
+
+		ctx, cancel := context.WithTimeout(context.Background(), idempotencyStoreTimeout)
+		claimed, err := cache.SetNX(ctx, storeKey, idempotentResponse{Fingerprint: fingerprint}, cfg.Context.Timeout+idempotencyGrace)
+		cancel()
+		if err != nil {
+			log.Error(err.Error())
+			// without Redis the request runs unprotected rather than failing
+			c.Next()
+			return
+		}
+
+		if !claimed {
+			ctx, cancel := context.WithTimeout(context.Background(), idempotencyStoreTimeout)
+			defer cancel()
+
+			var stored idempotentResponse
+			data, err := cache.Get(ctx, storeKey)
+			if err == nil {
+				err = json.Unmarshal(data, &stored)
+			}
+
+			switch {
+			case err != nil:
+				c.AbortWithStatusJSON(http.StatusConflict, entity.Error{Message: "A request with this Idempotency-Key is being processed"})
+			case stored.Fingerprint != fingerprint:
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, entity.Error{Message: "Idempotency-Key was already used with a different request"})
+			case !stored.Done:
+				c.AbortWithStatusJSON(http.StatusConflict, entity.Error{Message: "A request with this Idempotency-Key is being processed"})
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(stored.Status, stored.ContentType, stored.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// the booking is done by now even if the client hung up, so the
+		// response is stored on a context of its own
+		ctx, cancel = context.WithTimeout(context.Background(), idempotencyStoreTimeout)
+		defer cancel()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			if err = cache.Del(ctx, storeKey); err != nil {
+				log.Error(err.Error())
+			}
+			return
+		}
+
+		if err = cache.Set(ctx, storeKey, idempotentResponse{
+			Fingerprint: fingerprint,
+			Done:        true,
+			Status:      status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}, cfg.Idempotency.TTL); err != nil {
+			log.Error(err.Error())
+		}
+	}
+}
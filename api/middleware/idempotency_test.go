@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/config"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...zapcore.Field)        {}
+func (nopLogger) Info(string, ...zapcore.Field)         {}
+func (nopLogger) Warn(string, ...zapcore.Field)         {}
+func (nopLogger) Error(string, ...zapcore.Field)        {}
+func (nopLogger) Fatal(string, ...zapcore.Field)        {}
+func (l nopLogger) With(...zapcore.Field) logger.Logger { return l }
+func (nopLogger) Sync() error                           { return nil }
+
+// memoryCache fails writes on a cancelled context, as Redis does.
+type memoryCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (m *memoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = data
+	return nil
+}
+
+func (m *memoryCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	m.mu.Lock()
+	_, ok := m.data[key]
+	m.mu.Unlock()
+	if ok {
+		return false, ctx.Err()
+	}
+
+	return true, m.Set(ctx, key, value, expiration)
+}
+
+func (m *memoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.data[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func (m *memoryCache) Del(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+// TestIdempotencyClientGone stores the response of a request whose client
+// hung up while it ran, so the retry replays it instead of booking again.
+func TestIdempotencyClientGone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var (
+		cache  = &memoryCache{data: map[string][]byte{}}
+		booked int
+	)
+	router := gin.New()
+	router.POST("/appointment", Idempotency(cache, config.Config{}, nopLogger{}), func(c *gin.Context) {
+		booked++
+		c.Request.Context().Value(cancelKey{}).(context.CancelFunc)()
+		c.JSON(http.StatusCreated, gin.H{"id": booked})
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req := httptest.NewRequest(http.MethodPost, "/appointment", strings.NewReader(`{"DoctorID":"doctor"}`))
+		req = req.WithContext(context.WithValue(ctx, cancelKey{}, cancel))
+		req.Header.Set(IdempotencyHeader, "key")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send(); rec.Code != http.StatusCreated {
+		t.Fatalf("first request got %d, want %d", rec.Code, http.StatusCreated)
+	}
+	rec := send()
+	if rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry got %d replayed=%q, want the stored 201", rec.Code, rec.Header().Get("Idempotent-Replayed"))
+	}
+	if booked != 1 {
+		t.Fatalf("booked %d times, want 1", booked)
+	}
+}
+
+// cancelKey carries the request's cancel func to the test handler, which
+// uses it to hang up before the response is stored.
+type cancelKey struct{}
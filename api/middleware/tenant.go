@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Abdulazizxoshimov/Hospital/config"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/tenant"
+	tokens "github.com/Abdulazizxoshimov/Hospital/pkg/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tenant resolves the hospital a request is for and stores it under
+// tenant.Key. A token only works at the tenant it was issued for, so
+// signed in users, admins included, never reach another hospital; tokens
+// without a tenant_id claim, or that fail to verify, are pinned to the
+// configured default. Only requests without a token name their tenant
+// with the X-Tenant-ID header or the tenant parameter, and otherwise go to
+// the default too.
+func Tenant(cfg config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requested := c.GetHeader(tenant.Header)
+		if requested == "" {
+			requested = c.Query(tenant.Query)
+		}
+
+		var id int64
+		if requested != "" {
+			parsed, err := strconv.ParseInt(requested, 10, 64)
+			if err != nil || parsed <= 0 {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"error":   "Bad Request",
+					"message": "Invalid tenant",
+				})
+				return
+			}
+			id = parsed
+		}
+
+		if claimed, ok := tokens.GetTenantFromToken(c.Request, &cfg); ok {
+			if claimed == 0 {
+				claimed = cfg.Tenant.Default
+			}
+			if id != 0 && id != claimed {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error":   "Forbidden",
+					"message": "Token belongs to another tenant",
+				})
+				return
+			}
+			id = claimed
+		}
+
+		if id == 0 {
+			id = cfg.Tenant.Default
+		}
+		if id == 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": "Tenant is required",
+			})
+			return
+		}
+
+		c.Set(tenant.Key, id)
+	}
+}
@@ -7,9 +7,12 @@ import (
 	"github.com/Abdulazizxoshimov/Hospital/api/handlers"
 	"github.com/Abdulazizxoshimov/Hospital/api/middleware"
 	"github.com/Abdulazizxoshimov/Hospital/config"
+	"github.com/Abdulazizxoshimov/Hospital/internal/prescription"
 	"github.com/Abdulazizxoshimov/Hospital/internal/repo"
 	redis "github.com/Abdulazizxoshimov/Hospital/internal/repo/redisdb"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/filestore"
 	"github.com/Abdulazizxoshimov/Hospital/pkg/logger"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/tenant"
 	"github.com/Abdulazizxoshimov/Hospital/pkg/token"
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-contrib/cors"
@@ -26,6 +29,8 @@ type RouteOption struct {
 	Enforcer       *casbin.Enforcer
 	RefreshToken   token.JWTHandler
 	Service        repo.StorageI
+	Files          filestore.Store
+	Interactions   []*prescription.Rule
 }
 
 // NewRoute
@@ -48,19 +53,26 @@ func NewRoute(option RouteOption) *gin.Engine {
 		RefreshToken:   option.RefreshToken,
 		Enforcer:       option.Enforcer,
 		Service:        option.Service,
+		Files:          option.Files,
+		Interactions:   option.Interactions,
 	})
 
 	corsConfig := cors.Config{
 		AllowOrigins:     []string{"http://localhost:7777"}, 
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"}, 
-		AllowHeaders:     []string{"Content-Type", "Authorization"}, 
+		AllowHeaders:     []string{"Content-Type", "Authorization", middleware.IdempotencyHeader, tenant.Header}, 
 		AllowCredentials: true, 
 	}
 	
 	router.Use(cors.New(corsConfig))
 	
+	router.Use(middleware.Tenant(option.Config))
+
 	router.Use(middleware.CheckCasbinPermission(option.Enforcer, option.Config))
 
+	// retry safe booking endpoints honour the Idempotency-Key header
+	idempotent := middleware.Idempotency(option.Cache, option.Config, option.Logger)
+
 	// login
 	router.POST("/register", HandlerV1.Register)
 	router.POST("/login", HandlerV1.Login)
@@ -86,14 +98,155 @@ func NewRoute(option RouteOption) *gin.Engine {
 	router.DELETE("/doctor/:id", HandlerV1.DeleteDoctor)
 
 	//appointment
-	router.POST("/appointment", HandlerV1.CreateAppointment)
+	router.POST("/appointment", idempotent, HandlerV1.CreateAppointment)
 	router.GET("/appointments", HandlerV1.GetAppointments)
 	router.GET("/appointment/:id", HandlerV1.GetAppointmentByID)
-	router.PUT("/appointment", HandlerV1.UpdateAppointment)
+	router.PUT("/appointment/:id", HandlerV1.UpdateAppointment)
 	router.DELETE("/appointment/:id", HandlerV1.DeleteAppointment)
+	router.POST("/appointment/:id/confirm", HandlerV1.ConfirmAppointment)
+	router.POST("/appointment/:id/check-in", HandlerV1.CheckInAppointment)
+	router.POST("/appointment/:id/start", HandlerV1.StartAppointment)
+	router.POST("/appointment/:id/complete", HandlerV1.CompleteAppointment)
+	router.POST("/appointment/:id/cancel", HandlerV1.CancelAppointment)
+	router.POST("/appointment/:id/no-show", HandlerV1.NoShowAppointment)
+	router.GET("/appointment/:id/history", HandlerV1.GetAppointmentHistory)
+	router.GET("/me/appointments", HandlerV1.ListMyAppointments)
+	router.GET("/doctor/agenda", HandlerV1.GetDoctorAgenda)
+
+	//encounters
+	router.PUT("/appointment/:id/encounter", HandlerV1.SaveEncounter)
+	router.GET("/appointment/:id/encounter", HandlerV1.GetEncounter)
+	router.POST("/appointment/:id/encounter/sign", HandlerV1.SignEncounter)
+	router.POST("/appointment/:id/encounter/addendum", HandlerV1.AddEncounterAddendum)
+	router.GET("/appointment/:id/summary", HandlerV1.GetEncounterSummary)
+	router.GET("/me/encounters", HandlerV1.ListMyEncounters)
+
+	//prescriptions
+	router.POST("/medication", HandlerV1.CreateMedication)
+	router.PUT("/medication/:id", HandlerV1.UpdateMedication)
+	router.GET("/medications", HandlerV1.ListMedications)
+	router.POST("/medications/import", HandlerV1.ImportMedications)
+	router.GET("/user/:id/allergies", HandlerV1.ListAllergies)
+	router.POST("/user/:id/allergy", HandlerV1.CreateAllergy)
+	router.DELETE("/user/:id/allergy/:allergy_id", HandlerV1.DeleteAllergy)
+	router.POST("/appointment/:id/prescription", HandlerV1.CreatePrescription)
+	router.GET("/appointment/:id/prescriptions", HandlerV1.ListAppointmentPrescriptions)
+	router.GET("/prescription/:id", HandlerV1.GetPrescription)
+	router.GET("/prescription/:id/document", HandlerV1.GetPrescriptionDocument)
+	router.GET("/me/prescriptions", HandlerV1.ListMyPrescriptions)
+
+	//lab
+	router.POST("/appointment/:id/lab-order", HandlerV1.CreateLabOrder)
+	router.GET("/appointment/:id/lab-orders", HandlerV1.ListAppointmentLabOrders)
+	router.GET("/me/lab-orders", HandlerV1.ListMyLabOrders)
+	router.GET("/lab-orders", HandlerV1.ListLabOrders)
+	router.GET("/lab-order/:id", HandlerV1.GetLabOrder)
+	router.PUT("/lab-order/:id/results", HandlerV1.RecordLabResults)
+	router.POST("/lab-order/:id/release", HandlerV1.ReleaseLabOrder)
+	router.POST("/lab-order/:id/cancel", HandlerV1.CancelLabOrder)
+
+	//attachments
+	router.POST("/user/:id/attachment", HandlerV1.UploadUserAttachment)
+	router.GET("/user/:id/attachments", HandlerV1.ListUserAttachments)
+	router.POST("/appointment/:id/attachment", HandlerV1.UploadAppointmentAttachment)
+	router.GET("/appointment/:id/attachments", HandlerV1.ListAppointmentAttachments)
+	router.POST("/appointment/:id/encounter/attachment", HandlerV1.UploadEncounterAttachment)
+	router.GET("/appointment/:id/encounter/attachments", HandlerV1.ListEncounterAttachments)
+	router.GET("/attachment/:id/url", HandlerV1.GetAttachmentURL)
+	router.GET("/attachment/:id/download", HandlerV1.DownloadAttachment)
+	router.DELETE("/attachment/:id", HandlerV1.DeleteAttachment)
+
+	//appointment series
+	router.POST("/appointment-series", idempotent, HandlerV1.CreateAppointmentSeries)
+	router.GET("/appointment-series/:id", HandlerV1.GetAppointmentSeries)
+	router.POST("/appointment-series/:id/reschedule", HandlerV1.RescheduleAppointmentSeries)
+	router.POST("/appointment-series/:id/cancel", HandlerV1.CancelAppointmentSeries)
+
+	//calendar
+	router.GET("/appointment/:id/ics", HandlerV1.DownloadAppointmentICS)
+	router.POST("/me/calendar-feed", HandlerV1.CreateCalendarFeed)
+	router.DELETE("/me/calendar-feed", HandlerV1.DeleteCalendarFeed)
+	router.GET("/calendar/:token", HandlerV1.GetCalendarFeed)
+
+	//external busy times
+	router.POST("/doctor/busy-times/import", HandlerV1.ImportBusyTimes)
+	router.GET("/doctor/busy-times", HandlerV1.ListBusyTimes)
+	router.DELETE("/doctor/busy-times", HandlerV1.DeleteBusyTimes)
+
+	//doctor leave
+	router.POST("/doctor/leave", HandlerV1.CreateDoctorLeave)
+	router.GET("/doctor/leaves", HandlerV1.ListDoctorLeaves)
+	router.GET("/doctor/leave/:id/appointments", HandlerV1.GetLeaveAppointments)
+	router.POST("/doctor/leave/:id/resolve", HandlerV1.ResolveDoctorLeave)
+	router.DELETE("/doctor/leave/:id", HandlerV1.DeleteDoctorLeave)
+
+	//resources
+	router.POST("/resource", HandlerV1.CreateResource)
+	router.GET("/resources", HandlerV1.ListResources)
+	router.GET("/resource/:id", HandlerV1.GetResource)
+	router.PUT("/resource/:id", HandlerV1.UpdateResource)
+	router.DELETE("/resource/:id", HandlerV1.DeleteResource)
+	router.GET("/resource/:id/bookings", HandlerV1.ListResourceBookings)
+	router.GET("/appointment/:id/resources", HandlerV1.GetAppointmentResources)
+
+	//closures
+	router.POST("/closure", HandlerV1.CreateClosure)
+	router.GET("/closures", HandlerV1.ListClosures)
+	router.DELETE("/closure/:id", HandlerV1.DeleteClosure)
+
+	//branches
+	router.POST("/branch", HandlerV1.CreateBranch)
+	router.GET("/branches", HandlerV1.ListBranches)
+	router.GET("/branch/:id", HandlerV1.GetBranch)
+	router.PUT("/branch/:id", HandlerV1.UpdateBranch)
+	router.DELETE("/branch/:id", HandlerV1.DeleteBranch)
+	router.PUT("/doctor/:id/branches", HandlerV1.SetDoctorBranches)
+
+	//reminder links
+	router.GET("/reminder/:id/:action", HandlerV1.OpenReminderLink)
+	router.POST("/reminder/:id/:action", HandlerV1.ActOnReminderLink)
+
+	//two phase booking
+	router.POST("/slot-hold", idempotent, HandlerV1.HoldSlot)
+	router.POST("/slot-hold/:token/confirm", idempotent, HandlerV1.ConfirmSlotHold)
+	router.DELETE("/slot-hold/:token", HandlerV1.ReleaseSlotHold)
 	router.GET("/availabilities", HandlerV1.GetDoctorAvailabilities)
+	router.GET("/availabilities/search", HandlerV1.SearchAvailabilities)
 	router.GET("/availability/:id", HandlerV1.GetAvailabilityByID)
 
+	//availability
+	router.POST("/availability", HandlerV1.CreateAvailability)
+	router.POST("/availabilities/bulk", HandlerV1.CreateAvailabilitiesBulk)
+	router.PUT("/availability/:id", HandlerV1.UpdateAvailability)
+	router.DELETE("/availability/:id", HandlerV1.DeleteAvailability)
+
+	//appointment type
+	router.POST("/appointment-type", HandlerV1.CreateAppointmentType)
+	router.GET("/appointment-types", HandlerV1.ListAppointmentTypes)
+	router.GET("/appointment-type/:id", HandlerV1.GetAppointmentType)
+	router.PUT("/appointment-type/:id", HandlerV1.UpdateAppointmentType)
+	router.DELETE("/appointment-type/:id", HandlerV1.DeleteAppointmentType)
+	router.PUT("/appointment-type/:id/override", HandlerV1.SetAppointmentTypeOverride)
+	router.DELETE("/appointment-type/:id/override", HandlerV1.DeleteAppointmentTypeOverride)
+
+	//schedule
+	router.PUT("/schedule", HandlerV1.SaveSchedule)
+	router.GET("/schedule/:id", HandlerV1.GetSchedule)
+	router.DELETE("/schedule/:id", HandlerV1.DeleteSchedule)
+	router.POST("/schedule/:id/generate", HandlerV1.GenerateSchedule)
+
+	//cancellation policy
+	router.GET("/cancellation-policies", HandlerV1.ListCancellationPolicies)
+	router.GET("/cancellation-policy", HandlerV1.GetCancellationPolicy)
+	router.PUT("/cancellation-policy", HandlerV1.SaveCancellationPolicy)
+	router.DELETE("/cancellation-policy/:id", HandlerV1.DeleteCancellationPolicy)
+
+	//waitlist
+	router.POST("/waitlist", idempotent, HandlerV1.JoinWaitlist)
+	router.GET("/waitlist", HandlerV1.ListWaitlist)
+	router.POST("/waitlist/:id/accept", idempotent, HandlerV1.AcceptWaitlistOffer)
+	router.DELETE("/waitlist/:id", HandlerV1.LeaveWaitlist)
+
 
 	url := ginSwagger.URL("/swagger/doc.json")
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
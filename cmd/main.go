@@ -5,6 +5,8 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	// embed the zone database so TIMEZONE works on minimal images
+	_ "time/tzdata"
 
 	"go.uber.org/zap"
 
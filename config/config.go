@@ -1,22 +1,32 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
+	"strconv"
+	"strings"
 	"time"
-
-	
 )
 
 type Config struct {
 	App         string
 	Environment string
 	LogLevel    string
+	// Timezone is the hospital's IANA zone; wall clock inputs such as
+	// schedules and bulk slot ranges are interpreted in it.
+	Timezone string
+	Location *time.Location
 	Server      struct {
 		Host        string
 		Port         string
 		ReadTimeout  string
 		WriteTimeout string
 		IdleTimeout  string
+		// PublicURL is the address clients reach the API at, used for
+		// links that leave the service, e.g. in emails.
+		PublicURL string
 	}
 
 	Context struct {
@@ -43,6 +53,11 @@ type Config struct {
 		AccessTTL  time.Duration
 		RefreshTTL time.Duration
 		SignInKey  string
+		// LinkKey signs the expiring links handed out by mail. Without
+		// TOKEN_LINK_KEY it is derived from SignInKey, never SignInKey
+		// itself, and LinkKeyDerived is set so the app can warn about it.
+		LinkKey        string
+		LinkKeyDerived bool
 	}
 	Minio struct {
 		Endpoint                 string
@@ -51,6 +66,7 @@ type Config struct {
 		Location                 string
 		ImageUrlUploadBucketName string
 		FileUploadBucketName     string
+		UseSSL                   bool
 	}
 	SMTP struct {
 		Email         string
@@ -58,6 +74,61 @@ type Config struct {
 		SMTPPort      string
 		SMTPHost      string
 	}
+	Schedule struct {
+		WeeksAhead  int
+		SlotMinutes int
+		Interval    time.Duration
+	}
+	Booking struct {
+		// HoldDuration is how long a checkout may keep slots on hold
+		// before they are released to everyone else.
+		HoldDuration time.Duration
+	}
+	Idempotency struct {
+		// TTL is how long a response is replayed for retries
+		TTL time.Duration
+	}
+	Waitlist struct {
+		// HoldDuration is how long a freed slot is held for the patient
+		// it was offered to before it moves on to the next one.
+		HoldDuration time.Duration
+		Interval     time.Duration
+	}
+	Calendar struct {
+		// ImportDir is the only directory busy time calendars may be
+		// imported from by path; path imports are disabled when empty.
+		ImportDir string
+		// ImportWeeks is how far ahead recurring external events are
+		// expanded into busy times.
+		ImportWeeks int
+	}
+	Reminder struct {
+		// Offsets are how long before an appointment reminders go out
+		Offsets  []time.Duration
+		Interval time.Duration
+	}
+	Prescription struct {
+		// InteractionsFile holds the drug interaction rules prescriptions
+		// are checked against; it is read once at startup, so edits need a
+		// restart. Empty disables interaction checks.
+		InteractionsFile string
+	}
+	Attachment struct {
+		// Backend is where uploaded files are kept, "local" or "minio"
+		Backend  string
+		LocalDir string
+		// MaxSize is the largest upload accepted, in bytes
+		MaxSize int64
+		// ContentTypes are the media types uploads may have
+		ContentTypes []string
+		// URLTTL is how long a download link stays valid
+		URLTTL time.Duration
+	}
+	Tenant struct {
+		// Default is the hospital requests without a token, X-Tenant-ID
+		// header or tenant parameter go to; 0 makes naming one mandatory.
+		Default int64
+	}
 
 }
 
@@ -68,6 +139,13 @@ func NewConfig() (*Config, error) {
 	config.App = getEnv("APP", "app")
 	config.Environment = getEnv("ENVIRONMENT", "develop")
 	config.LogLevel = getEnv("LOG_LEVEL", "debug")
+	config.Timezone = getEnv("TIMEZONE", "Asia/Tashkent")
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	config.Location = location
 
 	// server configuration
 	config.Server.Host = getEnv("SERVER_HOST", "localhost")  //app
@@ -75,6 +153,7 @@ func NewConfig() (*Config, error) {
 	config.Server.ReadTimeout = getEnv("SERVER_READ_TIMEOUT", "10s")
 	config.Server.WriteTimeout = getEnv("SERVER_WRITE_TIMEOUT", "10s")
 	config.Server.IdleTimeout = getEnv("SERVER_IDLE_TIMEOUT", "120s")
+	config.Server.PublicURL = strings.TrimSuffix(getEnv("SERVER_PUBLIC_URL", "http://localhost:7777"), "/")
 
 	//context configuration
 	ContexTimeout, err := time.ParseDuration(getEnv("CONTEXT_TIMEOUT", "30s"))
@@ -105,6 +184,11 @@ func NewConfig() (*Config, error) {
 	config.Token.AccessTTL = accessTTl
 	config.Token.RefreshTTL = refreshTTL
 	config.Token.SignInKey = getEnv("TOKEN_SIGNIN_KEY", "debug")
+	config.Token.LinkKey = getEnv("TOKEN_LINK_KEY", "")
+	if config.Token.LinkKey == "" {
+		config.Token.LinkKey = deriveKey(config.Token.SignInKey, "links")
+		config.Token.LinkKeyDerived = true
+	}
 
 	// redis configuration
 	config.Redis.Host = getEnv("REDIS_HOST", "localhost")  //redisdb
@@ -118,10 +202,110 @@ func NewConfig() (*Config, error) {
 	config.SMTP.SMTPPort = getEnv("SMTP_PORT", "587")
 	config.SMTP.SMTPHost = getEnv("SMTP_HOST", "smtp.gmail.com")
 
+	// schedule generator configuration
+	config.Schedule.WeeksAhead, err = strconv.Atoi(getEnv("SCHEDULE_WEEKS_AHEAD", "4"))
+	if err != nil {
+		return nil, err
+	}
+	config.Schedule.SlotMinutes, err = strconv.Atoi(getEnv("SCHEDULE_SLOT_MINUTES", "30"))
+	if err != nil {
+		return nil, err
+	}
+	config.Schedule.Interval, err = time.ParseDuration(getEnv("SCHEDULE_INTERVAL", "1h"))
+	if err != nil {
+		return nil, err
+	}
+
+	// booking configuration
+	config.Booking.HoldDuration, err = time.ParseDuration(getEnv("BOOKING_HOLD_TTL", "10m"))
+	if err != nil {
+		return nil, err
+	}
+
+	// idempotency configuration
+	config.Idempotency.TTL, err = time.ParseDuration(getEnv("IDEMPOTENCY_TTL", "24h"))
+	if err != nil {
+		return nil, err
+	}
+
+	// waitlist configuration
+	config.Waitlist.HoldDuration, err = time.ParseDuration(getEnv("WAITLIST_HOLD", "30m"))
+	if err != nil {
+		return nil, err
+	}
+	config.Waitlist.Interval, err = time.ParseDuration(getEnv("WAITLIST_INTERVAL", "1m"))
+	if err != nil {
+		return nil, err
+	}
+
+	// calendar import configuration
+	config.Calendar.ImportDir = getEnv("CALENDAR_IMPORT_DIR", "")
+	config.Calendar.ImportWeeks, err = strconv.Atoi(getEnv("CALENDAR_IMPORT_WEEKS", "26"))
+	if err != nil {
+		return nil, err
+	}
+
+	// reminder configuration
+	for _, offset := range strings.Split(getEnv("REMINDER_OFFSETS", "24h,2h"), ",") {
+		duration, err := time.ParseDuration(strings.TrimSpace(offset))
+		if err != nil {
+			return nil, err
+		}
+		config.Reminder.Offsets = append(config.Reminder.Offsets, duration)
+	}
+	config.Reminder.Interval, err = time.ParseDuration(getEnv("REMINDER_INTERVAL", "5m"))
+	if err != nil {
+		return nil, err
+	}
+
+	// prescription configuration
+	config.Prescription.InteractionsFile = getEnv("PRESCRIPTION_INTERACTIONS_FILE", "./config/interactions.csv")
+
+	// minio configuration
+	config.Minio.Endpoint = getEnv("MINIO_ENDPOINT", "localhost:9000")
+	config.Minio.AccessKeyID = getEnv("MINIO_ACCESS_KEY_ID", "")
+	config.Minio.SecretAcessKey = getEnv("MINIO_SECRET_ACCESS_KEY", "")
+	config.Minio.Location = getEnv("MINIO_LOCATION", "us-east-1")
+	config.Minio.ImageUrlUploadBucketName = getEnv("MINIO_IMAGE_BUCKET", "images")
+	config.Minio.FileUploadBucketName = getEnv("MINIO_FILE_BUCKET", "files")
+	config.Minio.UseSSL, err = strconv.ParseBool(getEnv("MINIO_USE_SSL", "false"))
+	if err != nil {
+		return nil, err
+	}
+
+	// attachment configuration
+	config.Attachment.Backend = getEnv("ATTACHMENT_BACKEND", "local")
+	config.Attachment.LocalDir = getEnv("ATTACHMENT_LOCAL_DIR", "./attachments")
+	config.Attachment.MaxSize, err = strconv.ParseInt(getEnv("ATTACHMENT_MAX_SIZE", "10485760"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	for _, contentType := range strings.Split(getEnv("ATTACHMENT_CONTENT_TYPES", "application/pdf,image/jpeg,image/png,text/plain"), ",") {
+		config.Attachment.ContentTypes = append(config.Attachment.ContentTypes, strings.TrimSpace(contentType))
+	}
+	config.Attachment.URLTTL, err = time.ParseDuration(getEnv("ATTACHMENT_URL_TTL", "5m"))
+	if err != nil {
+		return nil, err
+	}
+
+	// tenant configuration
+	config.Tenant.Default, err = strconv.ParseInt(getEnv("TENANT_DEFAULT", "1"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
 
+// deriveKey derives a key for purpose from secret, so one secret never
+// signs two kinds of tokens.
+func deriveKey(secret, purpose string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 func getEnv(key string, defaultVaule string) string {
 	value, exists := os.LookupEnv(key)
 	if exists {
@@ -3,11 +3,19 @@ package entity
 import "time"
 
 type Appointment struct {
-	ID               int64
-	DoctorID         string
-	UserID           string
-	Appointment_time map[string]interface{}
-	Status           string
+	ID                int64
+	DoctorID          string
+	UserID            string
+	AppointmentTypeID int64
+	Appointment_time  map[string]interface{}
+	StartTime         time.Time
+	EndTime           time.Time
+	Status            string
+	RescheduleCount   int
+	SeriesID          int64
+	// BranchID is the branch of the slots the visit was booked into, 0
+	// when they are not tied to a branch.
+	BranchID int64
 }
 type Availability struct {
 	ID            int64
@@ -16,12 +24,91 @@ type Availability struct {
 	StartTime     time.Time
 	EndTime       time.Time
 	IsBooked      bool
+	// BranchID is where the slot is held, 0 when it is not tied to a
+	// branch. The doctor must be assigned to the branch and the slot lie
+	// within its opening hours.
+	BranchID int64
+}
+
+// AvailabilityBulk describes slots to publish for every matching day in
+// [FromDate, ToDate]. Dates are YYYY-MM-DD, times are HH:MM and Weekdays
+// uses time.Weekday numbering (0 = Sunday); empty means every day.
+type AvailabilityBulk struct {
+	DoctorID    string
+	FromDate    string
+	ToDate      string
+	StartTime   string
+	EndTime     string
+	SlotMinutes int
+	Weekdays    []int
+	BranchID    int64
 }
 type ListAppointments struct {
 	Appointments []*Appointment
 	TotalCount   int64
 }
+
+// AppointmentFilter narrows ListAppointments. Zero values do not filter;
+// StartFrom is inclusive and StartTo exclusive.
+type AppointmentFilter struct {
+	PatientID  string
+	DoctorID   string
+	SeriesID   int64
+	BranchID   int64
+	StartFrom  time.Time
+	StartTo    time.Time
+	Statuses   []string
+	Descending bool
+	Page       int
+	Limit      int
+}
+
+// AgendaEntry is an appointment on a doctor's agenda with the patient's
+// contact details.
+type AgendaEntry struct {
+	Appointment
+	PatientName  string
+	PatientPhone string
+}
+
+type DoctorAgenda struct {
+	DoctorID string
+	From     time.Time
+	To       time.Time
+	Entries  []*AgendaEntry
+}
 type ListAvailabilities struct {
 	Availabilities []*Availability
 	TotalCount     int64
 }
+
+// AvailabilitySearch filters the free slot search. From and To bound the
+// slot start; TimeFrom and TimeTo ("HH:MM") restrict the time of day in
+// Location.
+type AvailabilitySearch struct {
+	Specialization string
+	Language       string
+	BranchID       int64
+	From           time.Time
+	To             time.Time
+	TimeFrom       string
+	TimeTo         string
+	Location       *time.Location
+	Page           int
+	Limit          int
+}
+
+type AvailableSlot struct {
+	AvailabilityID int64
+	DoctorID       string
+	DoctorName     string
+	Specialization string
+	BranchID       int64
+	StartTime      time.Time
+	EndTime        time.Time
+}
+
+type ListAvailableSlots struct {
+	Slots      []*AvailableSlot
+	TotalCount int64
+}
@@ -0,0 +1,118 @@
+package entity
+
+import "time"
+
+const (
+	AppointmentStatusScheduled  = "scheduled"
+	AppointmentStatusConfirmed  = "confirmed"
+	AppointmentStatusCheckedIn  = "checked_in"
+	AppointmentStatusInProgress = "in_progress"
+	AppointmentStatusCompleted  = "completed"
+	AppointmentStatusCancelled  = "cancelled"
+	AppointmentStatusNoShow     = "no_show"
+)
+
+// AppointmentStatuses lists every status in lifecycle order.
+var AppointmentStatuses = []string{
+	AppointmentStatusScheduled,
+	AppointmentStatusConfirmed,
+	AppointmentStatusCheckedIn,
+	AppointmentStatusInProgress,
+	AppointmentStatusCompleted,
+	AppointmentStatusCancelled,
+	AppointmentStatusNoShow,
+}
+
+const (
+	RoleUser         = "user"
+	RoleReceptionist = "receptionist"
+	RoleDoctor       = "doctor"
+	RoleLab          = "lab"
+	RoleAdmin        = "admin"
+)
+
+// AppointmentTransitionRule describes one lifecycle action: the statuses
+// it may start from, the status it leads to and the roles allowed to
+// perform it. Admins may perform every action.
+type AppointmentTransitionRule struct {
+	From  []string
+	To    string
+	Roles []string
+}
+
+// AppointmentTransitions is the appointment lifecycle keyed by action name.
+var AppointmentTransitions = map[string]AppointmentTransitionRule{
+	"confirm": {
+		From:  []string{AppointmentStatusScheduled},
+		To:    AppointmentStatusConfirmed,
+		Roles: []string{RoleUser, RoleReceptionist},
+	},
+	"check-in": {
+		From:  []string{AppointmentStatusScheduled, AppointmentStatusConfirmed},
+		To:    AppointmentStatusCheckedIn,
+		Roles: []string{RoleReceptionist},
+	},
+	"start": {
+		From:  []string{AppointmentStatusCheckedIn},
+		To:    AppointmentStatusInProgress,
+		Roles: []string{RoleDoctor},
+	},
+	"complete": {
+		From:  []string{AppointmentStatusInProgress},
+		To:    AppointmentStatusCompleted,
+		Roles: []string{RoleDoctor},
+	},
+	"cancel": {
+		From:  []string{AppointmentStatusScheduled, AppointmentStatusConfirmed},
+		To:    AppointmentStatusCancelled,
+		Roles: []string{RoleUser, RoleReceptionist, RoleDoctor},
+	},
+	"no-show": {
+		From:  []string{AppointmentStatusScheduled, AppointmentStatusConfirmed},
+		To:    AppointmentStatusNoShow,
+		Roles: []string{RoleReceptionist, RoleDoctor},
+	},
+}
+
+// Allows reports whether the rule can move an appointment out of status.
+func (r AppointmentTransitionRule) Allows(status string) bool {
+	for _, from := range r.From {
+		if from == status {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsRole reports whether role may perform the action.
+func (r AppointmentTransitionRule) AllowsRole(role string) bool {
+	if role == RoleAdmin {
+		return true
+	}
+	for _, allowed := range r.Roles {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// AppointmentTransition is one recorded status change.
+type AppointmentTransition struct {
+	ID            int64
+	AppointmentID int64
+	FromStatus    string
+	ToStatus      string
+	ChangedBy     string
+	Reason        string
+	ChangedAt     time.Time
+}
+
+type AppointmentTransitionRequest struct {
+	Reason string
+}
+
+type ListAppointmentTransitions struct {
+	Transitions []*AppointmentTransition
+	TotalCount  int64
+}
@@ -0,0 +1,28 @@
+package entity
+
+// AppointmentType is a kind of visit with its default length. BufferMinutes
+// is extra time blocked after the visit (cleaning, paperwork).
+// RequiredResources lists the resource categories the visit needs, one
+// resource per entry.
+type AppointmentType struct {
+	ID                int64
+	Name              string
+	Description       string
+	DurationMinutes   int
+	BufferMinutes     int
+	RequiredResources []string
+}
+
+// DoctorAppointmentType overrides the type defaults for one doctor. A nil
+// field falls back to the type default.
+type DoctorAppointmentType struct {
+	DoctorID          string
+	AppointmentTypeID int64
+	DurationMinutes   *int
+	BufferMinutes     *int
+}
+
+type ListAppointmentTypes struct {
+	AppointmentTypes []*AppointmentType
+	TotalCount       int64
+}
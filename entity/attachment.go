@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// Attachment is a file uploaded for a user, an appointment or an
+// encounter; exactly one of UserID, EncounterID and AppointmentID without
+// EncounterID names its owner. Encounter attachments carry the
+// appointment of the encounter too.
+type Attachment struct {
+	ID            int64
+	UserID        string
+	AppointmentID int64
+	EncounterID   int64
+	FileName      string
+	ContentType   string
+	Size          int64
+	// Key is where the file is kept in the file store
+	Key        string `json:"-"`
+	UploadedBy string
+	CreatedAt  time.Time
+}
+
+// AttachmentURL is a short-lived link to download an attachment without a
+// token.
+type AttachmentURL struct {
+	URL       string
+	ExpiresAt time.Time
+}
+
+type ListAttachments struct {
+	Attachments []*Attachment
+	TotalCount  int64
+}
+
+// AttachmentFilter selects the attachments of one owner.
+type AttachmentFilter struct {
+	UserID        string
+	AppointmentID int64
+	EncounterID   int64
+}
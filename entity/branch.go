@@ -0,0 +1,48 @@
+package entity
+
+import "time"
+
+// Branch is one site of the hospital. Doctors are assigned to one or more
+// branches and publish their availability at one of them.
+type Branch struct {
+	ID      int64
+	Name    string
+	Address string
+	// Timezone is the IANA zone of the branch; its opening hours and
+	// closures are given on this wall clock.
+	Timezone string
+	Phone    string
+	Email    string
+	// Hours are the weekly opening hours; none means always open.
+	Hours     []*OpeningHours
+	CreatedAt time.Time
+}
+
+// OpeningHours opens a branch or resource on Weekday (0 = Sunday) from
+// StartTime to EndTime (HH:MM, in the owner's zone).
+type OpeningHours struct {
+	Weekday   int
+	StartTime string
+	EndTime   string
+}
+
+// BranchRequest creates or replaces a branch. Timezone defaults to the
+// hospital zone.
+type BranchRequest struct {
+	Name     string `binding:"required"`
+	Address  string
+	Timezone string
+	Phone    string
+	Email    string
+	Hours    []*OpeningHours
+}
+
+// DoctorBranchesRequest replaces the branches a doctor works at.
+type DoctorBranchesRequest struct {
+	BranchIDs []int64
+}
+
+type ListBranches struct {
+	Branches   []*Branch
+	TotalCount int64
+}
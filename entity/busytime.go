@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// BusyTime is a commitment a doctor has outside the hospital, imported
+// from one of their external calendars. Slots overlapping it cannot be
+// booked.
+type BusyTime struct {
+	ID       int64
+	DoctorID string
+	// Source names the calendar the busy time was imported from; a
+	// re-import of the same source replaces its busy times.
+	Source    string
+	UID       string
+	Summary   string
+	StartTime time.Time
+	EndTime   time.Time
+	UpdatedAt time.Time
+}
+
+type ListBusyTimes struct {
+	BusyTimes  []*BusyTime
+	TotalCount int64
+}
+
+// BusyTimeImport reports what an import changed. Conflicts lists the
+// appointments already booked into the imported busy times; they are left
+// as they are for staff to resolve.
+type BusyTimeImport struct {
+	DoctorID     string
+	Source       string
+	Added        int
+	Updated      int
+	Removed      int
+	Skipped      int
+	BlockedSlots int
+	Conflicts    []*Appointment
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// CalendarFeed is a user's secret iCalendar subscription. Anyone holding
+// the token can read the feed, so rotating it revokes old subscriptions.
+type CalendarFeed struct {
+	UserID    string
+	Token     string
+	URL       string
+	CreatedAt time.Time
+}
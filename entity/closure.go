@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// Closure is a public holiday or other time the hospital, or one of its
+// branches, is closed. Nothing can be booked while it lasts.
+type Closure struct {
+	ID int64
+	// BranchID is the branch that is closed; nil closes every branch.
+	BranchID *int64
+	// Date and EndDate are the first and last closed day (YYYY-MM-DD).
+	// EndDate defaults to Date.
+	Date    string
+	EndDate string
+	// FromTime and ToTime (HH:MM) close only part of a single day; both
+	// empty closes the whole day.
+	FromTime  string
+	ToTime    string
+	Reason    string
+	StartTime time.Time
+	EndTime   time.Time
+	CreatedAt time.Time
+}
+
+type ListClosures struct {
+	Closures   []*Closure
+	TotalCount int64
+}
+
+// ClosureRequest closes the branch BranchID, or the whole hospital when
+// it is nil. Dates and times are on the wall clock of the branch, or of
+// the hospital.
+type ClosureRequest struct {
+	BranchID *int64
+	Date     string `binding:"required"`
+	EndDate  string
+	FromTime string
+	ToTime   string
+	Reason   string
+}
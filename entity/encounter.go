@@ -0,0 +1,83 @@
+package entity
+
+import "time"
+
+// States of an encounter record. Drafts can be edited freely; signed
+// records are final and only take addenda.
+const (
+	EncounterStatusDraft  = "draft"
+	EncounterStatusSigned = "signed"
+)
+
+// Encounter is the clinical record the doctor writes for an appointment.
+type Encounter struct {
+	ID            int64
+	AppointmentID int64
+	DoctorID      string
+	PatientID     string
+	Status        string
+	// ChiefComplaint is the reason for the visit in the patient's words
+	ChiefComplaint string
+	// Subjective, Objective, Assessment and Plan are the SOAP notes
+	Subjective string
+	Objective  string
+	Assessment string
+	Plan       string
+	Diagnoses  []*Diagnosis
+	// Addenda are amendments made after signing, oldest first
+	Addenda   []*Addendum
+	CreatedBy string
+	SignedBy  string
+	SignedAt  *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Diagnosis is a coded diagnosis, e.g. an ICD-10 code.
+type Diagnosis struct {
+	Code        string `binding:"required"`
+	Description string
+}
+
+// Addendum amends a signed encounter without changing it.
+type Addendum struct {
+	ID          int64
+	EncounterID int64
+	Text        string
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+// EncounterRequest creates or replaces the draft of an encounter.
+type EncounterRequest struct {
+	ChiefComplaint string
+	Subjective     string
+	Objective      string
+	Assessment     string
+	Plan           string
+	Diagnoses      []*Diagnosis `binding:"dive"`
+}
+
+type AddendumRequest struct {
+	Text string `binding:"required"`
+}
+
+// EncounterSummary is what the patient sees of a signed encounter: why
+// they came, what was found and what happens next. The working notes stay
+// with the clinicians.
+type EncounterSummary struct {
+	EncounterID    int64
+	AppointmentID  int64
+	DoctorID       string
+	StartTime      time.Time
+	ChiefComplaint string
+	Diagnoses      []*Diagnosis
+	Plan           string
+	Addenda        []*Addendum
+	SignedAt       time.Time
+}
+
+type ListEncounterSummaries struct {
+	Summaries  []*EncounterSummary
+	TotalCount int64
+}
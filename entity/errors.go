@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -8,6 +9,36 @@ import (
 var (
 	ErrorConflict = NewErrConflict("object")
 	ErrorNotFound = NewErrNotFound("object")
+
+	ErrorForbidden   = errors.New("permission denied")
+	ErrorSlotOverlap = errors.New("slot overlaps an existing slot of the doctor")
+	ErrorSlotBooked  = errors.New("slot is already booked")
+
+	ErrorSlotUnavailable = errors.New("doctor is not available at this time")
+	ErrorSlotTaken       = errors.New("slot was taken by another booking")
+
+	ErrorIllegalTransition = errors.New("appointment status does not allow this action")
+	ErrorPolicyViolation   = errors.New("not allowed by the cancellation policy")
+
+	ErrorOfferClosed = errors.New("waitlist offer is not open")
+	ErrorSlotHeld    = errors.New("slot is held by another patient")
+	ErrorHoldExpired = errors.New("slot hold has expired")
+
+	ErrorNoSubstitute = errors.New("no other doctor is free at this time")
+	ErrorClosed       = errors.New("the hospital is closed at this time")
+
+	ErrorSeriesUnavailable = errors.New("not every occurrence of the series is available")
+
+	ErrorResourceUnavailable = errors.New("no room or equipment the visit needs is free at this time")
+	ErrorResourceInUse       = errors.New("resource has upcoming bookings")
+
+	ErrorBranchNotAssigned = errors.New("doctor does not work at this branch")
+	ErrorBranchInUse       = errors.New("branch has upcoming appointments")
+
+	ErrorEncounterSigned    = errors.New("encounter is signed and can only be amended with an addendum")
+	ErrorEncounterNotSigned = errors.New("encounter is not signed yet, edit the draft instead")
+
+	ErrorLabOrderStatus = errors.New("lab order status does not allow this action")
 )
 
 // error not found
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// SlotHold reserves the slots of a booking for a patient while they finish
+// checking out. Holds live in Redis and disappear when they expire.
+type SlotHold struct {
+	Token             string
+	DoctorID          string
+	PatientID         string
+	AppointmentTypeID int64
+	StartTime         time.Time
+	EndTime           time.Time
+	AvailabilityIDs   []int64
+	ExpiresAt         time.Time
+}
@@ -0,0 +1,91 @@
+package entity
+
+import "time"
+
+// States of a lab order. The lab records results on an ordered test and
+// releases them once checked; only released results are shown to the
+// doctor and the patient.
+const (
+	LabOrderStatusOrdered   = "ordered"
+	LabOrderStatusResulted  = "resulted"
+	LabOrderStatusReleased  = "released"
+	LabOrderStatusCancelled = "cancelled"
+)
+
+// Flags of abnormal lab results. Normal results have no flag.
+const (
+	LabFlagLow      = "low"
+	LabFlagHigh     = "high"
+	LabFlagAbnormal = "abnormal"
+)
+
+// LabOrder is a lab test the doctor ordered at an appointment.
+type LabOrder struct {
+	ID            int64
+	AppointmentID int64
+	PatientID     string
+	DoctorID      string
+	TestCode      string
+	TestName      string
+	Notes         string
+	Status        string
+	// Results and Report are empty until the results are released
+	Results []*LabResult
+	Report  string
+	// Abnormal is set when any result is flagged
+	Abnormal   bool
+	OrderedBy  string
+	ResultedBy string
+	ResultedAt *time.Time
+	ReleasedBy string
+	ReleasedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// LabResult is the measured value of one analyte of a lab test.
+type LabResult struct {
+	Analyte string
+	// Value is set for numeric results, Text for the others
+	Value         *float64
+	Text          string
+	Unit          string
+	ReferenceLow  *float64
+	ReferenceHigh *float64
+	Flag          string
+}
+
+type LabOrderRequest struct {
+	TestCode string `binding:"required"`
+	TestName string `binding:"required"`
+	Notes    string
+}
+
+// LabResultRequest records one result. Numeric values are flagged against
+// the reference range; Abnormal flags the others.
+type LabResultRequest struct {
+	Analyte       string `binding:"required"`
+	Value         *float64
+	Text          string
+	Unit          string
+	ReferenceLow  *float64
+	ReferenceHigh *float64
+	Abnormal      bool
+}
+
+// LabResultsRequest records the results of a lab order, replacing those
+// recorded before. It needs results, a report or both.
+type LabResultsRequest struct {
+	Results []*LabResultRequest `binding:"dive"`
+	Report  string
+}
+
+type ListLabOrders struct {
+	LabOrders  []*LabOrder
+	TotalCount int64
+}
+
+type LabOrderFilter struct {
+	AppointmentID int64
+	PatientID     string
+	Status        string
+}
@@ -0,0 +1,69 @@
+package entity
+
+import "time"
+
+// What to do with the appointments booked into a doctor's leave.
+const (
+	LeaveActionNone     = ""
+	LeaveActionCancel   = "cancel"
+	LeaveActionReassign = "reassign"
+)
+
+// What happened to an appointment booked into a doctor's leave.
+const (
+	LeaveOutcomeKept       = "kept"
+	LeaveOutcomeCancelled  = "cancelled"
+	LeaveOutcomeReassigned = "reassigned"
+	LeaveOutcomeFailed     = "failed"
+)
+
+// DoctorLeave is a period the doctor is away, such as a vacation or sick
+// leave. No new bookings can be made with the doctor during it.
+type DoctorLeave struct {
+	ID       int64
+	DoctorID string
+	// StartDate and EndDate are the first and last day of the leave
+	// (YYYY-MM-DD); StartTime and EndTime the same range as instants.
+	StartDate string
+	EndDate   string
+	StartTime time.Time
+	EndTime   time.Time
+	Reason    string
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// LeaveResolution says what to do with the appointments a leave affects.
+type LeaveResolution struct {
+	// Action is "" to only list them, "cancel" or "reassign".
+	Action string
+	// ReassignTo is the doctor taking the appointments over. When empty
+	// each appointment goes to any free doctor of the same specialization.
+	ReassignTo string
+}
+
+type DoctorLeaveRequest struct {
+	DoctorID  string
+	StartDate string
+	EndDate   string
+	Reason    string
+	LeaveResolution
+}
+
+// LeaveAppointment is an appointment booked into a leave and what was
+// done with it.
+type LeaveAppointment struct {
+	Appointment
+	Outcome string
+	Error   string
+}
+
+type DoctorLeaveResult struct {
+	Leave        *DoctorLeave
+	Appointments []*LeaveAppointment
+}
+
+type ListDoctorLeaves struct {
+	Leaves     []*DoctorLeave
+	TotalCount int64
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// CancellationPolicy controls how late appointments may be cancelled or
+// rescheduled. The policy without an AppointmentTypeID is the hospital
+// default; a policy for a type replaces it for appointments of that type.
+type CancellationPolicy struct {
+	ID                 int64
+	AppointmentTypeID  *int64
+	CutoffMinutes      int
+	MaxReschedules     int // negative means unlimited
+	LateCancelAsNoShow bool
+	AllowAdminOverride bool
+	UpdatedAt          time.Time
+}
+
+func (p *CancellationPolicy) withinCutoff(start, now time.Time) bool {
+	return start.Sub(now) < time.Duration(p.CutoffMinutes)*time.Minute
+}
+
+// CheckReschedule returns an error when moving the appointment is not
+// allowed. override is honoured only if the policy permits admin overrides.
+func (p *CancellationPolicy) CheckReschedule(appointment *Appointment, now time.Time, override bool) error {
+	if override && p.AllowAdminOverride {
+		return nil
+	}
+	if p.withinCutoff(appointment.StartTime, now) {
+		return fmt.Errorf("%w: cannot reschedule within %d minutes of the appointment", ErrorPolicyViolation, p.CutoffMinutes)
+	}
+	if p.MaxReschedules >= 0 && appointment.RescheduleCount >= p.MaxReschedules {
+		return fmt.Errorf("%w: appointment was already rescheduled %d times", ErrorPolicyViolation, appointment.RescheduleCount)
+	}
+
+	return nil
+}
+
+// CancelStatus returns the status a cancellation made now results in: a
+// plain cancellation, or a no-show when a late cancellation counts as one.
+func (p *CancellationPolicy) CancelStatus(appointment *Appointment, now time.Time, override bool) (string, error) {
+	if (override && p.AllowAdminOverride) || !p.withinCutoff(appointment.StartTime, now) {
+		return AppointmentStatusCancelled, nil
+	}
+	if p.LateCancelAsNoShow {
+		return AppointmentStatusNoShow, nil
+	}
+
+	return "", fmt.Errorf("%w: cannot cancel within %d minutes of the appointment", ErrorPolicyViolation, p.CutoffMinutes)
+}
+
+type ListCancellationPolicies struct {
+	Policies   []*CancellationPolicy
+	TotalCount int64
+}
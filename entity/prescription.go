@@ -0,0 +1,145 @@
+package entity
+
+import "time"
+
+// Kinds of prescription alerts.
+const (
+	AlertKindAllergy     = "allergy"
+	AlertKindInteraction = "interaction"
+)
+
+// Medication is an entry of the hospital's medication catalogue.
+type Medication struct {
+	ID   int64
+	Name string
+	// Ingredients are the active substances, in lower case
+	Ingredients []string
+	Form        string
+	Strength    string
+	// Active medications can be prescribed; retired ones stay on old
+	// prescriptions
+	Active    bool
+	CreatedAt time.Time
+}
+
+type MedicationRequest struct {
+	Name        string `binding:"required"`
+	Ingredients []string
+	Form        string
+	Strength    string
+	// Active defaults to true
+	Active *bool
+}
+
+type ListMedications struct {
+	Medications []*Medication
+	TotalCount  int64
+}
+
+// MedicationImport reports a catalogue import. Rows with errors are
+// skipped; the others are added or, when a medication of the same name,
+// form and strength exists, replace it.
+type MedicationImport struct {
+	Created int
+	Updated int
+	Errors  []*ImportError
+}
+
+type ImportError struct {
+	Line    int
+	Message string
+}
+
+// Allergy is a substance the patient is allergic to.
+type Allergy struct {
+	ID        int64
+	PatientID string
+	// Substance is matched against the ingredients and names of
+	// prescribed medications, case insensitively
+	Substance string
+	Reaction  string
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+type AllergyRequest struct {
+	Substance string `binding:"required"`
+	Reaction  string
+}
+
+type ListAllergies struct {
+	Allergies  []*Allergy
+	TotalCount int64
+}
+
+// Prescription is written by the doctor as part of an encounter.
+type Prescription struct {
+	ID            int64
+	EncounterID   int64
+	AppointmentID int64
+	PatientID     string
+	DoctorID      string
+	Items         []*PrescriptionItem
+	Notes         string
+	// Alerts are the warnings the doctor acknowledged when prescribing
+	Alerts    []*PrescriptionAlert
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// PrescriptionItem prescribes one medication.
+type PrescriptionItem struct {
+	MedicationID int64
+	Medication   string
+	Form         string
+	Strength     string
+	// Dosage and Frequency are free text, e.g. "500 mg" and "3 times a day"
+	Dosage       string
+	Frequency    string
+	DurationDays int
+	Instructions string
+}
+
+type PrescriptionItemRequest struct {
+	MedicationID int64  `binding:"required"`
+	Dosage       string `binding:"required"`
+	Frequency    string `binding:"required"`
+	DurationDays int    `binding:"required,min=1"`
+	Instructions string
+}
+
+// PrescriptionRequest writes a prescription. Prescriptions that raise
+// alerts are refused until the doctor resends them with AcknowledgeAlerts.
+type PrescriptionRequest struct {
+	Items             []*PrescriptionItemRequest `binding:"required,min=1,dive"`
+	Notes             string
+	AcknowledgeAlerts bool
+}
+
+// PrescriptionAlert warns about an allergy of the patient or an
+// interaction with another medication the patient is taking.
+type PrescriptionAlert struct {
+	Kind     string
+	Severity string
+	Message  string
+	// Medications are the names of the medications involved
+	Medications []string
+}
+
+// PrescriptionAlerts is returned when a prescription raised alerts the
+// doctor has not acknowledged yet.
+type PrescriptionAlerts struct {
+	Message string
+	Alerts  []*PrescriptionAlert
+}
+
+type ListPrescriptions struct {
+	Prescriptions []*Prescription
+	TotalCount    int64
+}
+
+// PrescriptionFilter narrows a prescription list; zero values do not filter.
+type PrescriptionFilter struct {
+	AppointmentID int64
+	PatientID     string
+}
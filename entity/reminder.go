@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// Reminder is an appointment due for the reminder sent Offset before it.
+type Reminder struct {
+	AppointmentID int64
+	Offset        time.Duration
+	PatientName   string
+	PatientEmail  string
+	DoctorName    string
+	StartTime     time.Time
+}
@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+const (
+	ResourceKindRoom      = "room"
+	ResourceKindEquipment = "equipment"
+)
+
+// Resource is a room or a piece of equipment procedures are booked into.
+// Appointment types ask for resources by Category; booking takes any free,
+// active resource of that category.
+type Resource struct {
+	ID          int64
+	Name        string
+	Kind        string
+	Category    string
+	Description string
+	Active      bool
+	// Hours are the weekly opening hours; none means always open.
+	Hours     []*OpeningHours
+	CreatedAt time.Time
+}
+
+// ResourceBooking is the time an appointment holds a resource, including
+// the appointment type's buffer.
+type ResourceBooking struct {
+	ResourceID    int64
+	ResourceName  string
+	AppointmentID int64
+	StartTime     time.Time
+	EndTime       time.Time
+}
+
+// ResourceRequest creates or replaces a resource. Active defaults to true.
+type ResourceRequest struct {
+	Name        string
+	Kind        string
+	Category    string
+	Description string
+	Active      *bool
+	Hours       []*OpeningHours
+}
+
+type ListResources struct {
+	Resources  []*Resource
+	TotalCount int64
+}
+
+type ListResourceBookings struct {
+	Bookings   []*ResourceBooking
+	TotalCount int64
+}
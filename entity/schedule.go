@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// Schedule is a doctor's recurring weekly template from which
+// doctor_availability slots are generated.
+type Schedule struct {
+	ID          int64
+	DoctorID    string
+	SlotMinutes int
+	Days        []ScheduleDay
+	UpdatedAt   time.Time
+}
+
+// ScheduleDay is the working window for one weekday (0 = Sunday).
+// Times are HH:MM, on the wall clock of BranchID when the day is worked
+// at a branch and of the hospital otherwise.
+type ScheduleDay struct {
+	Weekday   int
+	StartTime string
+	EndTime   string
+	Breaks    []ScheduleBreak
+	BranchID  int64
+}
+
+type ScheduleBreak struct {
+	StartTime string
+	EndTime   string
+}
+
+type ScheduleGenerateResponse struct {
+	Created int
+}
@@ -0,0 +1,80 @@
+package entity
+
+import "time"
+
+const (
+	// SeriesModeAllOrNothing books the series only if every occurrence is
+	// available.
+	SeriesModeAllOrNothing = "all_or_nothing"
+	// SeriesModeBestEffort books the available occurrences and reports the
+	// rest.
+	SeriesModeBestEffort = "best_effort"
+
+	// MaxSeriesOccurrences bounds how many visits one series may book.
+	MaxSeriesOccurrences = 104
+)
+
+// AppointmentSeries is a run of appointments with the same doctor at the
+// same time of day every IntervalWeeks weeks. It ends after Occurrences
+// visits or on the Until date.
+type AppointmentSeries struct {
+	ID                int64
+	DoctorID          string
+	UserID            string
+	AppointmentTypeID int64
+	StartTime         time.Time
+	IntervalWeeks     int
+	Occurrences       int
+	Until             string
+	Mode              string
+	CreatedAt         time.Time
+	Appointments      []*Appointment `json:",omitempty"`
+}
+
+// AppointmentSeriesRequest books a series. StartTime is the first visit,
+// RFC 3339 with an offset; Until is YYYY-MM-DD in the tz zone. Exactly one
+// of Occurrences and Until is required. IntervalWeeks defaults to 1 and
+// Mode to all_or_nothing.
+type AppointmentSeriesRequest struct {
+	DoctorID          string `binding:"required"`
+	UserID            string
+	AppointmentTypeID int64
+	StartTime         string `binding:"required"`
+	IntervalWeeks     int
+	Occurrences       int
+	Until             string
+	Mode              string
+}
+
+// SeriesOccurrence is the outcome of booking or moving one visit of a
+// series. Error is set when the visit could not be booked or moved.
+type SeriesOccurrence struct {
+	StartTime   time.Time
+	Appointment *Appointment `json:",omitempty"`
+	Error       string       `json:",omitempty"`
+}
+
+// AppointmentSeriesResult reports a series operation visit by visit.
+// Succeeded counts the visits booked, moved or cancelled.
+type AppointmentSeriesResult struct {
+	Series      *AppointmentSeries
+	Succeeded   int
+	Failed      int
+	Occurrences []*SeriesOccurrence
+}
+
+// SeriesRescheduleRequest moves the visit FromAppointmentID to StartTime
+// (RFC 3339) and every later visit of the series by the same number of
+// days, to the same new time of day.
+type SeriesRescheduleRequest struct {
+	FromAppointmentID int64  `binding:"required"`
+	StartTime         string `binding:"required"`
+	Mode              string
+}
+
+// SeriesCancelRequest cancels the visit FromAppointmentID and every later
+// visit of the series, or all upcoming visits when it is zero.
+type SeriesCancelRequest struct {
+	FromAppointmentID int64
+	Reason            string
+}
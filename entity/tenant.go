@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// Tenant is one hospital hosted on the deployment. Every other record
+// belongs to exactly one tenant.
+type Tenant struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
@@ -20,6 +20,10 @@ type Doctor struct{
 	Specialization string
 	Working_hour string
 	ExtraInfo map[string]interface{}
+	Languages []string
+	// BranchIDs are the branches the doctor works at, set through
+	// PUT /doctor/{id}/branches.
+	BranchIDs []int64
 }
 
 type Response struct {
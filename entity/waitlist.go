@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+const (
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusOffered   = "offered"
+	WaitlistStatusAccepted  = "accepted"
+	WaitlistStatusExpired   = "expired"
+	WaitlistStatusCancelled = "cancelled"
+)
+
+// WaitlistEntry is a patient waiting for a free slot of a doctor between
+// FromTime and ToTime, at BranchID when set and at any branch otherwise.
+// When a slot frees up the first matching entry gets an offer: the slots
+// are held for the patient until OfferExpiresAt.
+type WaitlistEntry struct {
+	ID                int64
+	DoctorID          string
+	PatientID         string
+	AppointmentTypeID int64
+	BranchID          int64
+	FromTime          time.Time
+	ToTime            time.Time
+	Status            string
+	OfferedStartTime  *time.Time
+	OfferExpiresAt    *time.Time
+	AppointmentID     *int64
+	CreatedAt         time.Time
+}
+
+type ListWaitlistEntries struct {
+	Entries    []*WaitlistEntry
+	TotalCount int64
+}
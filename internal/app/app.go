@@ -1,13 +1,19 @@
 package app
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Abdulazizxoshimov/Hospital/api"
 	"github.com/Abdulazizxoshimov/Hospital/api/server"
 	"github.com/Abdulazizxoshimov/Hospital/config"
 	repo "github.com/Abdulazizxoshimov/Hospital/internal/repo"
+	"github.com/Abdulazizxoshimov/Hospital/internal/prescription"
+	"github.com/Abdulazizxoshimov/Hospital/internal/reminder"
 	redisrepo "github.com/Abdulazizxoshimov/Hospital/internal/repo/redisdb"
+	"github.com/Abdulazizxoshimov/Hospital/internal/schedule"
+	"github.com/Abdulazizxoshimov/Hospital/internal/waitlist"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/filestore"
 	"github.com/Abdulazizxoshimov/Hospital/pkg/logger"
 	"github.com/Abdulazizxoshimov/Hospital/pkg/storage"
 
@@ -20,13 +26,16 @@ import (
 )
 
 type App struct {
-	Config   config.Config
-	Logger   logger.Logger
-	DB       *storage.PostgresDB
-	server   *http.Server
-	Enforcer *casbin.Enforcer
-	RedisDB  *storage.RedisDB
-	StorageI repo.StorageI
+	Config       config.Config
+	Logger       logger.Logger
+	DB           *storage.PostgresDB
+	server       *http.Server
+	Enforcer     *casbin.Enforcer
+	RedisDB      *storage.RedisDB
+	StorageI     repo.StorageI
+	Files        filestore.Store
+	Interactions []*prescription.Rule
+	cancel       context.CancelFunc
 }
 
 func NewApp(cfg config.Config) (*App, error) {
@@ -35,6 +44,9 @@ func NewApp(cfg config.Config) (*App, error) {
 	if err != nil {
 		return nil, err
 	}
+	if cfg.Token.LinkKeyDerived {
+		logger.Warn("TOKEN_LINK_KEY is not set, signing links with a key derived from TOKEN_SIGNIN_KEY")
+	}
 
 	//init redis
 	redisdb, err := storage.NewRedis(&cfg)
@@ -56,12 +68,27 @@ func NewApp(cfg config.Config) (*App, error) {
 
 	storageI := repo.NewStoragePg(db)
 
+	// init file storage
+	files, err := filestore.New(context.Background(), &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// load the drug interaction rules, a missing or broken file stops the boot
+	interactions, err := prescription.LoadRules(cfg.Prescription.InteractionsFile)
+	if err != nil {
+		return nil, err
+	}
+
 	return &App{
-		Config:   cfg,
-		Logger:   logger,
-		RedisDB:  redisdb,
-		Enforcer: enforcer,
-		StorageI: storageI,
+		Config:       cfg,
+		Logger:       logger,
+		DB:           db,
+		RedisDB:      redisdb,
+		Enforcer:     enforcer,
+		StorageI:     storageI,
+		Files:        files,
+		Interactions: interactions,
 	}, nil
 }
 
@@ -78,6 +105,8 @@ func (a *App) Run() error {
 		Cache:          cache,
 		Enforcer:       a.Enforcer,
 		Service:        a.StorageI,
+		Files:          a.Files,
+		Interactions:   a.Interactions,
 	})
 
 	//for Casbin init
@@ -90,6 +119,19 @@ func (a *App) Run() error {
 	roleManager.AddMatchingFunc("keyMatch", util.KeyMatch)
 	roleManager.AddMatchingFunc("keyMatch3", util.KeyMatch3)
 
+	// background jobs
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+
+	generator := schedule.NewGenerator(a.StorageI, a.Logger, a.Config.Schedule.WeeksAhead, a.Config.Location)
+	go generator.Run(ctx, a.Config.Schedule.Interval)
+
+	dispatcher := waitlist.NewDispatcher(a.StorageI, a.Logger, a.Config)
+	go dispatcher.Run(ctx, a.Config.Waitlist.Interval)
+
+	reminders := reminder.NewScheduler(a.StorageI, a.Logger, a.Config)
+	go reminders.Run(ctx, a.Config.Reminder.Interval)
+
 	// server init
 	a.server, err = server.NewServer(&a.Config, handler)
 	if err != nil {
@@ -100,6 +142,11 @@ func (a *App) Run() error {
 }
 
 func (a *App) Stop() {
+	// background jobs
+	if a.cancel != nil {
+		a.cancel()
+	}
+
 	// database connection
 	a.DB.Close()
 
@@ -0,0 +1,216 @@
+// Package prescription checks prescriptions against the patient's
+// allergies and a table of drug interactions, and reads medication
+// catalogues from CSV.
+package prescription
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+)
+
+// Rule says that two active substances interact.
+type Rule struct {
+	IngredientA string
+	IngredientB string
+	Severity    string
+	Description string
+}
+
+// LoadRules reads interaction rules from a CSV file with the columns
+// ingredient_a, ingredient_b, severity and description after a header row.
+// Lines starting with # are comments. An empty path means no rules.
+func LoadRules(path string) ([]*Rule, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("interaction rules %s: %w", path, err)
+	}
+
+	var rules []*Rule
+	for i, record := range records {
+		if i == 0 {
+			continue
+		}
+		rules = append(rules, &Rule{
+			IngredientA: normalize(record[0]),
+			IngredientB: normalize(record[1]),
+			Severity:    strings.TrimSpace(record[2]),
+			Description: strings.TrimSpace(record[3]),
+		})
+	}
+
+	return rules, nil
+}
+
+// Check returns the alerts raised by prescribing medications to a patient
+// with the given allergies who already takes current: allergies to any of
+// the prescribed medications, and interactions between them or with the
+// current ones.
+func Check(prescribed, current []*entity.Medication, allergies []*entity.Allergy, rules []*Rule) []*entity.PrescriptionAlert {
+	alerts := []*entity.PrescriptionAlert{}
+
+	for _, medication := range prescribed {
+		for _, allergy := range allergies {
+			substance := normalize(allergy.Substance)
+			if substance == normalize(medication.Name) || contains(medication.Ingredients, substance) {
+				message := fmt.Sprintf("Patient is allergic to %s", allergy.Substance)
+				if allergy.Reaction != "" {
+					message += " (" + allergy.Reaction + ")"
+				}
+				alerts = append(alerts, &entity.PrescriptionAlert{
+					Kind:        entity.AlertKindAllergy,
+					Severity:    "high",
+					Message:     message,
+					Medications: []string{medication.Name},
+				})
+			}
+		}
+	}
+
+	// every pair is checked once: prescribed ones among themselves and
+	// each against what the patient already takes
+	type pair struct{ a, b *entity.Medication }
+	var pairs []pair
+	for i, a := range prescribed {
+		for _, b := range prescribed[i+1:] {
+			pairs = append(pairs, pair{a, b})
+		}
+		for _, b := range current {
+			if b.ID != a.ID {
+				pairs = append(pairs, pair{a, b})
+			}
+		}
+	}
+
+	for _, p := range pairs {
+		for _, rule := range rules {
+			if (contains(p.a.Ingredients, rule.IngredientA) && contains(p.b.Ingredients, rule.IngredientB)) ||
+				(contains(p.a.Ingredients, rule.IngredientB) && contains(p.b.Ingredients, rule.IngredientA)) {
+				alerts = append(alerts, &entity.PrescriptionAlert{
+					Kind:        entity.AlertKindInteraction,
+					Severity:    rule.Severity,
+					Message:     rule.Description,
+					Medications: []string{p.a.Name, p.b.Name},
+				})
+			}
+		}
+	}
+
+	return alerts
+}
+
+// ParseCatalogue reads medications from a CSV file with a header row
+// naming the columns name, ingredients, form and strength in any order;
+// only name is required. Ingredients are separated by semicolons. Rows
+// that cannot be used are reported by line and left out.
+func ParseCatalogue(r io.Reader) ([]*entity.Medication, []*entity.ImportError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[normalize(name)] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, nil, errors.New("the header has no name column")
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var (
+		medications []*entity.Medication
+		problems    = []*entity.ImportError{}
+	)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			problems = append(problems, &entity.ImportError{Line: line, Message: err.Error()})
+			continue
+		}
+
+		medication := &entity.Medication{
+			Name:        field(record, "name"),
+			Ingredients: Ingredients(strings.Split(field(record, "ingredients"), ";")),
+			Form:        field(record, "form"),
+			Strength:    field(record, "strength"),
+			Active:      true,
+		}
+		if medication.Name == "" {
+			problems = append(problems, &entity.ImportError{Line: line, Message: "name is empty"})
+			continue
+		}
+		medications = append(medications, medication)
+	}
+
+	return medications, problems, nil
+}
+
+// Ingredients normalizes a list of active substances: lower case, without
+// blanks or duplicates, sorted.
+func Ingredients(ingredients []string) []string {
+	seen := make(map[string]bool, len(ingredients))
+	normalized := []string{}
+	for _, ingredient := range ingredients {
+		ingredient = normalize(ingredient)
+		if ingredient == "" || seen[ingredient] {
+			continue
+		}
+		seen[ingredient] = true
+		normalized = append(normalized, ingredient)
+	}
+	sort.Strings(normalized)
+
+	return normalized
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/config"
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/gmail"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/logger"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/signature"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/tenant"
+)
+
+const reminderTemplate = "./pkg/gmail/reminder.html"
+
+// Actions a patient can take from a reminder link.
+const (
+	ActionConfirm = "confirm"
+	ActionCancel  = "cancel"
+)
+
+// Scheduler mails patients reminders of their upcoming appointments.
+type Scheduler struct {
+	storage repo.StorageI
+	logger  logger.Logger
+	config  config.Config
+}
+
+func NewScheduler(storage repo.StorageI, logger logger.Logger, config config.Config) *Scheduler {
+	return &Scheduler{
+		storage: storage,
+		logger:  logger,
+		config:  config,
+	}
+}
+
+// Send mails every reminder that is due. Offsets are handled from the
+// shortest up and each only covers appointments beyond the next shorter
+// one, so a visit booked at short notice gets the reminder that fits it
+// rather than all of them at once.
+func (s *Scheduler) Send(ctx context.Context) error {
+	offsets := append([]time.Duration(nil), s.config.Reminder.Offsets...)
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+	var after time.Duration
+	for _, offset := range offsets {
+		reminders, err := s.storage.Reminder().Due(ctx, offset, after)
+		if err != nil {
+			return err
+		}
+
+		for _, reminder := range reminders {
+			s.send(ctx, reminder)
+		}
+		after = offset
+	}
+
+	return nil
+}
+
+// Run sends due reminders of every tenant on every interval until ctx is
+// cancelled.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := tenant.Each(ctx, s.storage.Tenant(), s.Send); err != nil {
+			s.logger.Error("sending reminders failed", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// send records the reminder before mailing it, so that another instance
+// or a restart does not send it again, and forgets it again when the mail
+// could not be sent.
+func (s *Scheduler) send(ctx context.Context, reminder *entity.Reminder) {
+	claimed, err := s.storage.Reminder().MarkSent(ctx, reminder.AppointmentID, reminder.Offset)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("reminder for appointment %d: recording failed", reminder.AppointmentID), logger.Error(err))
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	if err = s.notify(ctx, reminder); err != nil {
+		s.logger.Error(fmt.Sprintf("reminder for appointment %d: notification failed", reminder.AppointmentID), logger.Error(err))
+		if err = s.storage.Reminder().Unmark(ctx, reminder.AppointmentID, reminder.Offset); err != nil {
+			s.logger.Error(fmt.Sprintf("reminder for appointment %d: unmark failed", reminder.AppointmentID), logger.Error(err))
+		}
+	}
+}
+
+func (s *Scheduler) notify(ctx context.Context, reminder *entity.Reminder) error {
+	tenantID, _ := tenant.FromContext(ctx)
+	return gmail.SendTemplateGmail(reminder.PatientEmail, "Hospital\n", reminderTemplate, struct {
+		PatientName string
+		DoctorName  string
+		StartTime   string
+		ConfirmURL  string
+		CancelURL   string
+	}{
+		PatientName: reminder.PatientName,
+		DoctorName:  reminder.DoctorName,
+		StartTime:   reminder.StartTime.In(s.config.Location).Format("2006-01-02 15:04"),
+		ConfirmURL:  Link(s.config, tenantID, reminder.AppointmentID, ActionConfirm, reminder.StartTime),
+		CancelURL:   Link(s.config, tenantID, reminder.AppointmentID, ActionCancel, reminder.StartTime),
+	}, s.config)
+}
+
+// Link returns the signed link that performs action on the tenant's
+// appointment without logging in. It stops working at expires, normally
+// the start of the appointment.
+func Link(cfg config.Config, tenantID, appointmentID int64, action string, expires time.Time) string {
+	return fmt.Sprintf("%s/reminder/%d/%s?tenant=%d&expires=%d&sig=%s",
+		cfg.Server.PublicURL, appointmentID, action, tenantID, expires.Unix(),
+		signature.Sign(cfg.Token.LinkKey, linkPayload(tenantID, appointmentID, action), expires))
+}
+
+// VerifyLink checks a link made by Link.
+func VerifyLink(cfg config.Config, tenantID, appointmentID int64, action string, expires time.Time, sig string) error {
+	return signature.Verify(cfg.Token.LinkKey, linkPayload(tenantID, appointmentID, action), expires, sig)
+}
+
+func linkPayload(tenantID, appointmentID int64, action string) string {
+	return "reminder:" + strconv.FormatInt(tenantID, 10) + ":" + strconv.FormatInt(appointmentID, 10) + ":" + action
+}
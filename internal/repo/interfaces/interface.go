@@ -21,6 +21,7 @@ type User interface {
 type Doctor interface {
 	Create(context.Context, *entity.Doctor) (*entity.Doctor, error)
 	Get(context.Context, string) (*entity.Doctor, error)
+	GetByUserID(context.Context, string) (*entity.Doctor, error)
 	Update(context.Context, *entity.Doctor) (*entity.Doctor, error)
 	Delete(context.Context, string) error
 	List(context.Context, *entity.ListRequest) (*entity.ListDoctorRes, error)
@@ -29,9 +30,158 @@ type Doctor interface {
 type Appointment interface {
 	CreateAppointment(ctx context.Context, appointment *entity.Appointment) (*entity.Appointment, error)
 	UpdateAppointment(ctx context.Context, appointmentID int, newTime time.Time) error
-	DeleteAppointment(ctx context.Context, appointmentID int) error
 	GetAppointment(ctx context.Context, appointmentID int) (*entity.Appointment, error)
-	ListAppointments(ctx context.Context, page, limit int) ([]*entity.Appointment, int, error)
+	TransitionAppointment(ctx context.Context, appointmentID int, rule entity.AppointmentTransitionRule, changedBy, reason string) (*entity.Appointment, error)
+	ListAppointmentTransitions(ctx context.Context, appointmentID int) ([]*entity.AppointmentTransition, error)
+	ListAppointments(ctx context.Context, filter *entity.AppointmentFilter) ([]*entity.Appointment, int, error)
+	DoctorAgenda(ctx context.Context, filter *entity.AppointmentFilter) ([]*entity.AgendaEntry, error)
 	GetAvailability(ctx context.Context, availabilityID int) (*entity.Availability, error)
 	ListAvailabilities(ctx context.Context, page, limit int) ([]*entity.Availability, int, error)
+	HoldSlots(ctx context.Context, hold *entity.SlotHold) error
+	ReleaseSlotHold(ctx context.Context, token string) error
+	SearchAvailabilities(ctx context.Context, search *entity.AvailabilitySearch) ([]*entity.AvailableSlot, int, error)
+	CreateAvailability(ctx context.Context, availability *entity.Availability) (*entity.Availability, error)
+	CreateAvailabilities(ctx context.Context, availabilities []*entity.Availability) ([]*entity.Availability, error)
+	UpdateAvailability(ctx context.Context, availability *entity.Availability) (*entity.Availability, error)
+	DeleteAvailability(ctx context.Context, availabilityID int) error
+	FillAvailabilities(ctx context.Context, availabilities []*entity.Availability) (int, error)
+	ReassignAppointment(ctx context.Context, appointmentID int, doctorID string) (*entity.Appointment, error)
+	CreateSeries(ctx context.Context, series *entity.AppointmentSeries, starts []time.Time) (*entity.AppointmentSeriesResult, error)
+	GetSeries(ctx context.Context, seriesID int64) (*entity.AppointmentSeries, error)
+	RescheduleSeries(ctx context.Context, occurrences []*entity.SeriesOccurrence, allOrNothing bool) error
+}
+
+type Schedule interface {
+	Upsert(ctx context.Context, schedule *entity.Schedule) (*entity.Schedule, error)
+	Get(ctx context.Context, doctorID string) (*entity.Schedule, error)
+	Delete(ctx context.Context, doctorID string) error
+	List(ctx context.Context) ([]*entity.Schedule, error)
+}
+
+type AppointmentType interface {
+	Create(ctx context.Context, appointmentType *entity.AppointmentType) (*entity.AppointmentType, error)
+	Get(ctx context.Context, appointmentTypeID int64) (*entity.AppointmentType, error)
+	Update(ctx context.Context, appointmentType *entity.AppointmentType) (*entity.AppointmentType, error)
+	Delete(ctx context.Context, appointmentTypeID int64) error
+	List(ctx context.Context) ([]*entity.AppointmentType, error)
+	SetDoctorOverride(ctx context.Context, override *entity.DoctorAppointmentType) error
+	DeleteDoctorOverride(ctx context.Context, doctorID string, appointmentTypeID int64) error
+	Resolve(ctx context.Context, doctorID string, appointmentTypeID int64) (*entity.AppointmentType, error)
+}
+
+type CancellationPolicy interface {
+	Get(ctx context.Context, appointmentTypeID int64) (*entity.CancellationPolicy, error)
+	Upsert(ctx context.Context, policy *entity.CancellationPolicy) (*entity.CancellationPolicy, error)
+	List(ctx context.Context) ([]*entity.CancellationPolicy, error)
+	Delete(ctx context.Context, appointmentTypeID int64) error
+}
+
+type Waitlist interface {
+	Create(ctx context.Context, entry *entity.WaitlistEntry) (*entity.WaitlistEntry, error)
+	Get(ctx context.Context, id int64) (*entity.WaitlistEntry, error)
+	List(ctx context.Context, params map[string]string) ([]*entity.WaitlistEntry, error)
+	Cancel(ctx context.Context, id int64) error
+	Accept(ctx context.Context, id int64, appointment *entity.Appointment, startTime time.Time) (*entity.Appointment, error)
+	OfferNext(ctx context.Context, doctorID string, hold time.Duration) ([]*entity.WaitlistEntry, error)
+	ExpireOffers(ctx context.Context) ([]string, error)
+	WaitingDoctors(ctx context.Context) ([]string, error)
+}
+
+type CalendarFeed interface {
+	Save(ctx context.Context, feed *entity.CalendarFeed) (*entity.CalendarFeed, error)
+	GetByToken(ctx context.Context, token string) (*entity.CalendarFeed, error)
+	Delete(ctx context.Context, userID string) error
+}
+
+type BusyTime interface {
+	Import(ctx context.Context, doctorID, source string, busyTimes []*entity.BusyTime) (*entity.BusyTimeImport, error)
+	List(ctx context.Context, doctorID string, from, to time.Time) ([]*entity.BusyTime, error)
+	DeleteSource(ctx context.Context, doctorID, source string) error
+}
+
+type Reminder interface {
+	Due(ctx context.Context, offset, after time.Duration) ([]*entity.Reminder, error)
+	MarkSent(ctx context.Context, appointmentID int64, offset time.Duration) (bool, error)
+	Unmark(ctx context.Context, appointmentID int64, offset time.Duration) error
+}
+
+type DoctorLeave interface {
+	Create(ctx context.Context, leave *entity.DoctorLeave) (*entity.DoctorLeave, error)
+	Get(ctx context.Context, id int64) (*entity.DoctorLeave, error)
+	List(ctx context.Context, doctorID string, from time.Time) ([]*entity.DoctorLeave, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type Closure interface {
+	Create(ctx context.Context, closure *entity.Closure) (*entity.Closure, error)
+	List(ctx context.Context, from time.Time, branchID int64) ([]*entity.Closure, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type Resource interface {
+	Create(ctx context.Context, resource *entity.Resource) (*entity.Resource, error)
+	Get(ctx context.Context, resourceID int64) (*entity.Resource, error)
+	Update(ctx context.Context, resource *entity.Resource) (*entity.Resource, error)
+	Delete(ctx context.Context, resourceID int64) error
+	List(ctx context.Context, kind, category string) ([]*entity.Resource, error)
+	Bookings(ctx context.Context, resourceID int64, from, to time.Time) ([]*entity.ResourceBooking, error)
+	AppointmentBookings(ctx context.Context, appointmentID int64) ([]*entity.ResourceBooking, error)
+}
+
+type Branch interface {
+	Create(ctx context.Context, branch *entity.Branch) (*entity.Branch, error)
+	Get(ctx context.Context, branchID int64) (*entity.Branch, error)
+	Update(ctx context.Context, branch *entity.Branch) (*entity.Branch, error)
+	Delete(ctx context.Context, branchID int64) error
+	List(ctx context.Context) ([]*entity.Branch, error)
+	SetDoctorBranches(ctx context.Context, doctorID string, branchIDs []int64) error
+}
+
+type Tenant interface {
+	List(ctx context.Context) ([]*entity.Tenant, error)
+}
+
+type Encounter interface {
+	Save(ctx context.Context, encounter *entity.Encounter) (*entity.Encounter, error)
+	GetByAppointment(ctx context.Context, appointmentID int64) (*entity.Encounter, error)
+	Sign(ctx context.Context, appointmentID int64, signedBy string) (*entity.Encounter, error)
+	AddAddendum(ctx context.Context, appointmentID int64, addendum *entity.Addendum) (*entity.Addendum, error)
+	Summaries(ctx context.Context, patientID string) ([]*entity.EncounterSummary, error)
+}
+
+type Medication interface {
+	Create(ctx context.Context, medication *entity.Medication) (*entity.Medication, error)
+	Update(ctx context.Context, medication *entity.Medication) (*entity.Medication, error)
+	GetMany(ctx context.Context, medicationIDs []int64) ([]*entity.Medication, error)
+	List(ctx context.Context, search string, activeOnly bool) ([]*entity.Medication, error)
+	Import(ctx context.Context, medications []*entity.Medication) (created, updated int, err error)
+}
+
+type Allergy interface {
+	Create(ctx context.Context, allergy *entity.Allergy) (*entity.Allergy, error)
+	List(ctx context.Context, patientID string) ([]*entity.Allergy, error)
+	Delete(ctx context.Context, patientID string, allergyID int64) error
+}
+
+type Prescription interface {
+	Create(ctx context.Context, prescription *entity.Prescription) (*entity.Prescription, error)
+	Get(ctx context.Context, prescriptionID int64) (*entity.Prescription, error)
+	List(ctx context.Context, filter *entity.PrescriptionFilter) ([]*entity.Prescription, error)
+	ActiveMedications(ctx context.Context, patientID string) ([]*entity.Medication, error)
+}
+
+type LabOrder interface {
+	Create(ctx context.Context, order *entity.LabOrder) (*entity.LabOrder, error)
+	Get(ctx context.Context, orderID int64) (*entity.LabOrder, error)
+	List(ctx context.Context, filter *entity.LabOrderFilter) ([]*entity.LabOrder, error)
+	RecordResults(ctx context.Context, orderID int64, results []*entity.LabResult, report, resultedBy string) (*entity.LabOrder, error)
+	Release(ctx context.Context, orderID int64, releasedBy string) (*entity.LabOrder, error)
+	Cancel(ctx context.Context, orderID int64) (*entity.LabOrder, error)
+}
+
+type Attachment interface {
+	Create(ctx context.Context, attachment *entity.Attachment) (*entity.Attachment, error)
+	Get(ctx context.Context, attachmentID int64) (*entity.Attachment, error)
+	List(ctx context.Context, filter *entity.AttachmentFilter) ([]*entity.Attachment, error)
+	Delete(ctx context.Context, attachmentID int64) error
 }
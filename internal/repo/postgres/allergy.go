@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+)
+
+const allergyTableName = "patient_allergies"
+
+type allergyRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewAllergyRepo(db *postgres.PostgresDB) interfaces.Allergy {
+	return &allergyRepo{
+		db:        db,
+		tableName: allergyTableName,
+	}
+}
+
+func (p *allergyRepo) Create(ctx context.Context, allergy *entity.Allergy) (*entity.Allergy, error) {
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(map[string]any{
+			"patient_id": allergy.PatientID,
+			"substance":  allergy.Substance,
+			"reaction":   allergy.Reaction,
+			"created_by": allergy.CreatedBy,
+		}).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&allergy.ID, &allergy.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return allergy, nil
+}
+
+func (p *allergyRepo) List(ctx context.Context, patientID string) ([]*entity.Allergy, error) {
+	query, args, err := p.db.Sq.Builder.
+		Select("id", "patient_id", "substance", "reaction", "COALESCE(created_by::text, '')", "created_at").
+		From(p.tableName).
+		Where(p.db.Sq.Equal("patient_id", patientID)).
+		OrderBy("substance").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	allergies := []*entity.Allergy{}
+	for rows.Next() {
+		var allergy entity.Allergy
+		if err = rows.Scan(
+			&allergy.ID,
+			&allergy.PatientID,
+			&allergy.Substance,
+			&allergy.Reaction,
+			&allergy.CreatedBy,
+			&allergy.CreatedAt,
+		); err != nil {
+			return nil, p.db.Error(err)
+		}
+		allergies = append(allergies, &allergy)
+	}
+
+	return allergies, rows.Err()
+}
+
+func (p *allergyRepo) Delete(ctx context.Context, patientID string, allergyID int64) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(p.db.Sq.Equal("id", allergyID)).
+		Where(p.db.Sq.Equal("patient_id", patientID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" delete")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return entity.ErrorNotFound
+	}
+
+	return nil
+}
@@ -2,19 +2,28 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Abdulazizxoshimov/Hospital/entity"
 	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
 	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
-	"github.com/k0kubun/pp"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 )
 
 const (
 	tableNameAppointment  = "appointments"
 	tableNameAvailability = "doctor_availability"
+	tableNameStatusLog    = "appointment_status_history"
+
+	// defaultAppointmentDuration is used when a booking names no appointment type
+	defaultAppointmentDuration = time.Hour
 )
 
 type appointmentRepo struct {
@@ -31,358 +40,960 @@ func NewAppointmentRepo(db *postgres.PostgresDB) interfaces.Appointment {
 	}
 }
 
-func (p *appointmentRepo) CreateAppointment(ctx context.Context, appointment *entity.Appointment) (*entity.Appointment, error) {
-	tx, err := p.db.Begin(ctx)
-	if err != nil {
-		return nil, err
+// appointmentColumns lists the columns scanAppointment reads, qualified
+// with alias when the query joins other tables.
+func appointmentColumns(alias string) []string {
+	columns := []string{
+		"id",
+		"doctor_id",
+		"patient_id",
+		"appointment_type_id",
+		"appointment_time",
+		"start_time",
+		"end_time",
+		"status",
+		"reschedule_count",
+		"series_id",
+		"branch_id",
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback(ctx)
+	if alias != "" {
+		for i, column := range columns {
+			columns[i] = alias + "." + column
 		}
-	}()
-
-	startTimeStr, ok := appointment.Appointment_time["start_time"].(string)
-	if !ok {
-		return nil, fmt.Errorf("invalid appointment time format, expected string")
 	}
-	pp.Println(startTimeStr)
+	return columns
+}
 
-	startTime, err := time.Parse("2006-01-02T15:04:05Z", startTimeStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid time format: %v", err)
+func (p *appointmentRepo) appointmentSelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(appointmentColumns("")...).
+		From(p.tableNameAppointment)
+}
+
+// scanAppointment reads the appointmentColumns, followed by any extra
+// columns the query selected into extra.
+func scanAppointment(row pgx.Row, appointment *entity.Appointment, extra ...interface{}) error {
+	var (
+		appointmentTypeID sql.NullInt64
+		status            sql.NullString
+		seriesID          sql.NullInt64
+		branchID          sql.NullInt64
+	)
+
+	if err := row.Scan(append([]interface{}{
+		&appointment.ID,
+		&appointment.DoctorID,
+		&appointment.UserID,
+		&appointmentTypeID,
+		&appointment.Appointment_time,
+		&appointment.StartTime,
+		&appointment.EndTime,
+		&status,
+		&appointment.RescheduleCount,
+		&seriesID,
+		&branchID,
+	}, extra...)...); err != nil {
+		return err
 	}
+	appointment.AppointmentTypeID = appointmentTypeID.Int64
+	appointment.Status = status.String
+	appointment.SeriesID = seriesID.Int64
+	appointment.BranchID = branchID.Int64
 
-	appointmentEnd := startTime.Add(time.Hour)
-	pp.Println("hellolololol")
-	var count int
-	query, args, err := p.db.Sq.Builder.
-		Select("COUNT(*)").
-		From(p.tableNameAvailability).
-		Where("doctor_id = ?", appointment.DoctorID).
-		Where("available_date = ?", startTime.Format("2006-01-02")).       // YYYY-MM-DD
-		Where("start_time <= ?", startTime.Format("2006-01-02 15:04:05")). // YYYY-MM-DD HH:MM:SS
-		Where("end_time > ?", startTime.Format("2006-01-02 15:04:05")).
-		Where("is_booked = ?", false).
-		ToSql()
+	return nil
+}
 
-	if err != nil {
-		return nil, err
+// querier is satisfied by both the pool and a transaction, for reads that
+// are shared between transactional and plain lookups.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// resolveDuration returns the visit length and trailing buffer for the
+// appointment type, preferring the doctor's own override when present.
+func (p *appointmentRepo) resolveDuration(ctx context.Context, q querier, doctorID string, appointmentTypeID int64) (time.Duration, time.Duration, error) {
+	if appointmentTypeID == 0 {
+		return defaultAppointmentDuration, 0, nil
 	}
 
-	err = tx.QueryRow(ctx, query, args...).Scan(&count)
+	query, args, err := appointmentTypeResolveQuery(&p.db, doctorID, appointmentTypeID)
 	if err != nil {
-		return nil, err
+		return 0, 0, err
 	}
 
-	if count == 1 {
-		return nil, fmt.Errorf("doctor is not available at this time")
+	var appointmentType entity.AppointmentType
+	if err = scanAppointmentType(q.QueryRow(ctx, query, args...), &appointmentType); err != nil {
+		return 0, 0, p.db.Error(err)
 	}
-	pp.Println("hellolololol")
 
-	appointmentTimes := map[string]interface{}{
-		"available_date": startTime.Format("2006-01-02"),
-		"start_time":     startTime.Format("15:04:05"),
-		"end_time":       appointmentEnd.Format("15:04:05"),
-		"is_booked":      true,
+	return time.Duration(appointmentType.DurationMinutes) * time.Minute,
+		time.Duration(appointmentType.BufferMinutes) * time.Minute,
+		nil
+}
+
+// coveringSlots returns the IDs of the doctor's free slots covering
+// [start, end). The slots must be contiguous: a gap or an already booked
+// slot anywhere in the window means the doctor is not available for the
+// whole visit. Slots held for a waitlist offer or a checkout only count as
+// free for the patient they are held for, until the hold expires, and slots the
+// doctor's external busy times overlap never do. A visit is held at a
+// single branch, so slots at another branch break the cover too.
+func (p *appointmentRepo) coveringSlots(ctx context.Context, q querier, lock bool, doctorID, patientID string, start, end time.Time) ([]int64, error) {
+	queryBuilder := p.availabilitySelectQueryPrefix().
+		Where(p.db.Sq.Equal("doctor_id", doctorID)).
+		Where(p.db.Sq.Lt("start_time", end)).
+		Where(p.db.Sq.Gt("end_time", start)).
+		Where("is_booked IS NOT TRUE").
+		Where(notBusy(p.tableNameAvailability)).
+		Where("(held_until IS NULL OR held_until <= now() OR held_by IN (SELECT id FROM "+waitlistTableName+" WHERE patient_id = ?) OR hold_patient_id = ?)", patientID, patientID).
+		OrderBy("start_time")
+	if lock {
+		queryBuilder = queryBuilder.Suffix("FOR UPDATE")
 	}
 
-	appointmentTimesJSON, err := json.Marshal(appointmentTimes)
+	query, args, err := queryBuilder.ToSql()
 	if err != nil {
-		return nil, err
+		return nil, p.db.ErrSQLBuild(err, p.tableNameAvailability+" covering slots")
 	}
 
-	data := map[string]any{
-		"doctor_id":        appointment.DoctorID,
-		"patient_id":       appointment.UserID,
-		"appointment_time": json.RawMessage(appointmentTimesJSON),
-		"start_time":       startTime.Format("2006-01-02 15:04:05"),
-		"status":           "scheduled",
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
 	}
+	defer rows.Close()
 
-	query, args, err = p.db.Sq.Builder.Insert(p.tableNameAppointment).SetMap(data).ToSql()
-	if err != nil {
-		return nil, err
+	var (
+		ids      []int64
+		covered  = start
+		branchID int64
+	)
+	for rows.Next() {
+		var slot entity.Availability
+		if err = scanAvailability(rows, &slot); err != nil {
+			return nil, p.db.Error(err)
+		}
+		if slot.StartTime.After(covered) || len(ids) != 0 && slot.BranchID != branchID {
+			break
+		}
+		branchID = slot.BranchID
+		ids = append(ids, slot.ID)
+		covered = slot.EndTime
+	}
+	if err = rows.Err(); err != nil {
+		return nil, p.db.Error(err)
 	}
 
-	_, err = tx.Exec(ctx, query, args...)
+	if len(ids) == 0 || covered.Before(end) {
+		return nil, entity.ErrorSlotUnavailable
+	}
+
+	return ids, nil
+}
+
+// reserveSlots marks the doctor's free slots covering [start, end) as booked.
+//
+// The slots are read FOR UPDATE, so a concurrent booking of the same slot
+// waits for this transaction and then no longer sees the slot as free. The
+// appointments_no_overlap exclusion constraint backs this up in the schema.
+// Slots that were free until a concurrent booking took them while this one
+// waited are reported as ErrorSlotTaken rather than ErrorSlotUnavailable,
+// slots held for another patient's checkout as ErrorSlotHeld.
+func (p *appointmentRepo) reserveSlots(ctx context.Context, tx pgx.Tx, doctorID, patientID string, start, end time.Time) error {
+	if _, err := p.coveringSlots(ctx, tx, false, doctorID, patientID, start, end); err != nil {
+		return p.heldError(ctx, tx, err, doctorID, patientID, start, end)
+	}
+
+	ids, err := p.coveringSlots(ctx, tx, true, doctorID, patientID, start, end)
+	if errors.Is(err, entity.ErrorSlotUnavailable) {
+		return entity.ErrorSlotTaken
+	}
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	updateQuery, updateArgs, err := p.db.Sq.Builder.
+	query, args, err := p.db.Sq.Builder.
 		Update(p.tableNameAvailability).
 		Set("is_booked", true).
-		Where("doctor_id = ?", appointment.DoctorID).
-		Where("available_date = ?", startTime.Format("2006-01-02")).
-		Where("start_time = ?", startTime.Format("2006-01-02 15:04:05")).
+		Set("held_by", nil).
+		Set("held_until", nil).
+		Set("hold_token", nil).
+		Set("hold_patient_id", nil).
+		Where(p.db.Sq.Equal("id", ids)).
 		ToSql()
 	if err != nil {
-		return nil, err
+		return p.db.ErrSQLBuild(err, p.tableNameAvailability+" reserve")
 	}
 
+	_, err = tx.Exec(ctx, query, args...)
+	return err
+}
+
+// heldError turns ErrorSlotUnavailable into ErrorSlotHeld when the slots
+// of [start, end) are held for another patient's checkout.
+func (p *appointmentRepo) heldError(ctx context.Context, q querier, err error, doctorID, patientID string, start, end time.Time) error {
+	if !errors.Is(err, entity.ErrorSlotUnavailable) {
+		return err
+	}
 
-	_, err = tx.Exec(ctx, updateQuery, updateArgs...)
-	if err != nil {
-		return nil, err
+	query, args, sqlErr := p.db.Sq.Builder.
+		Select().
+		Column(squirrel.Expr("EXISTS (SELECT 1 FROM "+p.tableNameAvailability+
+			" WHERE doctor_id = ? AND start_time < ? AND end_time > ?"+
+			" AND hold_token IS NOT NULL AND held_until > now() AND hold_patient_id IS DISTINCT FROM ?)",
+			doctorID, end, start, patientID)).
+		ToSql()
+	if sqlErr != nil {
+		return p.db.ErrSQLBuild(sqlErr, p.tableNameAvailability+" held")
 	}
 
-	err = tx.Commit(ctx)
-	if err != nil {
-		return nil, err
+	var held bool
+	if sqlErr = q.QueryRow(ctx, query, args...).Scan(&held); sqlErr != nil {
+		return p.db.Error(sqlErr)
+	}
+	if held {
+		return entity.ErrorSlotHeld
 	}
 
-	return appointment, nil
+	return err
 }
 
-func (p *appointmentRepo) UpdateAppointment(ctx context.Context, appointmentID int, newTime time.Time) error {
-	if time.Until(newTime) < 24*time.Hour {
-		return fmt.Errorf("cannot update appointment within 24 hours of the scheduled time")
-	}
-
+// HoldSlots holds the free slots a visit of the hold's appointment type at
+// its StartTime would use for the hold's patient until its ExpiresAt,
+// filling in the slot IDs and when the visit ends. Every booking path
+// leaves the slots alone until then, except for the patient's own.
+func (p *appointmentRepo) HoldSlots(ctx context.Context, hold *entity.SlotHold) error {
 	tx, err := p.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	var oldAppointmentTimeJSON string
-	query, args, err := p.db.Sq.Builder.
-		Select("appointment_time").
-		From(tableNameAppointment).
-		Where("id = ?", appointmentID).
-		ToSql()
+	duration, buffer, err := p.resolveDuration(ctx, tx, hold.DoctorID, hold.AppointmentTypeID)
 	if err != nil {
 		return err
 	}
 
-	err = tx.QueryRow(ctx, query, args...).Scan(&oldAppointmentTimeJSON)
+	end := hold.StartTime.Add(duration)
+	ids, err := p.coveringSlots(ctx, tx, true, hold.DoctorID, hold.PatientID, hold.StartTime, end.Add(buffer))
 	if err != nil {
-		return err
+		return p.heldError(ctx, tx, err, hold.DoctorID, hold.PatientID, hold.StartTime, end.Add(buffer))
 	}
 
-	var oldAvailability entity.Availability
-	err = json.Unmarshal([]byte(oldAppointmentTimeJSON), &oldAvailability)
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableNameAvailability).
+		Set("held_by", nil).
+		Set("held_until", hold.ExpiresAt).
+		Set("hold_token", hold.Token).
+		Set("hold_patient_id", hold.PatientID).
+		Where(p.db.Sq.Equal("id", ids)).
+		ToSql()
 	if err != nil {
-		return err
+		return p.db.ErrSQLBuild(err, p.tableNameAvailability+" hold")
 	}
 
-	updateQuery, updateArgs, err := p.db.Sq.Builder.
-		Update(tableNameAvailability).
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return p.db.Error(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return bookingError(p.db.Error(err))
+	}
+
+	hold.AvailabilityIDs = ids
+	hold.EndTime = end
+	return nil
+}
+
+// ReleaseSlotHold gives back the slots still held under token.
+func (p *appointmentRepo) ReleaseSlotHold(ctx context.Context, token string) error {
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableNameAvailability).
+		Set("held_until", nil).
+		Set("hold_token", nil).
+		Set("hold_patient_id", nil).
+		Where(p.db.Sq.Equal("hold_token", token)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableNameAvailability+" release hold")
+	}
+
+	_, err = p.db.Exec(ctx, query, args...)
+	return p.db.Error(err)
+}
+
+// releaseSlots frees every slot of the doctor overlapping [start, end).
+func (p *appointmentRepo) releaseSlots(ctx context.Context, tx pgx.Tx, doctorID string, start, end time.Time) error {
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableNameAvailability).
 		Set("is_booked", false).
-		Where("available_date = ?", oldAvailability.AvailableDate).
-		Where("start_time = ?", oldAvailability.StartTime).
+		Where(p.db.Sq.Equal("doctor_id", doctorID)).
+		Where(p.db.Sq.Lt("start_time", end)).
+		Where(p.db.Sq.Gt("end_time", start)).
 		ToSql()
 	if err != nil {
-		return err
+		return p.db.ErrSQLBuild(err, p.tableNameAvailability+" release")
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	return err
+}
+
+// appointmentTimeJSON renders the visit window as wall clock values in the
+// hospital zone.
+func (p *appointmentRepo) appointmentTimeJSON(start, end time.Time) ([]byte, error) {
+	start, end = start.In(p.db.Location), end.In(p.db.Location)
+
+	return json.Marshal(map[string]interface{}{
+		"available_date": start.Format("2006-01-02"),
+		"start_time":     start.Format("15:04:05"),
+		"end_time":       end.Format("15:04:05"),
+		"is_booked":      true,
+	})
+}
+
+// bookingError reports constraint violations, serialization failures and
+// deadlocks hit while writing an appointment as the slot having been taken
+// by a concurrent booking.
+func bookingError(err error) error {
+	if errors.Is(err, entity.ErrorConflict) || errors.Is(err, entity.ErrorSlotOverlap) {
+		return entity.ErrorSlotTaken
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01") {
+		return entity.ErrorSlotTaken
+	}
+
+	return err
+}
+
+func (p *appointmentRepo) CreateAppointment(ctx context.Context, appointment *entity.Appointment) (*entity.Appointment, error) {
+	startTimeStr, ok := appointment.Appointment_time["start_time"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid appointment time format, expected string")
 	}
 
-	_, err = tx.Exec(ctx, updateQuery, updateArgs...)
+	startTime, err := time.Parse(time.RFC3339, startTimeStr)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("invalid time format, expected RFC 3339 with offset: %v", err)
+	}
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = p.insertAppointment(ctx, tx, appointment, startTime); err != nil {
+		return nil, err
 	}
 
-	newAvailability := entity.Availability{
-		AvailableDate: newTime,
-		StartTime:     newTime,
-		EndTime:       newTime.Add(time.Hour),
-		IsBooked:      true,
+	if err = tx.Commit(ctx); err != nil {
+		return nil, bookingError(p.db.Error(err))
 	}
 
-	newAvailabilityJSON, err := json.Marshal(newAvailability)
+	return appointment, nil
+}
+
+// insertAppointment reserves the doctor's slots for a visit at startTime
+// and writes the appointment, filling in its ID, times and status.
+func (p *appointmentRepo) insertAppointment(ctx context.Context, tx pgx.Tx, appointment *entity.Appointment, startTime time.Time) error {
+	duration, buffer, err := p.resolveDuration(ctx, tx, appointment.DoctorID, appointment.AppointmentTypeID)
 	if err != nil {
 		return err
 	}
+	appointmentEnd := startTime.Add(duration)
 
-	updateQuery, updateArgs, err = p.db.Sq.Builder.
-		Update(tableNameAppointment).
-		Set("appointment_time", string(newAvailabilityJSON)).
-		Where("id = ?", appointmentID).
-		ToSql()
-	if err != nil {
+	if err = p.reserveSlots(ctx, tx, appointment.DoctorID, appointment.UserID, startTime, appointmentEnd.Add(buffer)); err != nil {
 		return err
 	}
 
-	_, err = tx.Exec(ctx, updateQuery, updateArgs...)
+	appointmentTimesJSON, err := p.appointmentTimeJSON(startTime, appointmentEnd)
 	if err != nil {
 		return err
 	}
 
-	updateQuery, updateArgs, err = p.db.Sq.Builder.
-		Update(tableNameAvailability).
-		Set("is_booked", true).
-		Where("available_date = ?", newAvailability.AvailableDate).
-		Where("start_time = ?", newAvailability.StartTime).
+	data := map[string]any{
+		"doctor_id":        appointment.DoctorID,
+		"patient_id":       appointment.UserID,
+		"appointment_time": json.RawMessage(appointmentTimesJSON),
+		"start_time":       startTime,
+		"end_time":         appointmentEnd,
+		"buffer_minutes":   int(buffer / time.Minute),
+		"status":           entity.AppointmentStatusScheduled,
+	}
+	if appointment.AppointmentTypeID != 0 {
+		data["appointment_type_id"] = appointment.AppointmentTypeID
+	}
+	if appointment.SeriesID != 0 {
+		data["series_id"] = appointment.SeriesID
+	}
+	data["branch_id"] = p.slotBranch(appointment.DoctorID, startTime)
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableNameAppointment).
+		SetMap(data).
+		Suffix("RETURNING id, COALESCE(branch_id, 0)").
 		ToSql()
 	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableNameAppointment+" create")
+	}
+
+	if err = tx.QueryRow(ctx, query, args...).Scan(&appointment.ID, &appointment.BranchID); err != nil {
+		return bookingError(p.db.Error(err))
+	}
+
+	if err = p.reserveResources(ctx, tx, appointment.ID, appointment.AppointmentTypeID, startTime, appointmentEnd.Add(buffer)); err != nil {
 		return err
 	}
 
-	_, err = tx.Exec(ctx, updateQuery, updateArgs...)
-	if err != nil {
+	if err = p.recordTransition(ctx, tx, &entity.AppointmentTransition{
+		AppointmentID: appointment.ID,
+		ToStatus:      entity.AppointmentStatusScheduled,
+		ChangedBy:     appointment.UserID,
+	}); err != nil {
 		return err
 	}
 
-	return tx.Commit(ctx)
+	appointment.StartTime = startTime
+	appointment.EndTime = appointmentEnd
+	appointment.Status = entity.AppointmentStatusScheduled
+	return json.Unmarshal(appointmentTimesJSON, &appointment.Appointment_time)
+}
+
+// slotBranch is the branch of the doctor's slot starting the visit at
+// start, which the visit inherits.
+func (p *appointmentRepo) slotBranch(doctorID string, start time.Time) squirrel.Sqlizer {
+	return squirrel.Expr("(SELECT branch_id FROM "+p.tableNameAvailability+
+		" WHERE doctor_id = ? AND start_time <= ? AND end_time > ? LIMIT 1)", doctorID, start, start)
+}
+
+// appointmentWindow is the part of an appointment row needed to move or
+// free the availability it consumes.
+type appointmentWindow struct {
+	DoctorID          string
+	PatientID         string
+	AppointmentTypeID int64
+	StartTime         time.Time
+	EndTime           time.Time
+	BufferMinutes     int
+	Status            string
+}
+
+func (w *appointmentWindow) reservedUntil() time.Time {
+	return w.EndTime.Add(time.Duration(w.BufferMinutes) * time.Minute)
 }
 
-func (p *appointmentRepo) DeleteAppointment(ctx context.Context, appointmentID int) error {
-	var appointmentTimeJSON string
+func (p *appointmentRepo) getAppointmentWindow(ctx context.Context, tx pgx.Tx, appointmentID int) (*appointmentWindow, error) {
 	query, args, err := p.db.Sq.Builder.
-		Select("appointment_time").
-		From(tableNameAppointment).
-		Where("id = ?", appointmentID).
+		Select("doctor_id", "patient_id", "COALESCE(appointment_type_id, 0)", "start_time", "end_time", "buffer_minutes", "COALESCE(status, '')").
+		From(p.tableNameAppointment).
+		Where(p.db.Sq.Equal("id", appointmentID)).
+		Suffix("FOR UPDATE").
 		ToSql()
 	if err != nil {
-		return err
+		return nil, p.db.ErrSQLBuild(err, p.tableNameAppointment+" window")
+	}
+
+	var window appointmentWindow
+	if err = tx.QueryRow(ctx, query, args...).Scan(
+		&window.DoctorID,
+		&window.PatientID,
+		&window.AppointmentTypeID,
+		&window.StartTime,
+		&window.EndTime,
+		&window.BufferMinutes,
+		&window.Status,
+	); err != nil {
+		return nil, p.db.Error(err)
 	}
 
-	err = p.db.QueryRow(ctx, query, args...).Scan(&appointmentTimeJSON)
+	return &window, nil
+}
+
+// UpdateAppointment moves a live appointment to newTime, keeping its
+// length and buffer. Policy checks (cutoff, reschedule limit) are the
+// caller's job; the move itself only has to find free availability.
+func (p *appointmentRepo) UpdateAppointment(ctx context.Context, appointmentID int, newTime time.Time) error {
+	tx, err := p.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
+
+	if err = p.moveAppointment(ctx, tx, appointmentID, newTime); err != nil {
+		return err
+	}
+
+	return bookingError(p.db.Error(tx.Commit(ctx)))
+}
 
-	var appointmentTime entity.Availability
-	err = json.Unmarshal([]byte(appointmentTimeJSON), &appointmentTime)
+// moveAppointment releases the slots and resources of a live appointment,
+// reserves the ones at newTime and resets the reminders already sent for
+// it.
+func (p *appointmentRepo) moveAppointment(ctx context.Context, tx pgx.Tx, appointmentID int, newTime time.Time) error {
+	window, err := p.getAppointmentWindow(ctx, tx, appointmentID)
 	if err != nil {
 		return err
 	}
-	if time.Until(appointmentTime.StartTime) < 24*time.Hour {
-		return fmt.Errorf("cannot cancel an appointment within 24 hours of the scheduled time")
+	if window.Status != entity.AppointmentStatusScheduled && window.Status != entity.AppointmentStatusConfirmed {
+		return entity.ErrorIllegalTransition
 	}
 
-	tx, err := p.db.Begin(ctx)
-	if err != nil {
+	if err = p.releaseSlots(ctx, tx, window.DoctorID, window.StartTime, window.reservedUntil()); err != nil {
+		return err
+	}
+	if err = p.releaseResources(ctx, tx, int64(appointmentID)); err != nil {
 		return err
 	}
-	defer tx.Rollback(ctx)
 
-	query, args, err = p.db.Sq.Builder.
-		Delete(tableNameAppointment).
-		Where("id = ?", appointmentID).
-		ToSql()
-	if err != nil {
+	duration := window.EndTime.Sub(window.StartTime)
+	newEnd := newTime.Add(duration)
+	reservedUntil := newEnd.Add(time.Duration(window.BufferMinutes) * time.Minute)
+	if err = p.reserveSlots(ctx, tx, window.DoctorID, window.PatientID, newTime, reservedUntil); err != nil {
+		return err
+	}
+	if err = p.reserveResources(ctx, tx, int64(appointmentID), window.AppointmentTypeID, newTime, reservedUntil); err != nil {
 		return err
 	}
 
-	_, err = tx.Exec(ctx, query, args...)
+	appointmentTimesJSON, err := p.appointmentTimeJSON(newTime, newEnd)
 	if err != nil {
 		return err
 	}
 
 	updateQuery, updateArgs, err := p.db.Sq.Builder.
-		Update(tableNameAvailability).
-		Set("is_booked", false).
-		Where("available_date = ?", appointmentTime.AvailableDate).
-		Where("start_time = ?", appointmentTime.StartTime).
+		Update(p.tableNameAppointment).
+		Set("appointment_time", string(appointmentTimesJSON)).
+		Set("start_time", newTime).
+		Set("end_time", newEnd).
+		Set("branch_id", p.slotBranch(window.DoctorID, newTime)).
+		Set("reschedule_count", squirrel.Expr("reschedule_count + 1")).
+		Where(p.db.Sq.Equal("id", appointmentID)).
 		ToSql()
 	if err != nil {
-		return err
+		return p.db.ErrSQLBuild(err, p.tableNameAppointment+" update")
 	}
 
-	_, err = tx.Exec(ctx, updateQuery, updateArgs...)
+	if _, err = tx.Exec(ctx, updateQuery, updateArgs...); err != nil {
+		return bookingError(p.db.Error(err))
+	}
+
+	// reminders sent so far were for the old time
+	resetQuery, resetArgs, err := p.db.Sq.Builder.
+		Delete(reminderTableName).
+		Where(p.db.Sq.Equal("appointment_id", appointmentID)).
+		ToSql()
 	if err != nil {
-		return err
+		return p.db.ErrSQLBuild(err, reminderTableName+" reset")
 	}
 
-	return tx.Commit(ctx)
+	if _, err = tx.Exec(ctx, resetQuery, resetArgs...); err != nil {
+		return p.db.Error(err)
+	}
+
+	return nil
 }
-func (p *appointmentRepo) GetAppointment(ctx context.Context, appointmentID int) (*entity.Appointment, error) {
-	query, args, err := p.db.Sq.Builder.
-		Select("*").
-		From(p.tableNameAppointment).
-		Where("id = ?", appointmentID).
-		ToSql()
+
+// ReassignAppointment hands a live appointment over to doctorID at the same
+// time, or, when doctorID is empty, to the first other doctor of the same
+// specialization who is free for the whole visit. The new doctor's slots
+// are reserved and the old doctor's released in one transaction.
+func (p *appointmentRepo) ReassignAppointment(ctx context.Context, appointmentID int, doctorID string) (*entity.Appointment, error) {
+	tx, err := p.db.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback(ctx)
 
-	var appointment entity.Appointment
-	err = p.db.QueryRow(ctx, query, args...).Scan(
-		&appointment.ID,
-		&appointment.DoctorID,
-		&appointment.UserID,
-		&appointment.Appointment_time,
-		&appointment.Status,
-	)
+	window, err := p.getAppointmentWindow(ctx, tx, appointmentID)
 	if err != nil {
 		return nil, err
 	}
+	if window.Status != entity.AppointmentStatusScheduled && window.Status != entity.AppointmentStatusConfirmed {
+		return nil, entity.ErrorIllegalTransition
+	}
 
-	return &appointment, nil
-}
+	candidates := []string{doctorID}
+	if doctorID == "" {
+		if candidates, err = p.substituteDoctors(ctx, tx, window.DoctorID); err != nil {
+			return nil, err
+		}
+	}
 
-func (p *appointmentRepo) ListAppointments(ctx context.Context, page, limit int) ([]*entity.Appointment, int, error) {
-	offset := (page - 1) * limit
+	substitute := ""
+	for _, candidate := range candidates {
+		if candidate == window.DoctorID {
+			continue
+		}
+		err = p.reserveSlots(ctx, tx, candidate, window.PatientID, window.StartTime, window.reservedUntil())
+		if err == nil {
+			substitute = candidate
+			break
+		}
+		if !errors.Is(err, entity.ErrorSlotUnavailable) {
+			return nil, err
+		}
+	}
+	if substitute == "" {
+		return nil, entity.ErrorNoSubstitute
+	}
+
+	if err = p.releaseSlots(ctx, tx, window.DoctorID, window.StartTime, window.reservedUntil()); err != nil {
+		return nil, err
+	}
 
 	query, args, err := p.db.Sq.Builder.
-		Select("*").
-		From(p.tableNameAppointment).
-		Limit(uint64(limit)).
-		Offset(uint64(offset)).
+		Update(p.tableNameAppointment).
+		Set("doctor_id", substitute).
+		Set("branch_id", p.slotBranch(substitute, window.StartTime)).
+		Where(p.db.Sq.Equal("id", appointmentID)).
+		Suffix("RETURNING " + strings.Join(appointmentColumns(""), ", ")).
 		ToSql()
 	if err != nil {
-		return nil, 0, err
+		return nil, p.db.ErrSQLBuild(err, p.tableNameAppointment+" reassign")
 	}
 
-	totalQuery, _, err := p.db.Sq.Builder.
-		Select("COUNT(*)").
-		From(p.tableNameAppointment).
-		ToSql()
-	if err != nil {
-		return nil, 0, err
+	var appointment entity.Appointment
+	if err = scanAppointment(tx.QueryRow(ctx, query, args...), &appointment); err != nil {
+		return nil, bookingError(p.db.Error(err))
 	}
 
-	var total int
-	err = p.db.QueryRow(ctx, totalQuery).Scan(&total)
+	if err = tx.Commit(ctx); err != nil {
+		return nil, bookingError(p.db.Error(err))
+	}
+
+	return &appointment, nil
+}
+
+// substituteDoctors lists the other doctors sharing the doctor's
+// specialization.
+func (p *appointmentRepo) substituteDoctors(ctx context.Context, tx pgx.Tx, doctorID string) ([]string, error) {
+	query, args, err := p.db.Sq.Builder.
+		Select("d.id").
+		From(doctorTableName+" d").
+		Join(doctorTableName+" o ON LOWER(o.specialization) = LOWER(d.specialization)").
+		Where(p.db.Sq.Equal("o.id", doctorID)).
+		Where("d.id <> o.id").
+		OrderBy("d.created_at").
+		ToSql()
 	if err != nil {
-		return nil, 0, err
+		return nil, p.db.ErrSQLBuild(err, doctorTableName+" substitutes")
 	}
 
-	rows, err := p.db.Query(ctx, query, args...)
+	rows, err := tx.Query(ctx, query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, p.db.Error(err)
 	}
 	defer rows.Close()
 
-	var appointments []*entity.Appointment
+	var doctors []string
 	for rows.Next() {
-		var appointment entity.Appointment
-		if err := rows.Scan(
-			&appointment.ID,
-			&appointment.DoctorID,
-			&appointment.UserID,
-			&appointment.Appointment_time,
-			&appointment.Status,
-		); err != nil {
-			return nil, 0, err
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, p.db.Error(err)
 		}
-		appointments = append(appointments, &appointment)
+		doctors = append(doctors, id)
 	}
 
-	return appointments, total, nil
+	return doctors, rows.Err()
 }
 
-func (p *appointmentRepo) GetAvailability(ctx context.Context, availabilityID int) (*entity.Availability, error) {
+func (p *appointmentRepo) recordTransition(ctx context.Context, tx pgx.Tx, transition *entity.AppointmentTransition) error {
+	data := map[string]any{
+		"appointment_id": transition.AppointmentID,
+		"to_status":      transition.ToStatus,
+		"reason":         transition.Reason,
+	}
+	if transition.FromStatus != "" {
+		data["from_status"] = transition.FromStatus
+	}
+	if transition.ChangedBy != "" {
+		data["changed_by"] = transition.ChangedBy
+	}
+
 	query, args, err := p.db.Sq.Builder.
-		Select("*").
-		From(p.tableNameAvailability).
-		Where("id = ?", availabilityID).
+		Insert(tableNameStatusLog).
+		SetMap(data).
 		ToSql()
 	if err != nil {
-		return nil, err
+		return p.db.ErrSQLBuild(err, tableNameStatusLog+" create")
 	}
 
-	var availability entity.Availability
-	err = p.db.QueryRow(ctx, query, args...).Scan(
-		&availability.AvailableDate,
-		&availability.StartTime,
-		&availability.EndTime,
-		&availability.IsBooked,
-	)
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return p.db.Error(err)
+	}
+
+	return nil
+}
+
+// TransitionAppointment moves the appointment along the lifecycle described
+// by rule. The current status is read under a row lock so two concurrent
+// transitions cannot both succeed from the same status. Cancelling, or
+// marking a future appointment as no-show after a late cancellation, frees
+// the availability and resources the appointment consumed.
+func (p *appointmentRepo) TransitionAppointment(ctx context.Context, appointmentID int, rule entity.AppointmentTransitionRule, changedBy, reason string) (*entity.Appointment, error) {
+	tx, err := p.db.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback(ctx)
+
+	window, err := p.getAppointmentWindow(ctx, tx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !rule.Allows(window.Status) {
+		return nil, entity.ErrorIllegalTransition
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableNameAppointment).
+		Set("status", rule.To).
+		Where(p.db.Sq.Equal("id", appointmentID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableNameAppointment+" status")
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if rule.To == entity.AppointmentStatusCancelled ||
+		(rule.To == entity.AppointmentStatusNoShow && window.StartTime.After(time.Now())) {
+		if err = p.releaseSlots(ctx, tx, window.DoctorID, window.StartTime, window.reservedUntil()); err != nil {
+			return nil, err
+		}
+		if err = p.releaseResources(ctx, tx, int64(appointmentID)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = p.recordTransition(ctx, tx, &entity.AppointmentTransition{
+		AppointmentID: int64(appointmentID),
+		FromStatus:    window.Status,
+		ToStatus:      rule.To,
+		ChangedBy:     changedBy,
+		Reason:        reason,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return p.GetAppointment(ctx, appointmentID)
+}
+
+func (p *appointmentRepo) ListAppointmentTransitions(ctx context.Context, appointmentID int) ([]*entity.AppointmentTransition, error) {
+	query, args, err := p.db.Sq.Builder.
+		Select(
+			"id",
+			"appointment_id",
+			"COALESCE(from_status, '')",
+			"to_status",
+			"COALESCE(changed_by::text, '')",
+			"COALESCE(reason, '')",
+			"changed_at",
+		).
+		From(tableNameStatusLog).
+		Where(p.db.Sq.Equal("appointment_id", appointmentID)).
+		OrderBy("changed_at", "id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, tableNameStatusLog+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var transitions []*entity.AppointmentTransition
+	for rows.Next() {
+		var transition entity.AppointmentTransition
+		if err = rows.Scan(
+			&transition.ID,
+			&transition.AppointmentID,
+			&transition.FromStatus,
+			&transition.ToStatus,
+			&transition.ChangedBy,
+			&transition.Reason,
+			&transition.ChangedAt,
+		); err != nil {
+			return nil, p.db.Error(err)
+		}
+		transitions = append(transitions, &transition)
+	}
+
+	return transitions, rows.Err()
+}
+
+func (p *appointmentRepo) GetAppointment(ctx context.Context, appointmentID int) (*entity.Appointment, error) {
+	query, args, err := p.appointmentSelectQueryPrefix().
+		Where(p.db.Sq.Equal("id", appointmentID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableNameAppointment+" get")
+	}
+
+	var appointment entity.Appointment
+	if err = scanAppointment(p.db.QueryRow(ctx, query, args...), &appointment); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &appointment, nil
+}
+
+// appointmentFilter applies the filter's conditions to a query over the
+// appointments table, aliased as alias when non-empty.
+func (p *appointmentRepo) appointmentFilter(queryBuilder squirrel.SelectBuilder, alias string, filter *entity.AppointmentFilter) squirrel.SelectBuilder {
+	if alias != "" {
+		alias += "."
+	}
+
+	if filter.PatientID != "" {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Equal(alias+"patient_id", filter.PatientID))
+	}
+	if filter.DoctorID != "" {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Equal(alias+"doctor_id", filter.DoctorID))
+	}
+	if filter.SeriesID != 0 {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Equal(alias+"series_id", filter.SeriesID))
+	}
+	if filter.BranchID != 0 {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Equal(alias+"branch_id", filter.BranchID))
+	}
+	if !filter.StartFrom.IsZero() {
+		queryBuilder = queryBuilder.Where(alias+"start_time >= ?", filter.StartFrom)
+	}
+	if !filter.StartTo.IsZero() {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Lt(alias+"start_time", filter.StartTo))
+	}
+	if len(filter.Statuses) != 0 {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Equal(alias+"status", filter.Statuses))
+	}
+
+	if filter.Descending {
+		return queryBuilder.OrderBy(alias + "start_time DESC")
+	}
+	return queryBuilder.OrderBy(alias + "start_time")
+}
+
+func (p *appointmentRepo) ListAppointments(ctx context.Context, filter *entity.AppointmentFilter) ([]*entity.Appointment, int, error) {
+	query, args, err := p.appointmentFilter(
+		p.db.Sq.Builder.
+			Select(append(appointmentColumns(""), "COUNT(*) OVER()")...).
+			From(p.tableNameAppointment),
+		"", filter).
+		Limit(uint64(filter.Limit)).
+		Offset(uint64((filter.Page - 1) * filter.Limit)).
+		ToSql()
+	if err != nil {
+		return nil, 0, p.db.ErrSQLBuild(err, p.tableNameAppointment+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var (
+		appointments []*entity.Appointment
+		total        int
+	)
+	for rows.Next() {
+		var appointment entity.Appointment
+		if err := scanAppointment(rows, &appointment, &total); err != nil {
+			return nil, 0, p.db.Error(err)
+		}
+		appointments = append(appointments, &appointment)
+	}
+
+	return appointments, total, rows.Err()
+}
+
+// DoctorAgenda returns the doctor's appointments in the filter's range with
+// the patient's name and phone number.
+func (p *appointmentRepo) DoctorAgenda(ctx context.Context, filter *entity.AppointmentFilter) ([]*entity.AgendaEntry, error) {
+	query, args, err := p.appointmentFilter(
+		p.db.Sq.Builder.
+			Select(append(appointmentColumns("a"), "COALESCE(u.full_name, '')", "COALESCE(u.phone_number, '')")...).
+			From(p.tableNameAppointment+" a").
+			Join(userServiceTableName+" u ON u.id = a.patient_id"),
+		"a", filter).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableNameAppointment+" agenda")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var entries []*entity.AgendaEntry
+	for rows.Next() {
+		var entry entity.AgendaEntry
+		if err := scanAppointment(rows, &entry.Appointment, &entry.PatientName, &entry.PatientPhone); err != nil {
+			return nil, p.db.Error(err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (p *appointmentRepo) availabilitySelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"id",
+			"doctor_id",
+			"available_date",
+			"start_time",
+			"end_time",
+			"is_booked",
+			"COALESCE(branch_id, 0)",
+		).From(p.tableNameAvailability)
+}
+
+func scanAvailability(row pgx.Row, availability *entity.Availability) error {
+	var isBooked sql.NullBool
+
+	if err := row.Scan(
+		&availability.ID,
+		&availability.DoctorID,
+		&availability.AvailableDate,
+		&availability.StartTime,
+		&availability.EndTime,
+		&isBooked,
+		&availability.BranchID,
+	); err != nil {
+		return err
+	}
+	availability.IsBooked = isBooked.Bool
+
+	return nil
+}
+
+func (p *appointmentRepo) GetAvailability(ctx context.Context, availabilityID int) (*entity.Availability, error) {
+	query, args, err := p.availabilitySelectQueryPrefix().
+		Where(p.db.Sq.Equal("id", availabilityID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableNameAvailability+" get")
+	}
+
+	var availability entity.Availability
+	if err = scanAvailability(p.db.QueryRow(ctx, query, args...), &availability); err != nil {
+		return nil, p.db.Error(err)
+	}
 
 	return &availability, nil
 }
@@ -390,14 +1001,13 @@ func (p *appointmentRepo) GetAvailability(ctx context.Context, availabilityID in
 func (p *appointmentRepo) ListAvailabilities(ctx context.Context, page, limit int) ([]*entity.Availability, int, error) {
 	offset := (page - 1) * limit
 
-	query, args, err := p.db.Sq.Builder.
-		Select("*").
-		From(p.tableNameAvailability).
+	query, args, err := p.availabilitySelectQueryPrefix().
+		OrderBy("start_time").
 		Limit(uint64(limit)).
 		Offset(uint64(offset)).
 		ToSql()
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, p.db.ErrSQLBuild(err, p.tableNameAvailability+" list")
 	}
 
 	totalQuery, _, err := p.db.Sq.Builder.
@@ -423,12 +1033,7 @@ func (p *appointmentRepo) ListAvailabilities(ctx context.Context, page, limit in
 	var availabilities []*entity.Availability
 	for rows.Next() {
 		var availability entity.Availability
-		if err := rows.Scan(
-			&availability.AvailableDate,
-			&availability.StartTime,
-			&availability.EndTime,
-			&availability.IsBooked,
-		); err != nil {
+		if err := scanAvailability(rows, &availability); err != nil {
 			return nil, 0, err
 		}
 		availabilities = append(availabilities, &availability)
@@ -436,3 +1041,379 @@ func (p *appointmentRepo) ListAvailabilities(ctx context.Context, page, limit in
 
 	return availabilities, total, nil
 }
+
+// SearchAvailabilities returns free slots across every doctor matching the
+// search, earliest first. Slots held for a waitlist offer or blocked by an
+// external busy time are not free.
+func (p *appointmentRepo) SearchAvailabilities(ctx context.Context, search *entity.AvailabilitySearch) ([]*entity.AvailableSlot, int, error) {
+	queryBuilder := p.db.Sq.Builder.
+		Select(
+			"a.id",
+			"a.doctor_id",
+			"COALESCE(u.full_name, '')",
+			"d.specialization",
+			"COALESCE(a.branch_id, 0)",
+			"a.start_time",
+			"a.end_time",
+			"COUNT(*) OVER()",
+		).
+		From(p.tableNameAvailability+" a").
+		Join(doctorTableName+" d ON d.id = a.doctor_id").
+		Join(userServiceTableName+" u ON u.id = d.user_id").
+		Where("a.is_booked IS NOT TRUE").
+		Where(notBusy("a")).
+		Where("(a.held_until IS NULL OR a.held_until <= now())").
+		Where("a.start_time >= ?", search.From).
+		Where(p.db.Sq.Lt("a.start_time", search.To))
+
+	if search.Specialization != "" {
+		queryBuilder = queryBuilder.Where("LOWER(d.specialization) = LOWER(?)", search.Specialization)
+	}
+	if search.Language != "" {
+		queryBuilder = queryBuilder.Where("? = ANY(d.languages)", search.Language)
+	}
+	if search.BranchID != 0 {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Equal("a.branch_id", search.BranchID))
+	}
+
+	// the time of day is compared on the wall clock of the caller's zone
+	if search.TimeFrom != "" {
+		queryBuilder = queryBuilder.Where("(a.start_time AT TIME ZONE ?)::time >= ?::time", search.Location.String(), search.TimeFrom)
+	}
+	if search.TimeTo != "" {
+		queryBuilder = queryBuilder.Where("(a.end_time AT TIME ZONE ?)::time <= ?::time", search.Location.String(), search.TimeTo)
+	}
+
+	query, args, err := queryBuilder.
+		OrderBy("a.start_time", "a.doctor_id").
+		Limit(uint64(search.Limit)).
+		Offset(uint64((search.Page - 1) * search.Limit)).
+		ToSql()
+	if err != nil {
+		return nil, 0, p.db.ErrSQLBuild(err, p.tableNameAvailability+" search")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var (
+		slots []*entity.AvailableSlot
+		total int
+	)
+	for rows.Next() {
+		var slot entity.AvailableSlot
+		if err = rows.Scan(
+			&slot.AvailabilityID,
+			&slot.DoctorID,
+			&slot.DoctorName,
+			&slot.Specialization,
+			&slot.BranchID,
+			&slot.StartTime,
+			&slot.EndTime,
+			&total,
+		); err != nil {
+			return nil, 0, p.db.Error(err)
+		}
+		slots = append(slots, &slot)
+	}
+
+	return slots, total, rows.Err()
+}
+
+// lockDoctor takes a row lock on the doctor so that concurrent slot writes
+// for the same doctor are serialized and the overlap check stays valid.
+func (p *appointmentRepo) lockDoctor(ctx context.Context, tx pgx.Tx, doctorID string) error {
+	query, args, err := p.db.Sq.Builder.
+		Select("id").
+		From(doctorTableName).
+		Where(p.db.Sq.Equal("id", doctorID)).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, doctorTableName+" lock")
+	}
+
+	var id string
+	if err = tx.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		return p.db.Error(err)
+	}
+
+	return nil
+}
+
+func (p *appointmentRepo) checkSlotOverlap(ctx context.Context, tx pgx.Tx, availability *entity.Availability) error {
+	builder := p.db.Sq.Builder.
+		Select("COUNT(*)").
+		From(p.tableNameAvailability).
+		Where(p.db.Sq.Equal("doctor_id", availability.DoctorID)).
+		Where(p.db.Sq.Lt("start_time", availability.EndTime)).
+		Where(p.db.Sq.Gt("end_time", availability.StartTime))
+	if availability.ID != 0 {
+		builder = builder.Where(p.db.Sq.NotEqual("id", availability.ID))
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableNameAvailability+" overlap")
+	}
+
+	var count int
+	if err = tx.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return p.db.Error(err)
+	}
+	if count != 0 {
+		return entity.ErrorSlotOverlap
+	}
+
+	return nil
+}
+
+// checkClosed rejects a slot that falls into a closure of the hospital or
+// of the slot's branch.
+func (p *appointmentRepo) checkClosed(ctx context.Context, tx pgx.Tx, availability *entity.Availability) error {
+	query, args, err := p.db.Sq.Builder.
+		Select("COUNT(*)").
+		From(closureTableName+" c").
+		Where("(c.branch_id IS NULL OR c.branch_id = ?)", availability.BranchID).
+		Where(p.db.Sq.Lt("c.start_time", availability.EndTime)).
+		Where(p.db.Sq.Gt("c.end_time", availability.StartTime)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, closureTableName+" overlap")
+	}
+
+	var count int
+	if err = tx.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return p.db.Error(err)
+	}
+	if count != 0 {
+		return entity.ErrorClosed
+	}
+
+	return nil
+}
+
+func (p *appointmentRepo) insertAvailability(ctx context.Context, tx pgx.Tx, availability *entity.Availability) error {
+	if !availability.EndTime.After(availability.StartTime) {
+		return fmt.Errorf("slot end time must be after start time")
+	}
+	if err := p.checkSlotOverlap(ctx, tx, availability); err != nil {
+		return err
+	}
+	if err := p.checkClosed(ctx, tx, availability); err != nil {
+		return err
+	}
+	if err := p.checkBranch(ctx, tx, availability); err != nil {
+		return err
+	}
+
+	data := map[string]any{
+		"doctor_id":      availability.DoctorID,
+		"available_date": availability.StartTime.In(p.db.Location).Format("2006-01-02"),
+		"start_time":     availability.StartTime,
+		"end_time":       availability.EndTime,
+		"is_booked":      false,
+	}
+	if availability.BranchID != 0 {
+		data["branch_id"] = availability.BranchID
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableNameAvailability).
+		SetMap(data).
+		Suffix("RETURNING id, available_date").
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableNameAvailability+" create")
+	}
+
+	if err = tx.QueryRow(ctx, query, args...).Scan(&availability.ID, &availability.AvailableDate); err != nil {
+		return p.db.Error(err)
+	}
+	availability.IsBooked = false
+
+	return nil
+}
+
+func (p *appointmentRepo) CreateAvailability(ctx context.Context, availability *entity.Availability) (*entity.Availability, error) {
+	availabilities, err := p.CreateAvailabilities(ctx, []*entity.Availability{availability})
+	if err != nil {
+		return nil, err
+	}
+
+	return availabilities[0], nil
+}
+
+// CreateAvailabilities inserts all slots in one transaction; a single
+// overlapping slot rejects the whole batch.
+func (p *appointmentRepo) CreateAvailabilities(ctx context.Context, availabilities []*entity.Availability) ([]*entity.Availability, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	locked := make(map[string]bool)
+	for _, availability := range availabilities {
+		if !locked[availability.DoctorID] {
+			if err = p.lockDoctor(ctx, tx, availability.DoctorID); err != nil {
+				return nil, err
+			}
+			locked[availability.DoctorID] = true
+		}
+
+		if err = p.insertAvailability(ctx, tx, availability); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return availabilities, nil
+}
+
+// FillAvailabilities inserts the slots that do not overlap anything the
+// doctor already has, a closure nor a branch's closed hours, and silently
+// skips the rest. It is used to top up generated availability, so
+// re-running it is harmless.
+func (p *appointmentRepo) FillAvailabilities(ctx context.Context, availabilities []*entity.Availability) (int, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	created := 0
+	locked := make(map[string]bool)
+	for _, availability := range availabilities {
+		if !locked[availability.DoctorID] {
+			if err = p.lockDoctor(ctx, tx, availability.DoctorID); err != nil {
+				return 0, err
+			}
+			locked[availability.DoctorID] = true
+		}
+
+		err = p.insertAvailability(ctx, tx, availability)
+		if errors.Is(err, entity.ErrorSlotOverlap) || errors.Is(err, entity.ErrorClosed) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		created++
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return created, nil
+}
+
+func (p *appointmentRepo) UpdateAvailability(ctx context.Context, availability *entity.Availability) (*entity.Availability, error) {
+	if !availability.EndTime.After(availability.StartTime) {
+		return nil, fmt.Errorf("slot end time must be after start time")
+	}
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = p.lockDoctor(ctx, tx, availability.DoctorID); err != nil {
+		return nil, err
+	}
+
+	query, args, err := p.availabilitySelectQueryPrefix().
+		Where(p.db.Sq.Equal("id", availability.ID)).
+		Where(p.db.Sq.Equal("doctor_id", availability.DoctorID)).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableNameAvailability+" get")
+	}
+
+	var current entity.Availability
+	if err = scanAvailability(tx.QueryRow(ctx, query, args...), &current); err != nil {
+		return nil, p.db.Error(err)
+	}
+	if current.IsBooked {
+		return nil, entity.ErrorSlotBooked
+	}
+	if availability.BranchID == 0 {
+		availability.BranchID = current.BranchID
+	}
+
+	if err = p.checkSlotOverlap(ctx, tx, availability); err != nil {
+		return nil, err
+	}
+	if err = p.checkClosed(ctx, tx, availability); err != nil {
+		return nil, err
+	}
+	if err = p.checkBranch(ctx, tx, availability); err != nil {
+		return nil, err
+	}
+
+	clauses := map[string]any{
+		"available_date": availability.StartTime.In(p.db.Location).Format("2006-01-02"),
+		"start_time":     availability.StartTime,
+		"end_time":       availability.EndTime,
+	}
+	if availability.BranchID != 0 {
+		clauses["branch_id"] = availability.BranchID
+	}
+
+	query, args, err = p.db.Sq.Builder.
+		Update(p.tableNameAvailability).
+		SetMap(clauses).
+		Where(p.db.Sq.Equal("id", availability.ID)).
+		Suffix("RETURNING id, doctor_id, available_date, start_time, end_time, is_booked, COALESCE(branch_id, 0)").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableNameAvailability+" update")
+	}
+
+	var updated entity.Availability
+	if err = scanAvailability(tx.QueryRow(ctx, query, args...), &updated); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// DeleteAvailability removes a slot unless it is booked. The booked check is
+// part of the DELETE itself so a concurrent booking cannot slip in between.
+func (p *appointmentRepo) DeleteAvailability(ctx context.Context, availabilityID int) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableNameAvailability).
+		Where(p.db.Sq.Equal("id", availabilityID)).
+		Where("is_booked IS NOT TRUE").
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableNameAvailability+" delete")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		if _, err := p.GetAvailability(ctx, availabilityID); err != nil {
+			return err
+		}
+		return entity.ErrorSlotBooked
+	}
+
+	return nil
+}
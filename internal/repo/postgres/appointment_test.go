@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/config"
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/tenant"
+	"github.com/google/uuid"
+)
+
+// testDB connects to the database named by the POSTGRES_* variables. The
+// tests need a migrated database and only run with POSTGRES_TEST=1.
+func testDB(t *testing.T) (*postgres.PostgresDB, context.Context) {
+	t.Helper()
+	if os.Getenv("POSTGRES_TEST") != "1" {
+		t.Skip("set POSTGRES_TEST=1 to run against a migrated database")
+	}
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := postgres.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+
+	return db, tenant.NewContext(context.Background(), cfg.Tenant.Default)
+}
+
+// testUser creates a user that is removed, with everything referencing
+// it, when the test ends.
+func testUser(t *testing.T, ctx context.Context, db *postgres.PostgresDB, role string) string {
+	t.Helper()
+
+	id := uuid.NewString()
+	if _, err := NewUserRepo(db).Create(ctx, &entity.User{
+		ID:        id,
+		FullName:  "Test " + role,
+		UserName:  "test-" + id,
+		Password:  "secret",
+		Role:      role,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := db.Exec(ctx, "DELETE FROM users WHERE id = $1", id); err != nil {
+			t.Error(err)
+		}
+	})
+
+	return id
+}
+
+// TestCreateAppointmentConcurrent books one slot from many patients at
+// once; exactly one booking may succeed. Bookings racing the winner are
+// told the slot was taken, those starting after it committed find the
+// doctor unavailable; all of them are answered with 409.
+func TestCreateAppointmentConcurrent(t *testing.T) {
+	db, ctx := testDB(t)
+
+	doctorID := uuid.NewString()
+	if _, err := NewDoctorRepo(db).Create(ctx, &entity.Doctor{
+		ID:             doctorID,
+		UserID:         testUser(t, ctx, db, entity.RoleDoctor),
+		Specialization: "Therapist",
+		Working_hour:   "09:00-18:00",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewAppointmentRepo(db)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	if _, err := repo.CreateAvailability(ctx, &entity.Availability{
+		DoctorID:  doctorID,
+		StartTime: start,
+		EndTime:   start.Add(defaultAppointmentDuration),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	const patients = 10
+	patientIDs := make([]string, patients)
+	for i := range patientIDs {
+		patientIDs[i] = testUser(t, ctx, db, entity.RoleUser)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		errs   = make([]error, patients)
+		ready  = make(chan struct{})
+		starts = start.Format(time.RFC3339)
+	)
+	for i, patientID := range patientIDs {
+		wg.Add(1)
+		go func(i int, patientID string) {
+			defer wg.Done()
+			<-ready
+			_, errs[i] = repo.CreateAppointment(ctx, &entity.Appointment{
+				DoctorID:         doctorID,
+				UserID:           patientID,
+				Appointment_time: map[string]interface{}{"start_time": starts},
+			})
+		}(i, patientID)
+	}
+	close(ready)
+	wg.Wait()
+
+	var booked int
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			booked++
+		case errors.Is(err, entity.ErrorSlotTaken), errors.Is(err, entity.ErrorSlotOverlap),
+			errors.Is(err, entity.ErrorSlotUnavailable):
+		default:
+			t.Errorf("patient %d: unexpected error: %v", i, err)
+		}
+	}
+	if booked != 1 {
+		t.Fatalf("got %d bookings of one slot, want 1", booked)
+	}
+
+	var appointments int
+	if err := db.QueryRow(ctx, fmt.Sprintf("SELECT count(*) FROM %s WHERE doctor_id = $1", tableNameAppointment), doctorID).Scan(&appointments); err != nil {
+		t.Fatal(err)
+	}
+	if appointments != 1 {
+		t.Fatalf("got %d appointments stored, want 1", appointments)
+	}
+}
@@ -0,0 +1,268 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	appointmentTypeTableName       = "appointment_types"
+	doctorAppointmentTypeTableName = "doctor_appointment_types"
+)
+
+type appointmentTypeRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewAppointmentTypeRepo(db *postgres.PostgresDB) interfaces.AppointmentType {
+	return &appointmentTypeRepo{
+		db:        db,
+		tableName: appointmentTypeTableName,
+	}
+}
+
+func (p *appointmentTypeRepo) appointmentTypeSelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"id",
+			"name",
+			"description",
+			"duration_minutes",
+			"buffer_minutes",
+			"required_resources",
+		).From(p.tableName)
+}
+
+func scanAppointmentType(row pgx.Row, appointmentType *entity.AppointmentType) error {
+	var description sql.NullString
+
+	if err := row.Scan(
+		&appointmentType.ID,
+		&appointmentType.Name,
+		&description,
+		&appointmentType.DurationMinutes,
+		&appointmentType.BufferMinutes,
+		&appointmentType.RequiredResources,
+	); err != nil {
+		return err
+	}
+	appointmentType.Description = description.String
+
+	return nil
+}
+
+// appointmentTypeResolveQuery selects the appointment type with the doctor's
+// duration and buffer overrides applied on top of the type defaults.
+func appointmentTypeResolveQuery(db *postgres.PostgresDB, doctorID string, appointmentTypeID int64) (string, []interface{}, error) {
+	query, args, err := db.Sq.Builder.
+		Select(
+			"t.id",
+			"t.name",
+			"t.description",
+			"COALESCE(d.duration_minutes, t.duration_minutes)",
+			"COALESCE(d.buffer_minutes, t.buffer_minutes)",
+			"t.required_resources",
+		).
+		From(appointmentTypeTableName+" t").
+		LeftJoin(doctorAppointmentTypeTableName+" d ON d.appointment_type_id = t.id AND d.doctor_id = ?", doctorID).
+		Where(db.Sq.Equal("t.id", appointmentTypeID)).
+		ToSql()
+	if err != nil {
+		return "", nil, db.ErrSQLBuild(err, appointmentTypeTableName+" resolve")
+	}
+
+	return query, args, nil
+}
+
+func (p *appointmentTypeRepo) Create(ctx context.Context, appointmentType *entity.AppointmentType) (*entity.AppointmentType, error) {
+	if appointmentType.RequiredResources == nil {
+		appointmentType.RequiredResources = []string{}
+	}
+
+	data := map[string]any{
+		"name":               appointmentType.Name,
+		"description":        appointmentType.Description,
+		"duration_minutes":   appointmentType.DurationMinutes,
+		"buffer_minutes":     appointmentType.BufferMinutes,
+		"required_resources": appointmentType.RequiredResources,
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(data).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&appointmentType.ID); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return appointmentType, nil
+}
+
+func (p *appointmentTypeRepo) Get(ctx context.Context, appointmentTypeID int64) (*entity.AppointmentType, error) {
+	query, args, err := p.appointmentTypeSelectQueryPrefix().
+		Where(p.db.Sq.Equal("id", appointmentTypeID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var appointmentType entity.AppointmentType
+	if err = scanAppointmentType(p.db.QueryRow(ctx, query, args...), &appointmentType); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &appointmentType, nil
+}
+
+func (p *appointmentTypeRepo) Update(ctx context.Context, appointmentType *entity.AppointmentType) (*entity.AppointmentType, error) {
+	if appointmentType.RequiredResources == nil {
+		appointmentType.RequiredResources = []string{}
+	}
+
+	clauses := map[string]any{
+		"name":               appointmentType.Name,
+		"description":        appointmentType.Description,
+		"duration_minutes":   appointmentType.DurationMinutes,
+		"buffer_minutes":     appointmentType.BufferMinutes,
+		"required_resources": appointmentType.RequiredResources,
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(clauses).
+		Where(p.db.Sq.Equal("id", appointmentType.ID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" update")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return nil, entity.ErrorNotFound
+	}
+
+	return appointmentType, nil
+}
+
+func (p *appointmentTypeRepo) Delete(ctx context.Context, appointmentTypeID int64) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(p.db.Sq.Equal("id", appointmentTypeID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" delete")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return entity.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (p *appointmentTypeRepo) List(ctx context.Context) ([]*entity.AppointmentType, error) {
+	query, args, err := p.appointmentTypeSelectQueryPrefix().
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var appointmentTypes []*entity.AppointmentType
+	for rows.Next() {
+		var appointmentType entity.AppointmentType
+		if err = scanAppointmentType(rows, &appointmentType); err != nil {
+			return nil, p.db.Error(err)
+		}
+		appointmentTypes = append(appointmentTypes, &appointmentType)
+	}
+
+	return appointmentTypes, rows.Err()
+}
+
+func (p *appointmentTypeRepo) SetDoctorOverride(ctx context.Context, override *entity.DoctorAppointmentType) error {
+	data := map[string]any{
+		"doctor_id":           override.DoctorID,
+		"appointment_type_id": override.AppointmentTypeID,
+		"duration_minutes":    override.DurationMinutes,
+		"buffer_minutes":      override.BufferMinutes,
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(doctorAppointmentTypeTableName).
+		SetMap(data).
+		Suffix("ON CONFLICT (doctor_id, appointment_type_id) DO UPDATE SET duration_minutes = EXCLUDED.duration_minutes, buffer_minutes = EXCLUDED.buffer_minutes").
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, doctorAppointmentTypeTableName+" upsert")
+	}
+
+	if _, err = p.db.Exec(ctx, query, args...); err != nil {
+		return p.db.Error(err)
+	}
+
+	return nil
+}
+
+func (p *appointmentTypeRepo) DeleteDoctorOverride(ctx context.Context, doctorID string, appointmentTypeID int64) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(doctorAppointmentTypeTableName).
+		Where(p.db.Sq.Equal("doctor_id", doctorID)).
+		Where(p.db.Sq.Equal("appointment_type_id", appointmentTypeID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, doctorAppointmentTypeTableName+" delete")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return entity.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (p *appointmentTypeRepo) Resolve(ctx context.Context, doctorID string, appointmentTypeID int64) (*entity.AppointmentType, error) {
+	query, args, err := appointmentTypeResolveQuery(p.db, doctorID, appointmentTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var appointmentType entity.AppointmentType
+	if err = scanAppointmentType(p.db.QueryRow(ctx, query, args...), &appointmentType); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &appointmentType, nil
+}
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+const attachmentTableName = "attachments"
+
+type attachmentRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewAttachmentRepo(db *postgres.PostgresDB) interfaces.Attachment {
+	return &attachmentRepo{
+		db:        db,
+		tableName: attachmentTableName,
+	}
+}
+
+func (p *attachmentRepo) attachmentSelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"f.id",
+			"COALESCE(f.user_id::text, '')",
+			"COALESCE(f.appointment_id, e.appointment_id, 0)",
+			"COALESCE(f.encounter_id, 0)",
+			"f.file_name",
+			"f.content_type",
+			"f.size",
+			"f.object_key",
+			"COALESCE(f.uploaded_by::text, '')",
+			"f.created_at",
+		).
+		From(p.tableName + " f").
+		LeftJoin(encounterTableName + " e ON e.id = f.encounter_id")
+}
+
+func scanAttachment(row pgx.Row, attachment *entity.Attachment) error {
+	return row.Scan(
+		&attachment.ID,
+		&attachment.UserID,
+		&attachment.AppointmentID,
+		&attachment.EncounterID,
+		&attachment.FileName,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.Key,
+		&attachment.UploadedBy,
+		&attachment.CreatedAt,
+	)
+}
+
+// Create stores the attachment for its owner: the encounter when
+// EncounterID is set, else the appointment, else the user.
+func (p *attachmentRepo) Create(ctx context.Context, attachment *entity.Attachment) (*entity.Attachment, error) {
+	data := map[string]any{
+		"object_key":   attachment.Key,
+		"file_name":    attachment.FileName,
+		"content_type": attachment.ContentType,
+		"size":         attachment.Size,
+		"uploaded_by":  attachment.UploadedBy,
+	}
+	switch {
+	case attachment.EncounterID != 0:
+		data["encounter_id"] = attachment.EncounterID
+	case attachment.AppointmentID != 0:
+		data["appointment_id"] = attachment.AppointmentID
+	default:
+		data["user_id"] = attachment.UserID
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(data).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&attachment.ID); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return p.Get(ctx, attachment.ID)
+}
+
+func (p *attachmentRepo) Get(ctx context.Context, attachmentID int64) (*entity.Attachment, error) {
+	query, args, err := p.attachmentSelectQueryPrefix().
+		Where(p.db.Sq.Equal("f.id", attachmentID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var attachment entity.Attachment
+	if err = scanAttachment(p.db.QueryRow(ctx, query, args...), &attachment); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &attachment, nil
+}
+
+// List returns the attachments of the owner named by the filter, latest
+// first. Attachments of an appointment's encounter are not listed with
+// the appointment.
+func (p *attachmentRepo) List(ctx context.Context, filter *entity.AttachmentFilter) ([]*entity.Attachment, error) {
+	conditions := squirrel.And{}
+	if filter.UserID != "" {
+		conditions = append(conditions, p.db.Sq.Equal("f.user_id", filter.UserID))
+	}
+	if filter.AppointmentID != 0 {
+		conditions = append(conditions, p.db.Sq.Equal("f.appointment_id", filter.AppointmentID))
+	}
+	if filter.EncounterID != 0 {
+		conditions = append(conditions, p.db.Sq.Equal("f.encounter_id", filter.EncounterID))
+	}
+
+	query, args, err := p.attachmentSelectQueryPrefix().
+		Where(conditions).
+		OrderBy("f.created_at DESC", "f.id DESC").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	attachments := []*entity.Attachment{}
+	for rows.Next() {
+		var attachment entity.Attachment
+		if err = scanAttachment(rows, &attachment); err != nil {
+			return nil, p.db.Error(err)
+		}
+		attachments = append(attachments, &attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (p *attachmentRepo) Delete(ctx context.Context, attachmentID int64) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(p.db.Sq.Equal("id", attachmentID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" delete")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return entity.ErrorNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,343 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	branchTableName       = "branches"
+	branchHoursTableName  = "branch_hours"
+	doctorBranchTableName = "doctor_branches"
+)
+
+type branchRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewBranchRepo(db *postgres.PostgresDB) interfaces.Branch {
+	return &branchRepo{
+		db:        db,
+		tableName: branchTableName,
+	}
+}
+
+func (p *branchRepo) branchSelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"id",
+			"name",
+			"address",
+			"timezone",
+			"phone",
+			"email",
+			"created_at",
+		).From(p.tableName)
+}
+
+func scanBranch(row pgx.Row, branch *entity.Branch) error {
+	return row.Scan(
+		&branch.ID,
+		&branch.Name,
+		&branch.Address,
+		&branch.Timezone,
+		&branch.Phone,
+		&branch.Email,
+		&branch.CreatedAt,
+	)
+}
+
+// hours loads the weekly opening hours of the given branches.
+func (p *branchRepo) hours(ctx context.Context, branches ...*entity.Branch) error {
+	ids := make([]int64, 0, len(branches))
+	for _, branch := range branches {
+		ids = append(ids, branch.ID)
+	}
+
+	hours, err := openingHours(ctx, p.db, branchHoursTableName, "branch_id", ids)
+	if err != nil {
+		return err
+	}
+	for _, branch := range branches {
+		branch.Hours = hours[branch.ID]
+	}
+
+	return nil
+}
+
+func (p *branchRepo) Create(ctx context.Context, branch *entity.Branch) (*entity.Branch, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(map[string]any{
+			"name":     branch.Name,
+			"address":  branch.Address,
+			"timezone": branch.Timezone,
+			"phone":    branch.Phone,
+			"email":    branch.Email,
+		}).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	if err = tx.QueryRow(ctx, query, args...).Scan(&branch.ID, &branch.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = setOpeningHours(ctx, p.db, tx, branchHoursTableName, "branch_id", branch.ID, branch.Hours); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return branch, nil
+}
+
+func (p *branchRepo) Get(ctx context.Context, branchID int64) (*entity.Branch, error) {
+	query, args, err := p.branchSelectQueryPrefix().
+		Where(p.db.Sq.Equal("id", branchID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var branch entity.Branch
+	if err = scanBranch(p.db.QueryRow(ctx, query, args...), &branch); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = p.hours(ctx, &branch); err != nil {
+		return nil, err
+	}
+
+	return &branch, nil
+}
+
+// Update changes the branch and replaces its opening hours. Slots already
+// published outside the new hours are kept.
+func (p *branchRepo) Update(ctx context.Context, branch *entity.Branch) (*entity.Branch, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(map[string]any{
+			"name":     branch.Name,
+			"address":  branch.Address,
+			"timezone": branch.Timezone,
+			"phone":    branch.Phone,
+			"email":    branch.Email,
+		}).
+		Where(p.db.Sq.Equal("id", branch.ID)).
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" update")
+	}
+
+	if err = tx.QueryRow(ctx, query, args...).Scan(&branch.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = setOpeningHours(ctx, p.db, tx, branchHoursTableName, "branch_id", branch.ID, branch.Hours); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return branch, nil
+}
+
+// Delete removes a branch that has no upcoming appointments, together
+// with its closures and doctor assignments. Past appointments and slots
+// keep their history without a branch.
+func (p *branchRepo) Delete(ctx context.Context, branchID int64) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(p.db.Sq.Equal("id", branchID)).
+		Where("NOT EXISTS (SELECT 1 FROM "+tableNameAppointment+" a WHERE a.branch_id = "+p.tableName+".id AND a.end_time > now() AND a.status IN (?, ?))",
+			entity.AppointmentStatusScheduled, entity.AppointmentStatusConfirmed).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" delete")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		if _, err = p.Get(ctx, branchID); err != nil {
+			return err
+		}
+		return entity.ErrorBranchInUse
+	}
+
+	return nil
+}
+
+// List returns every branch by name.
+func (p *branchRepo) List(ctx context.Context) ([]*entity.Branch, error) {
+	query, args, err := p.branchSelectQueryPrefix().
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	branches := []*entity.Branch{}
+	for rows.Next() {
+		var branch entity.Branch
+		if err = scanBranch(rows, &branch); err != nil {
+			return nil, p.db.Error(err)
+		}
+		branches = append(branches, &branch)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = p.hours(ctx, branches...); err != nil {
+		return nil, err
+	}
+
+	return branches, nil
+}
+
+// SetDoctorBranches replaces the branches the doctor works at. Slots
+// already published at a branch the doctor leaves are kept.
+func (p *branchRepo) SetDoctorBranches(ctx context.Context, doctorID string, branchIDs []int64) error {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	unique := make(map[int64]bool, len(branchIDs))
+	for _, id := range branchIDs {
+		unique[id] = true
+	}
+
+	if len(unique) != 0 {
+		query, args, err := p.db.Sq.Builder.
+			Select("COUNT(*)").
+			From(p.tableName).
+			Where(p.db.Sq.Equal("id", branchIDs)).
+			ToSql()
+		if err != nil {
+			return p.db.ErrSQLBuild(err, p.tableName+" count")
+		}
+
+		var count int
+		if err = tx.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+			return p.db.Error(err)
+		}
+		if count != len(unique) {
+			return entity.ErrorNotFound
+		}
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Delete(doctorBranchTableName).
+		Where(p.db.Sq.Equal("doctor_id", doctorID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, doctorBranchTableName+" delete")
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return p.db.Error(err)
+	}
+
+	if len(unique) != 0 {
+		insert := p.db.Sq.Builder.
+			Insert(doctorBranchTableName).
+			Columns("doctor_id", "branch_id")
+		for id := range unique {
+			insert = insert.Values(doctorID, id)
+		}
+
+		query, args, err = insert.ToSql()
+		if err != nil {
+			return p.db.ErrSQLBuild(err, doctorBranchTableName+" insert")
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return p.db.Error(err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// checkBranch rejects a slot at a branch the doctor is not assigned to, or
+// outside the branch's opening hours read on the branch's own wall clock.
+// A slot has to end on the day it starts to fit the hours.
+func (p *appointmentRepo) checkBranch(ctx context.Context, tx pgx.Tx, availability *entity.Availability) error {
+	if availability.BranchID == 0 {
+		return nil
+	}
+
+	local := "(?::timestamptz AT TIME ZONE b.timezone)"
+	start, end := availability.StartTime, availability.EndTime
+	query, args, err := p.db.Sq.Builder.
+		Select().
+		Column(squirrel.Expr("EXISTS (SELECT 1 FROM "+doctorBranchTableName+" db WHERE db.branch_id = b.id AND db.doctor_id = ?)",
+			availability.DoctorID)).
+		Column(squirrel.Expr("NOT EXISTS (SELECT 1 FROM "+branchHoursTableName+" h WHERE h.branch_id = b.id)"+
+			" OR EXISTS (SELECT 1 FROM "+branchHoursTableName+" h WHERE h.branch_id = b.id"+
+			" AND h.weekday = EXTRACT(DOW FROM "+local+")"+
+			" AND h.start_time <= "+local+"::time"+
+			" AND "+local+" <= "+local+"::date + h.end_time)",
+			start, start, end, start)).
+		From(branchTableName + " b").
+		Where(p.db.Sq.Equal("b.id", availability.BranchID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, branchTableName+" check")
+	}
+
+	var assigned, open bool
+	if err = p.db.Error(tx.QueryRow(ctx, query, args...).Scan(&assigned, &open)); err != nil {
+		if errors.Is(err, entity.ErrorNotFound) {
+			return entity.ErrorBranchNotAssigned
+		}
+		return err
+	}
+	if !assigned {
+		return entity.ErrorBranchNotAssigned
+	}
+	if !open {
+		return entity.ErrorClosed
+	}
+
+	return nil
+}
@@ -0,0 +1,326 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	busyTimeTableName = "external_busy_times"
+)
+
+// notBusy is the condition that the availability slot in table overlaps
+// none of its doctor's external busy times or leaves, nor a closure of the
+// hospital. Queries looking for free slots add it next to is_booked, which
+// stays reserved for appointments.
+func notBusy(table string) string {
+	doctor := " b WHERE b.doctor_id = " + table + ".doctor_id"
+	overlaps := " AND b.start_time < " + table + ".end_time" +
+		" AND b.end_time > " + table + ".start_time)"
+
+	return "NOT EXISTS (SELECT 1 FROM " + busyTimeTableName + doctor + overlaps +
+		" AND NOT EXISTS (SELECT 1 FROM " + doctorLeaveTableName + doctor + overlaps +
+		" AND " + notClosed(table)
+}
+
+// notClosed is the condition that the row in table overlaps no closure of
+// the hospital or of the row's branch.
+func notClosed(table string) string {
+	return "NOT EXISTS (SELECT 1 FROM " + closureTableName + " c" +
+		" WHERE (c.branch_id IS NULL OR c.branch_id = " + table + ".branch_id)" +
+		" AND c.start_time < " + table + ".end_time" +
+		" AND c.end_time > " + table + ".start_time)"
+}
+
+type busyTimeRepo struct {
+	db                    *postgres.PostgresDB
+	tableName             string
+	tableNameAvailability string
+	tableNameAppointment  string
+}
+
+func NewBusyTimeRepo(db *postgres.PostgresDB) interfaces.BusyTime {
+	return &busyTimeRepo{
+		db:                    db,
+		tableName:             busyTimeTableName,
+		tableNameAvailability: tableNameAvailability,
+		tableNameAppointment:  tableNameAppointment,
+	}
+}
+
+func scanBusyTime(row pgx.Row, busyTime *entity.BusyTime) error {
+	return row.Scan(
+		&busyTime.ID,
+		&busyTime.DoctorID,
+		&busyTime.Source,
+		&busyTime.UID,
+		&busyTime.Summary,
+		&busyTime.StartTime,
+		&busyTime.EndTime,
+		&busyTime.UpdatedAt,
+	)
+}
+
+func (p *busyTimeRepo) listBusyTimes(ctx context.Context, q querier, where map[string]any, from, to time.Time) ([]*entity.BusyTime, error) {
+	queryBuilder := p.db.Sq.Builder.
+		Select("id", "doctor_id", "source", "uid", "summary", "start_time", "end_time", "updated_at").
+		From(p.tableName).
+		Where(p.db.Sq.EqualMany(where)).
+		OrderBy("start_time")
+	if !from.IsZero() {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Gt("end_time", from))
+	}
+	if !to.IsZero() {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Lt("start_time", to))
+	}
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var busyTimes []*entity.BusyTime
+	for rows.Next() {
+		var busyTime entity.BusyTime
+		if err = scanBusyTime(rows, &busyTime); err != nil {
+			return nil, p.db.Error(err)
+		}
+		busyTimes = append(busyTimes, &busyTime)
+	}
+
+	return busyTimes, rows.Err()
+}
+
+// Import reconciles the busy times of one source with a fresh copy of the
+// external calendar: new events are added, moved ones updated and the ones
+// no longer in the calendar removed, which unblocks their slots again.
+func (p *busyTimeRepo) Import(ctx context.Context, doctorID, source string, busyTimes []*entity.BusyTime) (*entity.BusyTimeImport, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := p.listBusyTimes(ctx, tx, map[string]any{
+		"doctor_id": doctorID,
+		"source":    source,
+	}, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*entity.BusyTime, len(current))
+	for _, busyTime := range current {
+		existing[busyTime.UID] = busyTime
+	}
+
+	result := &entity.BusyTimeImport{
+		DoctorID: doctorID,
+		Source:   source,
+	}
+	seen := make(map[string]bool, len(busyTimes))
+	for _, busyTime := range busyTimes {
+		if seen[busyTime.UID] || !busyTime.EndTime.After(busyTime.StartTime) {
+			result.Skipped++
+			continue
+		}
+		seen[busyTime.UID] = true
+
+		old, ok := existing[busyTime.UID]
+		switch {
+		case !ok:
+			err = p.insert(ctx, tx, doctorID, source, busyTime)
+			result.Added++
+		case !old.StartTime.Equal(busyTime.StartTime) || !old.EndTime.Equal(busyTime.EndTime) || old.Summary != busyTime.Summary:
+			err = p.update(ctx, tx, old.ID, busyTime)
+			result.Updated++
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var stale []int64
+	for uid, busyTime := range existing {
+		if !seen[uid] {
+			stale = append(stale, busyTime.ID)
+		}
+	}
+	if len(stale) > 0 {
+		query, args, err := p.db.Sq.Builder.
+			Delete(p.tableName).
+			Where(p.db.Sq.Equal("id", stale)).
+			ToSql()
+		if err != nil {
+			return nil, p.db.ErrSQLBuild(err, p.tableName+" delete stale")
+		}
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return nil, p.db.Error(err)
+		}
+		result.Removed = len(stale)
+	}
+
+	if result.BlockedSlots, err = p.blockedSlots(ctx, tx, doctorID, source); err != nil {
+		return nil, err
+	}
+	if result.Conflicts, err = p.conflicts(ctx, tx, doctorID, source); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (p *busyTimeRepo) insert(ctx context.Context, tx pgx.Tx, doctorID, source string, busyTime *entity.BusyTime) error {
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(map[string]any{
+			"doctor_id":  doctorID,
+			"source":     source,
+			"uid":        busyTime.UID,
+			"summary":    busyTime.Summary,
+			"start_time": busyTime.StartTime,
+			"end_time":   busyTime.EndTime,
+			"updated_at": time.Now(),
+		}).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" insert")
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return p.db.Error(err)
+	}
+
+	return nil
+}
+
+func (p *busyTimeRepo) update(ctx context.Context, tx pgx.Tx, id int64, busyTime *entity.BusyTime) error {
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(map[string]any{
+			"summary":    busyTime.Summary,
+			"start_time": busyTime.StartTime,
+			"end_time":   busyTime.EndTime,
+			"updated_at": time.Now(),
+		}).
+		Where(p.db.Sq.Equal("id", id)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" update")
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return p.db.Error(err)
+	}
+
+	return nil
+}
+
+// overlapsSource is the condition that the row in table overlaps one of
+// the busy times imported from source.
+func (p *busyTimeRepo) overlapsSource(table string) string {
+	return "EXISTS (SELECT 1 FROM " + p.tableName + " b" +
+		" WHERE b.doctor_id = " + table + ".doctor_id AND b.source = ?" +
+		" AND b.start_time < " + table + ".end_time" +
+		" AND b.end_time > " + table + ".start_time)"
+}
+
+// blockedSlots counts the doctor's upcoming free slots the source blocks.
+func (p *busyTimeRepo) blockedSlots(ctx context.Context, tx pgx.Tx, doctorID, source string) (int, error) {
+	query, args, err := p.db.Sq.Builder.
+		Select("COUNT(*)").
+		From(p.tableNameAvailability+" a").
+		Where(p.db.Sq.Equal("a.doctor_id", doctorID)).
+		Where("a.is_booked IS NOT TRUE").
+		Where("a.end_time > now()").
+		Where(p.overlapsSource("a"), source).
+		ToSql()
+	if err != nil {
+		return 0, p.db.ErrSQLBuild(err, p.tableNameAvailability+" blocked count")
+	}
+
+	var count int
+	if err = tx.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, p.db.Error(err)
+	}
+
+	return count, nil
+}
+
+// conflicts lists the doctor's upcoming active appointments that fall into
+// a busy time of the source.
+func (p *busyTimeRepo) conflicts(ctx context.Context, tx pgx.Tx, doctorID, source string) ([]*entity.Appointment, error) {
+	query, args, err := p.db.Sq.Builder.
+		Select(appointmentColumns("a")...).
+		From(p.tableNameAppointment+" a").
+		Where(p.db.Sq.Equal("a.doctor_id", doctorID)).
+		Where(p.db.Sq.Equal("a.status", []string{entity.AppointmentStatusScheduled, entity.AppointmentStatusConfirmed})).
+		Where("a.end_time > now()").
+		Where(p.overlapsSource("a"), source).
+		OrderBy("a.start_time").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableNameAppointment+" busy conflicts")
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	conflicts := []*entity.Appointment{}
+	for rows.Next() {
+		var appointment entity.Appointment
+		if err = scanAppointment(rows, &appointment); err != nil {
+			return nil, p.db.Error(err)
+		}
+		conflicts = append(conflicts, &appointment)
+	}
+
+	return conflicts, rows.Err()
+}
+
+// List returns the doctor's busy times, from every source, overlapping
+// [from, to).
+func (p *busyTimeRepo) List(ctx context.Context, doctorID string, from, to time.Time) ([]*entity.BusyTime, error) {
+	return p.listBusyTimes(ctx, p.db, map[string]any{"doctor_id": doctorID}, from, to)
+}
+
+// DeleteSource drops every busy time imported from source, unblocking
+// the slots it covered.
+func (p *busyTimeRepo) DeleteSource(ctx context.Context, doctorID, source string) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(p.db.Sq.Equal("doctor_id", doctorID)).
+		Where(p.db.Sq.Equal("source", source)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" delete source")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return entity.ErrorNotFound
+	}
+
+	return nil
+}
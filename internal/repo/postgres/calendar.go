@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+)
+
+const (
+	calendarFeedTableName = "calendar_feeds"
+)
+
+type calendarFeedRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewCalendarFeedRepo(db *postgres.PostgresDB) interfaces.CalendarFeed {
+	return &calendarFeedRepo{
+		db:        db,
+		tableName: calendarFeedTableName,
+	}
+}
+
+// Save stores the user's feed token, replacing the previous one.
+func (p *calendarFeedRepo) Save(ctx context.Context, feed *entity.CalendarFeed) (*entity.CalendarFeed, error) {
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(map[string]any{
+			"user_id":    feed.UserID,
+			"token":      feed.Token,
+			"created_at": time.Now(),
+		}).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET token = EXCLUDED.token, created_at = EXCLUDED.created_at").
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" save")
+	}
+
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&feed.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return feed, nil
+}
+
+func (p *calendarFeedRepo) GetByToken(ctx context.Context, token string) (*entity.CalendarFeed, error) {
+	query, args, err := p.db.Sq.Builder.
+		Select("user_id", "token", "created_at").
+		From(p.tableName).
+		Where(p.db.Sq.Equal("token", token)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var feed entity.CalendarFeed
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&feed.UserID, &feed.Token, &feed.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &feed, nil
+}
+
+func (p *calendarFeedRepo) Delete(ctx context.Context, userID string) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(p.db.Sq.Equal("user_id", userID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" delete")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return entity.ErrorNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	closureTableName = "closures"
+)
+
+type closureRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewClosureRepo(db *postgres.PostgresDB) interfaces.Closure {
+	return &closureRepo{
+		db:        db,
+		tableName: closureTableName,
+	}
+}
+
+func scanClosure(row pgx.Row, closure *entity.Closure) error {
+	return row.Scan(
+		&closure.ID,
+		&closure.BranchID,
+		&closure.Date,
+		&closure.EndDate,
+		&closure.FromTime,
+		&closure.ToTime,
+		&closure.Reason,
+		&closure.StartTime,
+		&closure.EndTime,
+		&closure.CreatedAt,
+	)
+}
+
+func (p *closureRepo) Create(ctx context.Context, closure *entity.Closure) (*entity.Closure, error) {
+	data := map[string]any{
+		"branch_id":  closure.BranchID,
+		"start_date": closure.Date,
+		"end_date":   closure.EndDate,
+		"reason":     closure.Reason,
+		"start_time": closure.StartTime,
+		"end_time":   closure.EndTime,
+	}
+	if closure.FromTime != "" {
+		data["from_time"] = closure.FromTime
+		data["to_time"] = closure.ToTime
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(data).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&closure.ID, &closure.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return closure, nil
+}
+
+// List returns the closures that have not ended before from, earliest
+// first. A branchID limits them to the ones closing that branch, including
+// the hospital-wide ones.
+func (p *closureRepo) List(ctx context.Context, from time.Time, branchID int64) ([]*entity.Closure, error) {
+	queryBuilder := p.db.Sq.Builder.
+		Select(
+			"id",
+			"branch_id",
+			"to_char(start_date, 'YYYY-MM-DD')",
+			"to_char(end_date, 'YYYY-MM-DD')",
+			"COALESCE(to_char(from_time, 'HH24:MI'), '')",
+			"COALESCE(to_char(to_time, 'HH24:MI'), '')",
+			"reason",
+			"start_time",
+			"end_time",
+			"created_at",
+		).
+		From(p.tableName).
+		Where(p.db.Sq.Gt("end_time", from)).
+		OrderBy("start_time")
+	if branchID != 0 {
+		queryBuilder = queryBuilder.Where("(branch_id IS NULL OR branch_id = ?)", branchID)
+	}
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var closures []*entity.Closure
+	for rows.Next() {
+		var closure entity.Closure
+		if err = scanClosure(rows, &closure); err != nil {
+			return nil, p.db.Error(err)
+		}
+		closures = append(closures, &closure)
+	}
+
+	return closures, rows.Err()
+}
+
+func (p *closureRepo) Delete(ctx context.Context, id int64) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(p.db.Sq.Equal("id", id)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" delete")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return entity.ErrorNotFound
+	}
+
+	return nil
+}
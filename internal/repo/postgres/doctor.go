@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/Abdulazizxoshimov/Hospital/entity"
 	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
@@ -15,6 +16,10 @@ import (
 
 const (
 	doctorTableName = "doctors"
+
+	// doctorBranchesColumn selects the IDs of the branches a doctor works at.
+	doctorBranchesColumn = "ARRAY(SELECT branch_id::bigint FROM " + doctorBranchTableName +
+		" WHERE doctor_id = " + doctorTableName + ".id ORDER BY branch_id)"
 )
 
 type doctorRepo struct {
@@ -41,6 +46,7 @@ func (p *doctorRepo) Create(ctx context.Context, doctor *entity.Doctor) (*entity
 		"specialization": doctor.Specialization,
 		"working_hours":  doctor.Working_hour,
 		"extra_info":     extraInfoJSON,
+		"languages":      doctorLanguages(doctor.Languages),
 	}
 
 	query, args, err := p.db.Sq.Builder.Insert(p.tableName).SetMap(doctorData).ToSql()
@@ -61,7 +67,7 @@ func (p *doctorRepo) Get(ctx context.Context, doctorID string) (*entity.Doctor,
 	var extraInfoJSON []byte
 
 	query, args, err := p.db.Sq.Builder.
-		Select("id", "user_id", "specialization", "working_hours", "extra_info").
+		Select("id", "user_id", "specialization", "working_hours", "extra_info", "languages", doctorBranchesColumn).
 		From(p.tableName).
 		Where(p.db.Sq.Equal("id", doctorID)).
 		ToSql()
@@ -75,6 +81,8 @@ func (p *doctorRepo) Get(ctx context.Context, doctorID string) (*entity.Doctor,
 		&doctor.Specialization,
 		&doctor.Working_hour,
 		&extraInfoJSON,
+		&doctor.Languages,
+		&doctor.BranchIDs,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -90,6 +98,39 @@ func (p *doctorRepo) Get(ctx context.Context, doctorID string) (*entity.Doctor,
 	return &doctor, nil
 }
 
+func (p *doctorRepo) GetByUserID(ctx context.Context, userID string) (*entity.Doctor, error) {
+	var doctor entity.Doctor
+	var extraInfoJSON []byte
+
+	query, args, err := p.db.Sq.Builder.
+		Select("id", "user_id", "specialization", "working_hours", "extra_info", "languages", doctorBranchesColumn).
+		From(p.tableName).
+		Where(p.db.Sq.Equal("user_id", userID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, "doctor get by user")
+	}
+
+	err = p.db.QueryRow(ctx, query, args...).Scan(
+		&doctor.ID,
+		&doctor.UserID,
+		&doctor.Specialization,
+		&doctor.Working_hour,
+		&extraInfoJSON,
+		&doctor.Languages,
+		&doctor.BranchIDs,
+	)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := json.Unmarshal(extraInfoJSON, &doctor.ExtraInfo); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal extra_info: %w", err)
+	}
+
+	return &doctor, nil
+}
+
 func (p *doctorRepo) Update(ctx context.Context, doctor *entity.Doctor) (*entity.Doctor, error) {
 	extraInfoJSON, err := json.Marshal(doctor.ExtraInfo)
 	if err != nil {
@@ -100,6 +141,7 @@ func (p *doctorRepo) Update(ctx context.Context, doctor *entity.Doctor) (*entity
 		"specialization": doctor.Specialization,
 		"extra_info":     extraInfoJSON,
 		"working_hours":  doctor.Working_hour,
+		"languages":      doctorLanguages(doctor.Languages),
 	}
 
 	sqlStr, args, err := p.db.Sq.Builder.
@@ -147,16 +189,25 @@ func (p *doctorRepo) List(ctx context.Context, req *entity.ListRequest) (*entity
 	var doctors entity.ListDoctorRes
 
 	queryBuilder := p.db.Sq.Builder.
-		Select("id", "user_id", "specialization", "working_hours", "extra_info").
+		Select("id", "user_id", "specialization", "working_hours", "extra_info", "languages", doctorBranchesColumn).
 		From(p.tableName).
 		PlaceholderFormat(squirrel.Dollar)
 
+	where := squirrel.And{}
 	if name, exists := req.Filter["name"]; exists {
-		queryBuilder = queryBuilder.Where("LOWER(name) LIKE LOWER($1)", "%"+name+"%")
+		where = append(where, squirrel.Expr("LOWER(name) LIKE LOWER(?)", "%"+name+"%"))
 	}
 	if specialization, exists := req.Filter["specialization"]; exists {
-		queryBuilder = queryBuilder.Where("LOWER(specialization) LIKE LOWER($2)", "%"+specialization+"%")
+		where = append(where, squirrel.Expr("LOWER(specialization) LIKE LOWER(?)", "%"+specialization+"%"))
+	}
+	if value, exists := req.Filter["branch_id"]; exists {
+		branchID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid branch_id filter: %w", err)
+		}
+		where = append(where, squirrel.Expr("id IN (SELECT doctor_id FROM "+doctorBranchTableName+" WHERE branch_id = ?)", branchID))
 	}
+	queryBuilder = queryBuilder.Where(where)
 
 	if req.Limit > 0 {
 		queryBuilder = queryBuilder.Limit(uint64(req.Limit)).Offset(uint64(req.Offset))
@@ -177,7 +228,7 @@ func (p *doctorRepo) List(ctx context.Context, req *entity.ListRequest) (*entity
 
 	for rows.Next() {
 		var doctor entity.Doctor
-		if err := rows.Scan(&doctor.ID, &doctor.UserID, &doctor.Specialization, &doctor.Working_hour, &doctor.ExtraInfo); err != nil {
+		if err := rows.Scan(&doctor.ID, &doctor.UserID, &doctor.Specialization, &doctor.Working_hour, &doctor.ExtraInfo, &doctor.Languages, &doctor.BranchIDs); err != nil {
 			return nil, p.db.Error(err)
 		}
 		doctors.Doctors = append(doctors.Doctors, &doctor)
@@ -185,7 +236,8 @@ func (p *doctorRepo) List(ctx context.Context, req *entity.ListRequest) (*entity
 
 	countQuery := p.db.Sq.Builder.
 		Select("COUNT(*)").
-		From(p.tableName)
+		From(p.tableName).
+		Where(where)
 
 	countSQL, countArgs, err := countQuery.ToSql()
 	if err != nil {
@@ -198,3 +250,12 @@ func (p *doctorRepo) List(ctx context.Context, req *entity.ListRequest) (*entity
 
 	return &doctors, nil
 }
+
+// doctorLanguages keeps the languages column an empty array rather than
+// NULL when none are given.
+func doctorLanguages(languages []string) []string {
+	if languages == nil {
+		return []string{}
+	}
+	return languages
+}
@@ -0,0 +1,324 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	encounterTableName = "encounters"
+	addendumTableName  = "encounter_addenda"
+)
+
+type encounterRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewEncounterRepo(db *postgres.PostgresDB) interfaces.Encounter {
+	return &encounterRepo{
+		db:        db,
+		tableName: encounterTableName,
+	}
+}
+
+func (p *encounterRepo) encounterSelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"e.id",
+			"e.appointment_id",
+			"a.doctor_id",
+			"a.patient_id",
+			"e.status",
+			"e.chief_complaint",
+			"e.subjective",
+			"e.objective",
+			"e.assessment",
+			"e.plan",
+			"e.diagnoses",
+			"COALESCE(e.created_by::text, '')",
+			"COALESCE(e.signed_by::text, '')",
+			"e.signed_at",
+			"e.created_at",
+			"e.updated_at",
+		).
+		From(p.tableName + " e").
+		Join(tableNameAppointment + " a ON a.id = e.appointment_id")
+}
+
+func scanEncounter(row pgx.Row, encounter *entity.Encounter) error {
+	var diagnosesJSON []byte
+
+	if err := row.Scan(
+		&encounter.ID,
+		&encounter.AppointmentID,
+		&encounter.DoctorID,
+		&encounter.PatientID,
+		&encounter.Status,
+		&encounter.ChiefComplaint,
+		&encounter.Subjective,
+		&encounter.Objective,
+		&encounter.Assessment,
+		&encounter.Plan,
+		&diagnosesJSON,
+		&encounter.CreatedBy,
+		&encounter.SignedBy,
+		&encounter.SignedAt,
+		&encounter.CreatedAt,
+		&encounter.UpdatedAt,
+	); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(diagnosesJSON, &encounter.Diagnoses); err != nil {
+		return fmt.Errorf("failed to unmarshal diagnoses: %w", err)
+	}
+
+	return nil
+}
+
+// addenda loads the addenda of the given encounters, oldest first, keyed
+// by encounter. Every id gets an entry, empty when it has none.
+func (p *encounterRepo) addenda(ctx context.Context, ids []int64) (map[int64][]*entity.Addendum, error) {
+	addenda := make(map[int64][]*entity.Addendum, len(ids))
+	if len(ids) == 0 {
+		return addenda, nil
+	}
+	for _, id := range ids {
+		addenda[id] = []*entity.Addendum{}
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Select("id", "encounter_id", "text", "COALESCE(created_by::text, '')", "created_at").
+		From(addendumTableName).
+		Where(p.db.Sq.Equal("encounter_id", ids)).
+		OrderBy("created_at", "id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, addendumTableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var addendum entity.Addendum
+		if err = rows.Scan(&addendum.ID, &addendum.EncounterID, &addendum.Text, &addendum.CreatedBy, &addendum.CreatedAt); err != nil {
+			return nil, p.db.Error(err)
+		}
+		addenda[addendum.EncounterID] = append(addenda[addendum.EncounterID], &addendum)
+	}
+
+	return addenda, rows.Err()
+}
+
+// Save creates the draft encounter of the appointment or replaces its
+// contents. A signed encounter is left alone and ErrorEncounterSigned
+// returned.
+func (p *encounterRepo) Save(ctx context.Context, encounter *entity.Encounter) (*entity.Encounter, error) {
+	diagnosesJSON, err := json.Marshal(encounter.Diagnoses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal diagnoses: %w", err)
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(map[string]any{
+			"appointment_id":  encounter.AppointmentID,
+			"chief_complaint": encounter.ChiefComplaint,
+			"subjective":      encounter.Subjective,
+			"objective":       encounter.Objective,
+			"assessment":      encounter.Assessment,
+			"plan":            encounter.Plan,
+			"diagnoses":       diagnosesJSON,
+			"created_by":      encounter.CreatedBy,
+			"updated_at":      time.Now(),
+		}).
+		Suffix("ON CONFLICT (appointment_id) DO UPDATE SET chief_complaint = EXCLUDED.chief_complaint, subjective = EXCLUDED.subjective, objective = EXCLUDED.objective, assessment = EXCLUDED.assessment, plan = EXCLUDED.plan, diagnoses = EXCLUDED.diagnoses, updated_at = EXCLUDED.updated_at").
+		Suffix("WHERE "+p.tableName+".status = ?", entity.EncounterStatusDraft).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" save")
+	}
+
+	// the conflicting row is only returned when it was updated, that is
+	// while it is a draft
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&encounter.ID); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, entity.ErrorEncounterSigned
+		}
+		return nil, p.db.Error(err)
+	}
+
+	return p.GetByAppointment(ctx, encounter.AppointmentID)
+}
+
+func (p *encounterRepo) GetByAppointment(ctx context.Context, appointmentID int64) (*entity.Encounter, error) {
+	query, args, err := p.encounterSelectQueryPrefix().
+		Where(p.db.Sq.Equal("e.appointment_id", appointmentID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var encounter entity.Encounter
+	if err = scanEncounter(p.db.QueryRow(ctx, query, args...), &encounter); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	addenda, err := p.addenda(ctx, []int64{encounter.ID})
+	if err != nil {
+		return nil, err
+	}
+	encounter.Addenda = addenda[encounter.ID]
+
+	return &encounter, nil
+}
+
+// Sign makes the draft encounter of the appointment final.
+func (p *encounterRepo) Sign(ctx context.Context, appointmentID int64, signedBy string) (*entity.Encounter, error) {
+	now := time.Now()
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(map[string]any{
+			"status":     entity.EncounterStatusSigned,
+			"signed_by":  signedBy,
+			"signed_at":  now,
+			"updated_at": now,
+		}).
+		Where(p.db.Sq.Equal("appointment_id", appointmentID)).
+		Where(p.db.Sq.Equal("status", entity.EncounterStatusDraft)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" sign")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	encounter, err := p.GetByAppointment(ctx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return nil, entity.ErrorEncounterSigned
+	}
+
+	return encounter, nil
+}
+
+// AddAddendum amends the signed encounter of the appointment.
+func (p *encounterRepo) AddAddendum(ctx context.Context, appointmentID int64, addendum *entity.Addendum) (*entity.Addendum, error) {
+	encounter, err := p.GetByAppointment(ctx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	// signing is final, so the encounter cannot go back to a draft
+	// before the addendum is stored
+	if encounter.Status != entity.EncounterStatusSigned {
+		return nil, entity.ErrorEncounterNotSigned
+	}
+
+	addendum.EncounterID = encounter.ID
+	query, args, err := p.db.Sq.Builder.
+		Insert(addendumTableName).
+		SetMap(map[string]any{
+			"encounter_id": addendum.EncounterID,
+			"text":         addendum.Text,
+			"created_by":   addendum.CreatedBy,
+		}).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, addendumTableName+" create")
+	}
+
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&addendum.ID, &addendum.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return addendum, nil
+}
+
+// Summaries returns the patient's signed encounters, latest visit first.
+func (p *encounterRepo) Summaries(ctx context.Context, patientID string) ([]*entity.EncounterSummary, error) {
+	query, args, err := p.db.Sq.Builder.
+		Select(
+			"e.id",
+			"e.appointment_id",
+			"a.doctor_id",
+			"a.start_time",
+			"e.chief_complaint",
+			"e.diagnoses",
+			"e.plan",
+			"e.signed_at",
+		).
+		From(p.tableName + " e").
+		Join(tableNameAppointment + " a ON a.id = e.appointment_id").
+		Where(p.db.Sq.Equal("a.patient_id", patientID)).
+		Where(p.db.Sq.Equal("e.status", entity.EncounterStatusSigned)).
+		OrderBy("a.start_time DESC").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" summaries")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	summaries := []*entity.EncounterSummary{}
+	var ids []int64
+	for rows.Next() {
+		var (
+			summary       entity.EncounterSummary
+			diagnosesJSON []byte
+		)
+		if err = rows.Scan(
+			&summary.EncounterID,
+			&summary.AppointmentID,
+			&summary.DoctorID,
+			&summary.StartTime,
+			&summary.ChiefComplaint,
+			&diagnosesJSON,
+			&summary.Plan,
+			&summary.SignedAt,
+		); err != nil {
+			return nil, p.db.Error(err)
+		}
+		if err = json.Unmarshal(diagnosesJSON, &summary.Diagnoses); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal diagnoses: %w", err)
+		}
+		summaries = append(summaries, &summary)
+		ids = append(ids, summary.EncounterID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	addenda, err := p.addenda(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		summary.Addenda = addenda[summary.EncounterID]
+	}
+
+	return summaries, nil
+}
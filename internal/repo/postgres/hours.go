@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/jackc/pgx/v4"
+)
+
+// setOpeningHours replaces the weekly hours stored in table for the row
+// whose id is in column.
+func setOpeningHours(ctx context.Context, db *postgres.PostgresDB, tx pgx.Tx, table, column string, id int64, hours []*entity.OpeningHours) error {
+	query, args, err := db.Sq.Builder.
+		Delete(table).
+		Where(db.Sq.Equal(column, id)).
+		ToSql()
+	if err != nil {
+		return db.ErrSQLBuild(err, table+" delete")
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return db.Error(err)
+	}
+
+	if len(hours) == 0 {
+		return nil
+	}
+
+	insert := db.Sq.Builder.
+		Insert(table).
+		Columns(column, "weekday", "start_time", "end_time")
+	for _, h := range hours {
+		insert = insert.Values(id, h.Weekday, h.StartTime, h.EndTime)
+	}
+
+	query, args, err = insert.ToSql()
+	if err != nil {
+		return db.ErrSQLBuild(err, table+" insert")
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return db.Error(err)
+	}
+
+	return nil
+}
+
+// openingHours loads the weekly hours stored in table for the given ids,
+// keyed by id. Every id gets an entry, empty when it has no hours.
+func openingHours(ctx context.Context, db *postgres.PostgresDB, table, column string, ids []int64) (map[int64][]*entity.OpeningHours, error) {
+	hours := make(map[int64][]*entity.OpeningHours, len(ids))
+	if len(ids) == 0 {
+		return hours, nil
+	}
+	for _, id := range ids {
+		hours[id] = []*entity.OpeningHours{}
+	}
+
+	query, args, err := db.Sq.Builder.
+		Select(column, "weekday", "to_char(start_time, 'HH24:MI')", "to_char(end_time, 'HH24:MI')").
+		From(table).
+		Where(db.Sq.Equal(column, ids)).
+		OrderBy(column, "weekday", "start_time").
+		ToSql()
+	if err != nil {
+		return nil, db.ErrSQLBuild(err, table+" list")
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, db.Error(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id int64
+			h  entity.OpeningHours
+		)
+		if err = rows.Scan(&id, &h.Weekday, &h.StartTime, &h.EndTime); err != nil {
+			return nil, db.Error(err)
+		}
+		hours[id] = append(hours[id], &h)
+	}
+
+	return hours, rows.Err()
+}
@@ -0,0 +1,347 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	labOrderTableName  = "lab_orders"
+	labResultTableName = "lab_results"
+)
+
+type labOrderRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewLabOrderRepo(db *postgres.PostgresDB) interfaces.LabOrder {
+	return &labOrderRepo{
+		db:        db,
+		tableName: labOrderTableName,
+	}
+}
+
+func (p *labOrderRepo) labOrderSelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"o.id",
+			"o.appointment_id",
+			"a.patient_id",
+			"a.doctor_id",
+			"o.test_code",
+			"o.test_name",
+			"o.notes",
+			"o.status",
+			"o.report",
+			"COALESCE(o.ordered_by::text, '')",
+			"COALESCE(o.resulted_by::text, '')",
+			"o.resulted_at",
+			"COALESCE(o.released_by::text, '')",
+			"o.released_at",
+			"o.created_at",
+		).
+		From(p.tableName + " o").
+		Join(tableNameAppointment + " a ON a.id = o.appointment_id")
+}
+
+func scanLabOrder(row pgx.Row, order *entity.LabOrder) error {
+	return row.Scan(
+		&order.ID,
+		&order.AppointmentID,
+		&order.PatientID,
+		&order.DoctorID,
+		&order.TestCode,
+		&order.TestName,
+		&order.Notes,
+		&order.Status,
+		&order.Report,
+		&order.OrderedBy,
+		&order.ResultedBy,
+		&order.ResultedAt,
+		&order.ReleasedBy,
+		&order.ReleasedAt,
+		&order.CreatedAt,
+	)
+}
+
+// results loads the results of the given orders and flags the orders with
+// abnormal ones.
+func (p *labOrderRepo) results(ctx context.Context, orders ...*entity.LabOrder) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*entity.LabOrder, len(orders))
+	ids := make([]int64, 0, len(orders))
+	for _, order := range orders {
+		order.Results = []*entity.LabResult{}
+		byID[order.ID] = order
+		ids = append(ids, order.ID)
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Select(
+			"order_id",
+			"analyte",
+			"value",
+			"text_value",
+			"unit",
+			"reference_low",
+			"reference_high",
+			"flag",
+		).
+		From(labResultTableName).
+		Where(p.db.Sq.Equal("order_id", ids)).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, labResultTableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			orderID int64
+			result  entity.LabResult
+		)
+		if err = rows.Scan(
+			&orderID,
+			&result.Analyte,
+			&result.Value,
+			&result.Text,
+			&result.Unit,
+			&result.ReferenceLow,
+			&result.ReferenceHigh,
+			&result.Flag,
+		); err != nil {
+			return p.db.Error(err)
+		}
+		order := byID[orderID]
+		order.Results = append(order.Results, &result)
+		if result.Flag != "" {
+			order.Abnormal = true
+		}
+	}
+
+	return rows.Err()
+}
+
+func (p *labOrderRepo) Create(ctx context.Context, order *entity.LabOrder) (*entity.LabOrder, error) {
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(map[string]any{
+			"appointment_id": order.AppointmentID,
+			"test_code":      order.TestCode,
+			"test_name":      order.TestName,
+			"notes":          order.Notes,
+			"ordered_by":     order.OrderedBy,
+		}).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&order.ID); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return p.Get(ctx, order.ID)
+}
+
+func (p *labOrderRepo) Get(ctx context.Context, orderID int64) (*entity.LabOrder, error) {
+	query, args, err := p.labOrderSelectQueryPrefix().
+		Where(p.db.Sq.Equal("o.id", orderID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var order entity.LabOrder
+	if err = scanLabOrder(p.db.QueryRow(ctx, query, args...), &order); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = p.results(ctx, &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// List returns the matching orders, oldest first so the lab can work
+// through them in order.
+func (p *labOrderRepo) List(ctx context.Context, filter *entity.LabOrderFilter) ([]*entity.LabOrder, error) {
+	conditions := squirrel.And{}
+	if filter.AppointmentID != 0 {
+		conditions = append(conditions, p.db.Sq.Equal("o.appointment_id", filter.AppointmentID))
+	}
+	if filter.PatientID != "" {
+		conditions = append(conditions, p.db.Sq.Equal("a.patient_id", filter.PatientID))
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, p.db.Sq.Equal("o.status", filter.Status))
+	}
+
+	query, args, err := p.labOrderSelectQueryPrefix().
+		Where(conditions).
+		OrderBy("o.created_at", "o.id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	orders := []*entity.LabOrder{}
+	for rows.Next() {
+		var order entity.LabOrder
+		if err = scanLabOrder(rows, &order); err != nil {
+			return nil, p.db.Error(err)
+		}
+		orders = append(orders, &order)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = p.results(ctx, orders...); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// RecordResults replaces the results and report of an order that is not
+// released or cancelled yet.
+func (p *labOrderRepo) RecordResults(ctx context.Context, orderID int64, results []*entity.LabResult, report, resultedBy string) (*entity.LabOrder, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(map[string]any{
+			"status":      entity.LabOrderStatusResulted,
+			"report":      report,
+			"resulted_by": resultedBy,
+			"resulted_at": time.Now(),
+		}).
+		Where(p.db.Sq.Equal("id", orderID)).
+		Where(p.db.Sq.Equal("status", []string{entity.LabOrderStatusOrdered, entity.LabOrderStatusResulted})).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" record results")
+	}
+
+	commandTag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		if _, err = p.Get(ctx, orderID); err != nil {
+			return nil, err
+		}
+		return nil, entity.ErrorLabOrderStatus
+	}
+
+	query, args, err = p.db.Sq.Builder.
+		Delete(labResultTableName).
+		Where(p.db.Sq.Equal("order_id", orderID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, labResultTableName+" delete")
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if len(results) != 0 {
+		insert := p.db.Sq.Builder.
+			Insert(labResultTableName).
+			Columns("order_id", "analyte", "value", "text_value", "unit", "reference_low", "reference_high", "flag")
+		for _, result := range results {
+			insert = insert.Values(orderID, result.Analyte, result.Value, result.Text, result.Unit, result.ReferenceLow, result.ReferenceHigh, result.Flag)
+		}
+
+		query, args, err = insert.ToSql()
+		if err != nil {
+			return nil, p.db.ErrSQLBuild(err, labResultTableName+" create")
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return nil, p.db.Error(err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return p.Get(ctx, orderID)
+}
+
+// Release makes the recorded results of the order visible to the doctor
+// and the patient. Released results are final.
+func (p *labOrderRepo) Release(ctx context.Context, orderID int64, releasedBy string) (*entity.LabOrder, error) {
+	return p.transition(ctx, orderID, entity.LabOrderStatusResulted, map[string]any{
+		"status":      entity.LabOrderStatusReleased,
+		"released_by": releasedBy,
+		"released_at": time.Now(),
+	})
+}
+
+// Cancel withdraws an order the lab has not recorded results for yet.
+func (p *labOrderRepo) Cancel(ctx context.Context, orderID int64) (*entity.LabOrder, error) {
+	return p.transition(ctx, orderID, entity.LabOrderStatusOrdered, map[string]any{
+		"status": entity.LabOrderStatusCancelled,
+	})
+}
+
+// transition updates the order when it is in the from status and returns
+// ErrorLabOrderStatus otherwise.
+func (p *labOrderRepo) transition(ctx context.Context, orderID int64, from string, set map[string]any) (*entity.LabOrder, error) {
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(set).
+		Where(p.db.Sq.Equal("id", orderID)).
+		Where(p.db.Sq.Equal("status", from)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" update status")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	order, err := p.Get(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return nil, entity.ErrorLabOrderStatus
+	}
+
+	return order, nil
+}
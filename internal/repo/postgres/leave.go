@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	doctorLeaveTableName = "doctor_leaves"
+)
+
+type doctorLeaveRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewDoctorLeaveRepo(db *postgres.PostgresDB) interfaces.DoctorLeave {
+	return &doctorLeaveRepo{
+		db:        db,
+		tableName: doctorLeaveTableName,
+	}
+}
+
+func (p *doctorLeaveRepo) leaveSelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"id",
+			"doctor_id",
+			"to_char(start_date, 'YYYY-MM-DD')",
+			"to_char(end_date, 'YYYY-MM-DD')",
+			"start_time",
+			"end_time",
+			"reason",
+			"COALESCE(created_by::text, '')",
+			"created_at",
+		).From(p.tableName)
+}
+
+func scanDoctorLeave(row pgx.Row, leave *entity.DoctorLeave) error {
+	return row.Scan(
+		&leave.ID,
+		&leave.DoctorID,
+		&leave.StartDate,
+		&leave.EndDate,
+		&leave.StartTime,
+		&leave.EndTime,
+		&leave.Reason,
+		&leave.CreatedBy,
+		&leave.CreatedAt,
+	)
+}
+
+func (p *doctorLeaveRepo) Create(ctx context.Context, leave *entity.DoctorLeave) (*entity.DoctorLeave, error) {
+	data := map[string]any{
+		"doctor_id":  leave.DoctorID,
+		"start_date": leave.StartDate,
+		"end_date":   leave.EndDate,
+		"start_time": leave.StartTime,
+		"end_time":   leave.EndTime,
+		"reason":     leave.Reason,
+	}
+	if leave.CreatedBy != "" {
+		data["created_by"] = leave.CreatedBy
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(data).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&leave.ID, &leave.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return leave, nil
+}
+
+func (p *doctorLeaveRepo) Get(ctx context.Context, id int64) (*entity.DoctorLeave, error) {
+	query, args, err := p.leaveSelectQueryPrefix().
+		Where(p.db.Sq.Equal("id", id)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var leave entity.DoctorLeave
+	if err = scanDoctorLeave(p.db.QueryRow(ctx, query, args...), &leave); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &leave, nil
+}
+
+// List returns the doctor's leaves that have not ended before from.
+func (p *doctorLeaveRepo) List(ctx context.Context, doctorID string, from time.Time) ([]*entity.DoctorLeave, error) {
+	query, args, err := p.leaveSelectQueryPrefix().
+		Where(p.db.Sq.Equal("doctor_id", doctorID)).
+		Where(p.db.Sq.Gt("end_time", from)).
+		OrderBy("start_time").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var leaves []*entity.DoctorLeave
+	for rows.Next() {
+		var leave entity.DoctorLeave
+		if err = scanDoctorLeave(rows, &leave); err != nil {
+			return nil, p.db.Error(err)
+		}
+		leaves = append(leaves, &leave)
+	}
+
+	return leaves, rows.Err()
+}
+
+func (p *doctorLeaveRepo) Delete(ctx context.Context, id int64) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(p.db.Sq.Equal("id", id)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" delete")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return entity.ErrorNotFound
+	}
+
+	return nil
+}
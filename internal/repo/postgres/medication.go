@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+const medicationTableName = "medications"
+
+type medicationRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewMedicationRepo(db *postgres.PostgresDB) interfaces.Medication {
+	return &medicationRepo{
+		db:        db,
+		tableName: medicationTableName,
+	}
+}
+
+func (p *medicationRepo) medicationSelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"id",
+			"name",
+			"ingredients",
+			"form",
+			"strength",
+			"active",
+			"created_at",
+		).From(p.tableName)
+}
+
+func scanMedication(row pgx.Row, medication *entity.Medication) error {
+	return row.Scan(
+		&medication.ID,
+		&medication.Name,
+		&medication.Ingredients,
+		&medication.Form,
+		&medication.Strength,
+		&medication.Active,
+		&medication.CreatedAt,
+	)
+}
+
+func (p *medicationRepo) Create(ctx context.Context, medication *entity.Medication) (*entity.Medication, error) {
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(map[string]any{
+			"name":        medication.Name,
+			"ingredients": medication.Ingredients,
+			"form":        medication.Form,
+			"strength":    medication.Strength,
+			"active":      medication.Active,
+		}).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&medication.ID, &medication.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return medication, nil
+}
+
+func (p *medicationRepo) Update(ctx context.Context, medication *entity.Medication) (*entity.Medication, error) {
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(map[string]any{
+			"name":        medication.Name,
+			"ingredients": medication.Ingredients,
+			"form":        medication.Form,
+			"strength":    medication.Strength,
+			"active":      medication.Active,
+		}).
+		Where(p.db.Sq.Equal("id", medication.ID)).
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" update")
+	}
+
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&medication.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return medication, nil
+}
+
+// GetMany returns the given medications; unknown ids are left out.
+func (p *medicationRepo) GetMany(ctx context.Context, medicationIDs []int64) ([]*entity.Medication, error) {
+	if len(medicationIDs) == 0 {
+		return []*entity.Medication{}, nil
+	}
+
+	return p.list(ctx, p.medicationSelectQueryPrefix().
+		Where(p.db.Sq.Equal("id", medicationIDs)).
+		OrderBy("id"))
+}
+
+// List returns the catalogue by name, narrowed to medications whose name
+// or ingredients contain search.
+func (p *medicationRepo) List(ctx context.Context, search string, activeOnly bool) ([]*entity.Medication, error) {
+	builder := p.medicationSelectQueryPrefix()
+	if search != "" {
+		pattern := "%" + search + "%"
+		builder = builder.Where("(name ILIKE ? OR array_to_string(ingredients, ' ') ILIKE ?)", pattern, pattern)
+	}
+	if activeOnly {
+		builder = builder.Where(p.db.Sq.Equal("active", true))
+	}
+
+	return p.list(ctx, builder.OrderBy("name", "form", "strength"))
+}
+
+func (p *medicationRepo) list(ctx context.Context, builder squirrel.SelectBuilder) ([]*entity.Medication, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	medications := []*entity.Medication{}
+	for rows.Next() {
+		var medication entity.Medication
+		if err = scanMedication(rows, &medication); err != nil {
+			return nil, p.db.Error(err)
+		}
+		medications = append(medications, &medication)
+	}
+
+	return medications, rows.Err()
+}
+
+// Import adds the medications to the catalogue in one transaction. A
+// medication of the same name, form and strength is replaced and made
+// active again.
+func (p *medicationRepo) Import(ctx context.Context, medications []*entity.Medication) (int, int, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var created, updated int
+	for _, medication := range medications {
+		query, args, err := p.db.Sq.Builder.
+			Insert(p.tableName).
+			SetMap(map[string]any{
+				"name":        medication.Name,
+				"ingredients": medication.Ingredients,
+				"form":        medication.Form,
+				"strength":    medication.Strength,
+				"active":      true,
+			}).
+			Suffix("ON CONFLICT (tenant_id, name, form, strength) DO UPDATE SET ingredients = EXCLUDED.ingredients, active = true").
+			Suffix("RETURNING (xmax = 0)").
+			ToSql()
+		if err != nil {
+			return 0, 0, p.db.ErrSQLBuild(err, p.tableName+" import")
+		}
+
+		var inserted bool
+		if err = tx.QueryRow(ctx, query, args...).Scan(&inserted); err != nil {
+			return 0, 0, p.db.Error(err)
+		}
+		if inserted {
+			created++
+		} else {
+			updated++
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	return created, updated, nil
+}
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	policyTableName = "cancellation_policies"
+)
+
+type policyRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewCancellationPolicyRepo(db *postgres.PostgresDB) interfaces.CancellationPolicy {
+	return &policyRepo{
+		db:        db,
+		tableName: policyTableName,
+	}
+}
+
+func (p *policyRepo) policySelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"id",
+			"appointment_type_id",
+			"cutoff_minutes",
+			"max_reschedules",
+			"late_cancel_as_no_show",
+			"allow_admin_override",
+			"updated_at",
+		).From(p.tableName)
+}
+
+func scanPolicy(row pgx.Row, policy *entity.CancellationPolicy) error {
+	var appointmentTypeID sql.NullInt64
+
+	if err := row.Scan(
+		&policy.ID,
+		&appointmentTypeID,
+		&policy.CutoffMinutes,
+		&policy.MaxReschedules,
+		&policy.LateCancelAsNoShow,
+		&policy.AllowAdminOverride,
+		&policy.UpdatedAt,
+	); err != nil {
+		return err
+	}
+	if appointmentTypeID.Valid {
+		policy.AppointmentTypeID = &appointmentTypeID.Int64
+	}
+
+	return nil
+}
+
+// Get returns the policy for the appointment type, falling back to the
+// hospital default when the type has none. Pass 0 for the default itself.
+func (p *policyRepo) Get(ctx context.Context, appointmentTypeID int64) (*entity.CancellationPolicy, error) {
+	query, args, err := p.policySelectQueryPrefix().
+		Where(p.db.Sq.Or(
+			squirrel.Eq{"appointment_type_id": appointmentTypeID},
+			squirrel.Eq{"appointment_type_id": nil},
+		)).
+		OrderBy("appointment_type_id NULLS LAST").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var policy entity.CancellationPolicy
+	if err = scanPolicy(p.db.QueryRow(ctx, query, args...), &policy); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &policy, nil
+}
+
+func (p *policyRepo) Upsert(ctx context.Context, policy *entity.CancellationPolicy) (*entity.CancellationPolicy, error) {
+	clauses := map[string]any{
+		"cutoff_minutes":         policy.CutoffMinutes,
+		"max_reschedules":        policy.MaxReschedules,
+		"late_cancel_as_no_show": policy.LateCancelAsNoShow,
+		"allow_admin_override":   policy.AllowAdminOverride,
+		"updated_at":             time.Now(),
+	}
+
+	// the default row always exists, so it is updated in place; type
+	// policies are inserted on first use
+	var builder squirrel.Sqlizer
+	if policy.AppointmentTypeID == nil {
+		builder = p.db.Sq.Builder.
+			Update(p.tableName).
+			SetMap(clauses).
+			Where("appointment_type_id IS NULL").
+			Suffix("RETURNING id, appointment_type_id, cutoff_minutes, max_reschedules, late_cancel_as_no_show, allow_admin_override, updated_at")
+	} else {
+		clauses["appointment_type_id"] = *policy.AppointmentTypeID
+		builder = p.db.Sq.Builder.
+			Insert(p.tableName).
+			SetMap(clauses).
+			Suffix("ON CONFLICT (appointment_type_id) DO UPDATE SET cutoff_minutes = EXCLUDED.cutoff_minutes, max_reschedules = EXCLUDED.max_reschedules, late_cancel_as_no_show = EXCLUDED.late_cancel_as_no_show, allow_admin_override = EXCLUDED.allow_admin_override, updated_at = EXCLUDED.updated_at").
+			Suffix("RETURNING id, appointment_type_id, cutoff_minutes, max_reschedules, late_cancel_as_no_show, allow_admin_override, updated_at")
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" upsert")
+	}
+
+	var saved entity.CancellationPolicy
+	if err = scanPolicy(p.db.QueryRow(ctx, query, args...), &saved); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &saved, nil
+}
+
+func (p *policyRepo) List(ctx context.Context) ([]*entity.CancellationPolicy, error) {
+	query, args, err := p.policySelectQueryPrefix().
+		OrderBy("appointment_type_id NULLS FIRST").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var policies []*entity.CancellationPolicy
+	for rows.Next() {
+		var policy entity.CancellationPolicy
+		if err = scanPolicy(rows, &policy); err != nil {
+			return nil, p.db.Error(err)
+		}
+		policies = append(policies, &policy)
+	}
+
+	return policies, rows.Err()
+}
+
+// Delete removes a type specific policy so the type falls back to the
+// hospital default. The default itself cannot be deleted.
+func (p *policyRepo) Delete(ctx context.Context, appointmentTypeID int64) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(p.db.Sq.Equal("appointment_type_id", appointmentTypeID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" delete")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return entity.ErrorNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,296 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	prescriptionTableName     = "prescriptions"
+	prescriptionItemTableName = "prescription_items"
+)
+
+type prescriptionRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewPrescriptionRepo(db *postgres.PostgresDB) interfaces.Prescription {
+	return &prescriptionRepo{
+		db:        db,
+		tableName: prescriptionTableName,
+	}
+}
+
+func (p *prescriptionRepo) prescriptionSelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"p.id",
+			"p.encounter_id",
+			"e.appointment_id",
+			"a.patient_id",
+			"a.doctor_id",
+			"p.notes",
+			"p.alerts",
+			"COALESCE(p.created_by::text, '')",
+			"p.created_at",
+		).
+		From(p.tableName + " p").
+		Join(encounterTableName + " e ON e.id = p.encounter_id").
+		Join(tableNameAppointment + " a ON a.id = e.appointment_id")
+}
+
+func scanPrescription(row pgx.Row, prescription *entity.Prescription) error {
+	var alertsJSON []byte
+
+	if err := row.Scan(
+		&prescription.ID,
+		&prescription.EncounterID,
+		&prescription.AppointmentID,
+		&prescription.PatientID,
+		&prescription.DoctorID,
+		&prescription.Notes,
+		&alertsJSON,
+		&prescription.CreatedBy,
+		&prescription.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(alertsJSON, &prescription.Alerts); err != nil {
+		return fmt.Errorf("failed to unmarshal alerts: %w", err)
+	}
+
+	return nil
+}
+
+// items loads the items of the given prescriptions.
+func (p *prescriptionRepo) items(ctx context.Context, prescriptions ...*entity.Prescription) error {
+	if len(prescriptions) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*entity.Prescription, len(prescriptions))
+	ids := make([]int64, 0, len(prescriptions))
+	for _, prescription := range prescriptions {
+		prescription.Items = []*entity.PrescriptionItem{}
+		byID[prescription.ID] = prescription
+		ids = append(ids, prescription.ID)
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Select(
+			"i.prescription_id",
+			"i.medication_id",
+			"m.name",
+			"m.form",
+			"m.strength",
+			"i.dosage",
+			"i.frequency",
+			"i.duration_days",
+			"i.instructions",
+		).
+		From(prescriptionItemTableName + " i").
+		Join(medicationTableName + " m ON m.id = i.medication_id").
+		Where(p.db.Sq.Equal("i.prescription_id", ids)).
+		OrderBy("i.id").
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, prescriptionItemTableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			prescriptionID int64
+			item           entity.PrescriptionItem
+		)
+		if err = rows.Scan(
+			&prescriptionID,
+			&item.MedicationID,
+			&item.Medication,
+			&item.Form,
+			&item.Strength,
+			&item.Dosage,
+			&item.Frequency,
+			&item.DurationDays,
+			&item.Instructions,
+		); err != nil {
+			return p.db.Error(err)
+		}
+		byID[prescriptionID].Items = append(byID[prescriptionID].Items, &item)
+	}
+
+	return rows.Err()
+}
+
+func (p *prescriptionRepo) Create(ctx context.Context, prescription *entity.Prescription) (*entity.Prescription, error) {
+	alertsJSON, err := json.Marshal(prescription.Alerts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal alerts: %w", err)
+	}
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(map[string]any{
+			"encounter_id": prescription.EncounterID,
+			"notes":        prescription.Notes,
+			"alerts":       alertsJSON,
+			"created_by":   prescription.CreatedBy,
+		}).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	if err = tx.QueryRow(ctx, query, args...).Scan(&prescription.ID); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	insert := p.db.Sq.Builder.
+		Insert(prescriptionItemTableName).
+		Columns("prescription_id", "medication_id", "dosage", "frequency", "duration_days", "instructions")
+	for _, item := range prescription.Items {
+		insert = insert.Values(prescription.ID, item.MedicationID, item.Dosage, item.Frequency, item.DurationDays, item.Instructions)
+	}
+
+	query, args, err = insert.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, prescriptionItemTableName+" create")
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return p.Get(ctx, prescription.ID)
+}
+
+func (p *prescriptionRepo) Get(ctx context.Context, prescriptionID int64) (*entity.Prescription, error) {
+	query, args, err := p.prescriptionSelectQueryPrefix().
+		Where(p.db.Sq.Equal("p.id", prescriptionID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var prescription entity.Prescription
+	if err = scanPrescription(p.db.QueryRow(ctx, query, args...), &prescription); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = p.items(ctx, &prescription); err != nil {
+		return nil, err
+	}
+
+	return &prescription, nil
+}
+
+// List returns the matching prescriptions, latest first.
+func (p *prescriptionRepo) List(ctx context.Context, filter *entity.PrescriptionFilter) ([]*entity.Prescription, error) {
+	conditions := squirrel.And{}
+	if filter.AppointmentID != 0 {
+		conditions = append(conditions, p.db.Sq.Equal("e.appointment_id", filter.AppointmentID))
+	}
+	if filter.PatientID != "" {
+		conditions = append(conditions, p.db.Sq.Equal("a.patient_id", filter.PatientID))
+	}
+
+	query, args, err := p.prescriptionSelectQueryPrefix().
+		Where(conditions).
+		OrderBy("p.created_at DESC", "p.id DESC").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	prescriptions := []*entity.Prescription{}
+	for rows.Next() {
+		var prescription entity.Prescription
+		if err = scanPrescription(rows, &prescription); err != nil {
+			return nil, p.db.Error(err)
+		}
+		prescriptions = append(prescriptions, &prescription)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = p.items(ctx, prescriptions...); err != nil {
+		return nil, err
+	}
+
+	return prescriptions, nil
+}
+
+// ActiveMedications returns the medications the patient is still taking,
+// that is those of prescription items whose duration has not run out.
+func (p *prescriptionRepo) ActiveMedications(ctx context.Context, patientID string) ([]*entity.Medication, error) {
+	query, args, err := p.db.Sq.Builder.
+		Select(
+			"DISTINCT m.id",
+			"m.name",
+			"m.ingredients",
+			"m.form",
+			"m.strength",
+			"m.active",
+			"m.created_at",
+		).
+		From(prescriptionItemTableName + " i").
+		Join(p.tableName + " p ON p.id = i.prescription_id").
+		Join(encounterTableName + " e ON e.id = p.encounter_id").
+		Join(tableNameAppointment + " a ON a.id = e.appointment_id").
+		Join(medicationTableName + " m ON m.id = i.medication_id").
+		Where(p.db.Sq.Equal("a.patient_id", patientID)).
+		Where("p.created_at + make_interval(days => i.duration_days) > now()").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" active medications")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	medications := []*entity.Medication{}
+	for rows.Next() {
+		var medication entity.Medication
+		if err = scanMedication(rows, &medication); err != nil {
+			return nil, p.db.Error(err)
+		}
+		medications = append(medications, &medication)
+	}
+
+	return medications, rows.Err()
+}
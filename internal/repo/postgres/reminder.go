@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+)
+
+const (
+	reminderTableName = "appointment_reminders"
+)
+
+type reminderRepo struct {
+	db                   *postgres.PostgresDB
+	tableName            string
+	tableNameAppointment string
+}
+
+func NewReminderRepo(db *postgres.PostgresDB) interfaces.Reminder {
+	return &reminderRepo{
+		db:                   db,
+		tableName:            reminderTableName,
+		tableNameAppointment: tableNameAppointment,
+	}
+}
+
+// Due returns the active appointments starting later than after but
+// within offset from now whose reminder for offset has not been sent.
+// Patients without an email address are left out.
+func (p *reminderRepo) Due(ctx context.Context, offset, after time.Duration) ([]*entity.Reminder, error) {
+	now := time.Now()
+
+	query, args, err := p.db.Sq.Builder.
+		Select(
+			"a.id",
+			"COALESCE(pu.full_name, '')",
+			"pu.email",
+			"COALESCE(du.full_name, '')",
+			"a.start_time",
+		).
+		From(p.tableNameAppointment+" a").
+		Join(userServiceTableName+" pu ON pu.id = a.patient_id").
+		Join(doctorTableName+" d ON d.id = a.doctor_id").
+		Join(userServiceTableName+" du ON du.id = d.user_id").
+		Where(p.db.Sq.Equal("a.status", []string{entity.AppointmentStatusScheduled, entity.AppointmentStatusConfirmed})).
+		Where(p.db.Sq.Gt("a.start_time", now.Add(after))).
+		Where("a.start_time <= ?", now.Add(offset)).
+		Where("COALESCE(pu.email, '') <> ''").
+		Where("NOT EXISTS (SELECT 1 FROM "+p.tableName+" r WHERE r.appointment_id = a.id AND r.offset_minutes = ?)", int(offset/time.Minute)).
+		OrderBy("a.start_time").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" due")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var reminders []*entity.Reminder
+	for rows.Next() {
+		reminder := entity.Reminder{Offset: offset}
+		if err = rows.Scan(
+			&reminder.AppointmentID,
+			&reminder.PatientName,
+			&reminder.PatientEmail,
+			&reminder.DoctorName,
+			&reminder.StartTime,
+		); err != nil {
+			return nil, p.db.Error(err)
+		}
+		reminders = append(reminders, &reminder)
+	}
+
+	return reminders, rows.Err()
+}
+
+// MarkSent records the reminder as sent and reports whether this call
+// recorded it, i.e. whether the caller should be the one to send it.
+func (p *reminderRepo) MarkSent(ctx context.Context, appointmentID int64, offset time.Duration) (bool, error) {
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(map[string]any{
+			"appointment_id": appointmentID,
+			"offset_minutes": int(offset / time.Minute),
+			"sent_at":        time.Now(),
+		}).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return false, p.db.ErrSQLBuild(err, p.tableName+" mark sent")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, p.db.Error(err)
+	}
+
+	return commandTag.RowsAffected() == 1, nil
+}
+
+// Unmark forgets a reminder that could not be delivered so that it is
+// tried again.
+func (p *reminderRepo) Unmark(ctx context.Context, appointmentID int64, offset time.Duration) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(p.db.Sq.Equal("appointment_id", appointmentID)).
+		Where(p.db.Sq.Equal("offset_minutes", int(offset/time.Minute))).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" unmark")
+	}
+
+	if _, err = p.db.Exec(ctx, query, args...); err != nil {
+		return p.db.Error(err)
+	}
+
+	return nil
+}
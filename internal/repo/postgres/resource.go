@@ -0,0 +1,401 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	resourceTableName            = "resources"
+	resourceHoursTableName       = "resource_hours"
+	appointmentResourceTableName = "appointment_resources"
+)
+
+type resourceRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewResourceRepo(db *postgres.PostgresDB) interfaces.Resource {
+	return &resourceRepo{
+		db:        db,
+		tableName: resourceTableName,
+	}
+}
+
+func (p *resourceRepo) resourceSelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"id",
+			"name",
+			"kind",
+			"category",
+			"description",
+			"active",
+			"created_at",
+		).From(p.tableName)
+}
+
+func scanResource(row pgx.Row, resource *entity.Resource) error {
+	return row.Scan(
+		&resource.ID,
+		&resource.Name,
+		&resource.Kind,
+		&resource.Category,
+		&resource.Description,
+		&resource.Active,
+		&resource.CreatedAt,
+	)
+}
+
+// hours loads the weekly hours of the given resources.
+func (p *resourceRepo) hours(ctx context.Context, resources ...*entity.Resource) error {
+	ids := make([]int64, 0, len(resources))
+	for _, resource := range resources {
+		ids = append(ids, resource.ID)
+	}
+
+	hours, err := openingHours(ctx, p.db, resourceHoursTableName, "resource_id", ids)
+	if err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		resource.Hours = hours[resource.ID]
+	}
+
+	return nil
+}
+
+func (p *resourceRepo) Create(ctx context.Context, resource *entity.Resource) (*entity.Resource, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(map[string]any{
+			"name":        resource.Name,
+			"kind":        resource.Kind,
+			"category":    resource.Category,
+			"description": resource.Description,
+			"active":      resource.Active,
+		}).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	if err = tx.QueryRow(ctx, query, args...).Scan(&resource.ID, &resource.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = setOpeningHours(ctx, p.db, tx, resourceHoursTableName, "resource_id", resource.ID, resource.Hours); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+func (p *resourceRepo) Get(ctx context.Context, resourceID int64) (*entity.Resource, error) {
+	query, args, err := p.resourceSelectQueryPrefix().
+		Where(p.db.Sq.Equal("id", resourceID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var resource entity.Resource
+	if err = scanResource(p.db.QueryRow(ctx, query, args...), &resource); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = p.hours(ctx, &resource); err != nil {
+		return nil, err
+	}
+
+	return &resource, nil
+}
+
+// Update changes the resource and replaces its weekly hours. Bookings
+// already made are kept.
+func (p *resourceRepo) Update(ctx context.Context, resource *entity.Resource) (*entity.Resource, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(map[string]any{
+			"name":        resource.Name,
+			"kind":        resource.Kind,
+			"category":    resource.Category,
+			"description": resource.Description,
+			"active":      resource.Active,
+		}).
+		Where(p.db.Sq.Equal("id", resource.ID)).
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" update")
+	}
+
+	if err = tx.QueryRow(ctx, query, args...).Scan(&resource.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = setOpeningHours(ctx, p.db, tx, resourceHoursTableName, "resource_id", resource.ID, resource.Hours); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// Delete removes a resource that has no upcoming bookings.
+func (p *resourceRepo) Delete(ctx context.Context, resourceID int64) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(p.db.Sq.Equal("id", resourceID)).
+		Where("NOT EXISTS (SELECT 1 FROM " + appointmentResourceTableName + " ar WHERE ar.resource_id = " + p.tableName + ".id AND ar.end_time > now())").
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" delete")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		if _, err = p.Get(ctx, resourceID); err != nil {
+			return err
+		}
+		return entity.ErrorResourceInUse
+	}
+
+	return nil
+}
+
+// List returns the resources, filtered by kind and category when given.
+func (p *resourceRepo) List(ctx context.Context, kind, category string) ([]*entity.Resource, error) {
+	queryBuilder := p.resourceSelectQueryPrefix().OrderBy("name")
+	if kind != "" {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Equal("kind", kind))
+	}
+	if category != "" {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Equal("category", category))
+	}
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	resources := []*entity.Resource{}
+	for rows.Next() {
+		var resource entity.Resource
+		if err = scanResource(rows, &resource); err != nil {
+			return nil, p.db.Error(err)
+		}
+		resources = append(resources, &resource)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err = p.hours(ctx, resources...); err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+func (p *resourceRepo) listBookings(ctx context.Context, where squirrel.Sqlizer, from, to time.Time) ([]*entity.ResourceBooking, error) {
+	queryBuilder := p.db.Sq.Builder.
+		Select("ar.resource_id", "r.name", "ar.appointment_id", "ar.start_time", "ar.end_time").
+		From(appointmentResourceTableName+" ar").
+		Join(p.tableName+" r ON r.id = ar.resource_id").
+		Where(where).
+		OrderBy("ar.start_time", "r.name")
+	if !from.IsZero() {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Gt("ar.end_time", from))
+	}
+	if !to.IsZero() {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Lt("ar.start_time", to))
+	}
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, appointmentResourceTableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	bookings := []*entity.ResourceBooking{}
+	for rows.Next() {
+		var booking entity.ResourceBooking
+		if err = rows.Scan(
+			&booking.ResourceID,
+			&booking.ResourceName,
+			&booking.AppointmentID,
+			&booking.StartTime,
+			&booking.EndTime,
+		); err != nil {
+			return nil, p.db.Error(err)
+		}
+		bookings = append(bookings, &booking)
+	}
+
+	return bookings, rows.Err()
+}
+
+// Bookings returns the resource's bookings overlapping [from, to).
+func (p *resourceRepo) Bookings(ctx context.Context, resourceID int64, from, to time.Time) ([]*entity.ResourceBooking, error) {
+	return p.listBookings(ctx, p.db.Sq.Equal("ar.resource_id", resourceID), from, to)
+}
+
+// AppointmentBookings returns the resources the appointment holds.
+func (p *resourceRepo) AppointmentBookings(ctx context.Context, appointmentID int64) ([]*entity.ResourceBooking, error) {
+	return p.listBookings(ctx, p.db.Sq.Equal("ar.appointment_id", appointmentID), time.Time{}, time.Time{})
+}
+
+// reserveResources books one free, active resource of every category the
+// appointment type requires for [start, end), the visit including its
+// buffer.
+//
+// The chosen resource rows are locked FOR UPDATE so concurrent bookings
+// asking for the same category queue up behind each other; the
+// appointment_resources_no_overlap exclusion constraint backs this up.
+func (p *appointmentRepo) reserveResources(ctx context.Context, tx pgx.Tx, appointmentID, appointmentTypeID int64, start, end time.Time) error {
+	if appointmentTypeID == 0 {
+		return nil
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Select("required_resources").
+		From(appointmentTypeTableName).
+		Where(p.db.Sq.Equal("id", appointmentTypeID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, appointmentTypeTableName+" required resources")
+	}
+
+	var categories []string
+	if err = tx.QueryRow(ctx, query, args...).Scan(&categories); err != nil {
+		return p.db.Error(err)
+	}
+
+	weekday, from, to := p.resourceWindow(start, end)
+
+	var chosen []int64
+	for _, category := range categories {
+		query, args, err := p.db.Sq.Builder.
+			Select("r.id").
+			From(resourceTableName+" r").
+			Where(p.db.Sq.Equal("r.category", category)).
+			Where("r.active").
+			Where(p.db.Sq.NotEqual("r.id", chosen)).
+			Where("(NOT EXISTS (SELECT 1 FROM "+resourceHoursTableName+" h WHERE h.resource_id = r.id)"+
+				" OR EXISTS (SELECT 1 FROM "+resourceHoursTableName+" h WHERE h.resource_id = r.id"+
+				" AND h.weekday = ? AND h.start_time <= ?::time AND h.end_time >= ?::time))", weekday, from, to).
+			Where("NOT EXISTS (SELECT 1 FROM "+appointmentResourceTableName+" ar"+
+				" WHERE ar.resource_id = r.id AND ar.start_time < ? AND ar.end_time > ?)", end, start).
+			OrderBy("r.id").
+			Limit(1).
+			Suffix("FOR UPDATE OF r").
+			ToSql()
+		if err != nil {
+			return p.db.ErrSQLBuild(err, resourceTableName+" free")
+		}
+
+		var resourceID int64
+		if err = p.db.Error(tx.QueryRow(ctx, query, args...).Scan(&resourceID)); err != nil {
+			if errors.Is(err, entity.ErrorNotFound) {
+				return entity.ErrorResourceUnavailable
+			}
+			return err
+		}
+		chosen = append(chosen, resourceID)
+
+		query, args, err = p.db.Sq.Builder.
+			Insert(appointmentResourceTableName).
+			SetMap(map[string]any{
+				"appointment_id": appointmentID,
+				"resource_id":    resourceID,
+				"start_time":     start,
+				"end_time":       end,
+			}).
+			ToSql()
+		if err != nil {
+			return p.db.ErrSQLBuild(err, appointmentResourceTableName+" create")
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return bookingError(p.db.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// resourceWindow returns the weekday and the wall clock start and end of
+// [start, end) in the hospital zone, for matching against resource hours.
+// A window running past midnight matches no weekday.
+func (p *appointmentRepo) resourceWindow(start, end time.Time) (int, string, string) {
+	start, end = start.In(p.db.Location), end.In(p.db.Location)
+
+	to := end.Format("15:04:05")
+	midnight := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, p.db.Location)
+	switch {
+	case end.Equal(midnight):
+		to = "24:00:00"
+	case end.After(midnight):
+		return -1, "00:00:00", "00:00:00"
+	}
+
+	return int(start.Weekday()), start.Format("15:04:05"), to
+}
+
+// releaseResources frees every resource the appointment holds.
+func (p *appointmentRepo) releaseResources(ctx context.Context, tx pgx.Tx, appointmentID int64) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(appointmentResourceTableName).
+		Where(p.db.Sq.Equal("appointment_id", appointmentID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, appointmentResourceTableName+" release")
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	return err
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	scheduleTableName = "doctor_schedules"
+)
+
+type scheduleRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewScheduleRepo(db *postgres.PostgresDB) interfaces.Schedule {
+	return &scheduleRepo{
+		db:        db,
+		tableName: scheduleTableName,
+	}
+}
+
+func (p *scheduleRepo) scheduleSelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"id",
+			"doctor_id",
+			"slot_minutes",
+			"days",
+			"updated_at",
+		).From(p.tableName)
+}
+
+func scanSchedule(row pgx.Row, schedule *entity.Schedule) error {
+	var daysJSON []byte
+
+	if err := row.Scan(
+		&schedule.ID,
+		&schedule.DoctorID,
+		&schedule.SlotMinutes,
+		&daysJSON,
+		&schedule.UpdatedAt,
+	); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(daysJSON, &schedule.Days); err != nil {
+		return fmt.Errorf("failed to unmarshal days: %w", err)
+	}
+
+	return nil
+}
+
+func (p *scheduleRepo) Upsert(ctx context.Context, schedule *entity.Schedule) (*entity.Schedule, error) {
+	daysJSON, err := json.Marshal(schedule.Days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal days: %w", err)
+	}
+
+	data := map[string]any{
+		"doctor_id":    schedule.DoctorID,
+		"slot_minutes": schedule.SlotMinutes,
+		"days":         daysJSON,
+		"updated_at":   time.Now(),
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(data).
+		Suffix("ON CONFLICT (doctor_id) DO UPDATE SET slot_minutes = EXCLUDED.slot_minutes, days = EXCLUDED.days, updated_at = EXCLUDED.updated_at").
+		Suffix("RETURNING id, doctor_id, slot_minutes, days, updated_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" upsert")
+	}
+
+	var upserted entity.Schedule
+	if err = scanSchedule(p.db.QueryRow(ctx, query, args...), &upserted); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &upserted, nil
+}
+
+func (p *scheduleRepo) Get(ctx context.Context, doctorID string) (*entity.Schedule, error) {
+	query, args, err := p.scheduleSelectQueryPrefix().
+		Where(p.db.Sq.Equal("doctor_id", doctorID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var schedule entity.Schedule
+	if err = scanSchedule(p.db.QueryRow(ctx, query, args...), &schedule); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &schedule, nil
+}
+
+func (p *scheduleRepo) Delete(ctx context.Context, doctorID string) error {
+	query, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(p.db.Sq.Equal("doctor_id", doctorID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" delete")
+	}
+
+	commandTag, err := p.db.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return entity.ErrorNotFound
+	}
+
+	return nil
+}
+
+func (p *scheduleRepo) List(ctx context.Context) ([]*entity.Schedule, error) {
+	query, args, err := p.scheduleSelectQueryPrefix().
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var schedules []*entity.Schedule
+	for rows.Next() {
+		var schedule entity.Schedule
+		if err = scanSchedule(rows, &schedule); err != nil {
+			return nil, p.db.Error(err)
+		}
+		schedules = append(schedules, &schedule)
+	}
+
+	return schedules, rows.Err()
+}
@@ -0,0 +1,237 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+)
+
+const (
+	seriesTableName = "appointment_series"
+)
+
+// occurrenceError reports whether err only concerns a single visit of a
+// series, which best-effort booking skips, rather than the whole request.
+func occurrenceError(err error) bool {
+	return errors.Is(err, entity.ErrorSlotUnavailable) ||
+		errors.Is(err, entity.ErrorSlotTaken) ||
+		errors.Is(err, entity.ErrorSlotHeld) ||
+		errors.Is(err, entity.ErrorResourceUnavailable) ||
+		errors.Is(err, entity.ErrorIllegalTransition)
+}
+
+// CreateSeries records the series and books a visit at each of starts.
+// Every visit is booked under its own savepoint, so one the doctor is not
+// free for is reported on its occurrence without undoing the others. In
+// all-or-nothing mode, or when no visit could be booked at all, nothing is
+// written and ErrorSeriesUnavailable is returned along with the result
+// listing the failed occurrences.
+func (p *appointmentRepo) CreateSeries(ctx context.Context, series *entity.AppointmentSeries, starts []time.Time) (*entity.AppointmentSeriesResult, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	data := map[string]any{
+		"doctor_id":      series.DoctorID,
+		"patient_id":     series.UserID,
+		"start_time":     series.StartTime,
+		"interval_weeks": series.IntervalWeeks,
+		"mode":           series.Mode,
+	}
+	if series.AppointmentTypeID != 0 {
+		data["appointment_type_id"] = series.AppointmentTypeID
+	}
+	if series.Occurrences != 0 {
+		data["occurrences"] = series.Occurrences
+	} else {
+		data["until"] = series.Until
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(seriesTableName).
+		SetMap(data).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, seriesTableName+" create")
+	}
+
+	if err = tx.QueryRow(ctx, query, args...).Scan(&series.ID, &series.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	result := &entity.AppointmentSeriesResult{Series: series}
+	for _, start := range starts {
+		occurrence := &entity.SeriesOccurrence{StartTime: start}
+		result.Occurrences = append(result.Occurrences, occurrence)
+
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		appointment := &entity.Appointment{
+			DoctorID:          series.DoctorID,
+			UserID:            series.UserID,
+			AppointmentTypeID: series.AppointmentTypeID,
+			SeriesID:          series.ID,
+		}
+		if err = p.insertAppointment(ctx, savepoint, appointment, start); err != nil {
+			savepoint.Rollback(ctx)
+			if !occurrenceError(err) {
+				return nil, err
+			}
+			occurrence.Error = err.Error()
+			result.Failed++
+			continue
+		}
+		if err = savepoint.Commit(ctx); err != nil {
+			return nil, bookingError(p.db.Error(err))
+		}
+
+		occurrence.Appointment = appointment
+		result.Succeeded++
+	}
+
+	if result.Failed > 0 && (series.Mode == entity.SeriesModeAllOrNothing || result.Succeeded == 0) {
+		for _, occurrence := range result.Occurrences {
+			occurrence.Appointment = nil
+		}
+		series.ID = 0
+		result.Succeeded = 0
+		return result, entity.ErrorSeriesUnavailable
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, bookingError(p.db.Error(err))
+	}
+
+	return result, nil
+}
+
+// GetSeries returns the series with all its appointments, earliest first.
+func (p *appointmentRepo) GetSeries(ctx context.Context, seriesID int64) (*entity.AppointmentSeries, error) {
+	query, args, err := p.db.Sq.Builder.
+		Select(
+			"id",
+			"doctor_id",
+			"patient_id",
+			"appointment_type_id",
+			"start_time",
+			"interval_weeks",
+			"occurrences",
+			"COALESCE(to_char(until, 'YYYY-MM-DD'), '')",
+			"mode",
+			"created_at",
+		).
+		From(seriesTableName).
+		Where(p.db.Sq.Equal("id", seriesID)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, seriesTableName+" get")
+	}
+
+	var (
+		series            entity.AppointmentSeries
+		appointmentTypeID sql.NullInt64
+		occurrences       sql.NullInt64
+	)
+	if err = p.db.QueryRow(ctx, query, args...).Scan(
+		&series.ID,
+		&series.DoctorID,
+		&series.UserID,
+		&appointmentTypeID,
+		&series.StartTime,
+		&series.IntervalWeeks,
+		&occurrences,
+		&series.Until,
+		&series.Mode,
+		&series.CreatedAt,
+	); err != nil {
+		return nil, p.db.Error(err)
+	}
+	series.AppointmentTypeID = appointmentTypeID.Int64
+	series.Occurrences = int(occurrences.Int64)
+
+	query, args, err = p.appointmentSelectQueryPrefix().
+		Where(p.db.Sq.Equal("series_id", seriesID)).
+		OrderBy("start_time").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableNameAppointment+" series")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	series.Appointments = []*entity.Appointment{}
+	for rows.Next() {
+		var appointment entity.Appointment
+		if err = scanAppointment(rows, &appointment); err != nil {
+			return nil, p.db.Error(err)
+		}
+		series.Appointments = append(series.Appointments, &appointment)
+	}
+
+	return &series, rows.Err()
+}
+
+// RescheduleSeries moves each occurrence's appointment to the
+// occurrence's StartTime, every move under its own savepoint. Moves that
+// fail are reported on their occurrence; with allOrNothing a single failure
+// undoes every move and returns ErrorSeriesUnavailable.
+func (p *appointmentRepo) RescheduleSeries(ctx context.Context, occurrences []*entity.SeriesOccurrence, allOrNothing bool) error {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	failed := 0
+	for _, occurrence := range occurrences {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err = p.moveAppointment(ctx, savepoint, int(occurrence.Appointment.ID), occurrence.StartTime); err != nil {
+			savepoint.Rollback(ctx)
+			if !occurrenceError(err) {
+				return err
+			}
+			occurrence.Error = err.Error()
+			failed++
+			continue
+		}
+		if err = savepoint.Commit(ctx); err != nil {
+			return bookingError(p.db.Error(err))
+		}
+	}
+
+	if failed > 0 && (allOrNothing || failed == len(occurrences)) {
+		return entity.ErrorSeriesUnavailable
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return bookingError(p.db.Error(err))
+	}
+
+	for _, occurrence := range occurrences {
+		if occurrence.Error != "" {
+			continue
+		}
+		if occurrence.Appointment, err = p.GetAppointment(ctx, int(occurrence.Appointment.ID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+)
+
+const tenantTableName = "tenants"
+
+type tenantRepo struct {
+	db        *postgres.PostgresDB
+	tableName string
+}
+
+func NewTenantRepo(db *postgres.PostgresDB) interfaces.Tenant {
+	return &tenantRepo{
+		db:        db,
+		tableName: tenantTableName,
+	}
+}
+
+// List returns every hospital hosted on the deployment. The tenants table
+// is not row level secured, so background jobs use it to visit each one.
+func (p *tenantRepo) List(ctx context.Context) ([]*entity.Tenant, error) {
+	query, args, err := p.db.Sq.Builder.
+		Select("id", "name", "created_at").
+		From(p.tableName).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	tenants := []*entity.Tenant{}
+	for rows.Next() {
+		var tenant entity.Tenant
+		if err = rows.Scan(&tenant.ID, &tenant.Name, &tenant.CreatedAt); err != nil {
+			return nil, p.db.Error(err)
+		}
+		tenants = append(tenants, &tenant)
+	}
+
+	return tenants, rows.Err()
+}
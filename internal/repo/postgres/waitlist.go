@@ -0,0 +1,529 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo/interfaces"
+	postgres "github.com/Abdulazizxoshimov/Hospital/pkg/storage"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	waitlistTableName = "waitlist_entries"
+)
+
+type waitlistRepo struct {
+	db                    *postgres.PostgresDB
+	tableName             string
+	tableNameAvailability string
+	appointments          *appointmentRepo
+}
+
+func NewWaitlistRepo(db *postgres.PostgresDB) interfaces.Waitlist {
+	return &waitlistRepo{
+		db:                    db,
+		tableName:             waitlistTableName,
+		tableNameAvailability: tableNameAvailability,
+		appointments:          NewAppointmentRepo(db).(*appointmentRepo),
+	}
+}
+
+func (p *waitlistRepo) waitlistSelectQueryPrefix() squirrel.SelectBuilder {
+	return p.db.Sq.Builder.
+		Select(
+			"id",
+			"doctor_id",
+			"patient_id",
+			"COALESCE(appointment_type_id, 0)",
+			"COALESCE(branch_id, 0)",
+			"from_time",
+			"to_time",
+			"status",
+			"offered_start_time",
+			"offer_expires_at",
+			"appointment_id",
+			"created_at",
+		).From(p.tableName)
+}
+
+func scanWaitlistEntry(row pgx.Row, entry *entity.WaitlistEntry) error {
+	return row.Scan(
+		&entry.ID,
+		&entry.DoctorID,
+		&entry.PatientID,
+		&entry.AppointmentTypeID,
+		&entry.BranchID,
+		&entry.FromTime,
+		&entry.ToTime,
+		&entry.Status,
+		&entry.OfferedStartTime,
+		&entry.OfferExpiresAt,
+		&entry.AppointmentID,
+		&entry.CreatedAt,
+	)
+}
+
+func (p *waitlistRepo) Create(ctx context.Context, entry *entity.WaitlistEntry) (*entity.WaitlistEntry, error) {
+	data := map[string]any{
+		"doctor_id":  entry.DoctorID,
+		"patient_id": entry.PatientID,
+		"from_time":  entry.FromTime,
+		"to_time":    entry.ToTime,
+		"status":     entity.WaitlistStatusWaiting,
+	}
+	if entry.AppointmentTypeID != 0 {
+		data["appointment_type_id"] = entry.AppointmentTypeID
+	}
+	if entry.BranchID != 0 {
+		data["branch_id"] = entry.BranchID
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		SetMap(data).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	if err = p.db.QueryRow(ctx, query, args...).Scan(&entry.ID, &entry.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+	entry.Status = entity.WaitlistStatusWaiting
+
+	return entry, nil
+}
+
+func (p *waitlistRepo) Get(ctx context.Context, id int64) (*entity.WaitlistEntry, error) {
+	query, args, err := p.waitlistSelectQueryPrefix().
+		Where(p.db.Sq.Equal("id", id)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var entry entity.WaitlistEntry
+	if err = scanWaitlistEntry(p.db.QueryRow(ctx, query, args...), &entry); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &entry, nil
+}
+
+func (p *waitlistRepo) List(ctx context.Context, params map[string]string) ([]*entity.WaitlistEntry, error) {
+	queryBuilder := p.waitlistSelectQueryPrefix().OrderBy("created_at")
+
+	for key, value := range params {
+		if key == "doctor_id" || key == "patient_id" || key == "status" {
+			queryBuilder = queryBuilder.Where(p.db.Sq.Equal(key, value))
+		}
+	}
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var entries []*entity.WaitlistEntry
+	for rows.Next() {
+		var entry entity.WaitlistEntry
+		if err = scanWaitlistEntry(rows, &entry); err != nil {
+			return nil, p.db.Error(err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// releaseHolds clears the holds placed on availability for the entries.
+func (p *waitlistRepo) releaseHolds(ctx context.Context, tx pgx.Tx, entryIDs []int64) error {
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableNameAvailability).
+		Set("held_by", nil).
+		Set("held_until", nil).
+		Where(p.db.Sq.Equal("held_by", entryIDs)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableNameAvailability+" release hold")
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	return p.db.Error(err)
+}
+
+// Cancel takes the patient off the waitlist, giving up any open offer.
+func (p *waitlistRepo) Cancel(ctx context.Context, id int64) error {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		Set("status", entity.WaitlistStatusCancelled).
+		Where(p.db.Sq.Equal("id", id)).
+		Where(p.db.Sq.Equal("status", []string{entity.WaitlistStatusWaiting, entity.WaitlistStatusOffered})).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" cancel")
+	}
+
+	commandTag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return entity.ErrorOfferClosed
+	}
+
+	if err = p.releaseHolds(ctx, tx, []int64{id}); err != nil {
+		return err
+	}
+
+	return p.db.Error(tx.Commit(ctx))
+}
+
+// Accept books the offered slot at startTime and marks the entry accepted
+// in one transaction, so an offer that closed meanwhile books nothing.
+func (p *waitlistRepo) Accept(ctx context.Context, id int64, appointment *entity.Appointment, startTime time.Time) (*entity.Appointment, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = p.appointments.insertAppointment(ctx, tx, appointment, startTime); err != nil {
+		return nil, err
+	}
+
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		Set("status", entity.WaitlistStatusAccepted).
+		Set("appointment_id", appointment.ID).
+		Where(p.db.Sq.Equal("id", id)).
+		Where(p.db.Sq.Equal("status", entity.WaitlistStatusOffered)).
+		Where("offer_expires_at > now()").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" accept")
+	}
+
+	commandTag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return nil, entity.ErrorOfferClosed
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, bookingError(p.db.Error(err))
+	}
+
+	return appointment, nil
+}
+
+// ExpireOffers closes offers nobody accepted in time and entries whose
+// window has passed, releasing their holds. It returns the doctors whose
+// slots became free again so they can be offered to the next patient.
+func (p *waitlistRepo) ExpireOffers(ctx context.Context) ([]string, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		Set("status", entity.WaitlistStatusExpired).
+		Where(p.db.Sq.Or(
+			squirrel.And{
+				squirrel.Eq{"status": entity.WaitlistStatusOffered},
+				squirrel.Expr("offer_expires_at <= now()"),
+			},
+			squirrel.And{
+				squirrel.Eq{"status": entity.WaitlistStatusWaiting},
+				squirrel.Expr("to_time <= now()"),
+			},
+		)).
+		Suffix("RETURNING id, doctor_id, offer_expires_at IS NOT NULL").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" expire")
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	var (
+		ids     []int64
+		doctors []string
+		seen    = make(map[string]bool)
+	)
+	for rows.Next() {
+		var (
+			id       int64
+			doctorID string
+			offered  bool
+		)
+		if err = rows.Scan(&id, &doctorID, &offered); err != nil {
+			rows.Close()
+			return nil, p.db.Error(err)
+		}
+		if !offered {
+			continue
+		}
+		ids = append(ids, id)
+		if !seen[doctorID] {
+			seen[doctorID] = true
+			doctors = append(doctors, doctorID)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if len(ids) != 0 {
+		if err = p.releaseHolds(ctx, tx, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return doctors, nil
+}
+
+// WaitingDoctors returns the doctors with at least one patient waiting.
+func (p *waitlistRepo) WaitingDoctors(ctx context.Context) ([]string, error) {
+	query, args, err := p.db.Sq.Builder.
+		Select("DISTINCT doctor_id").
+		From(p.tableName).
+		Where(p.db.Sq.Equal("status", entity.WaitlistStatusWaiting)).
+		Where("to_time > now()").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" waiting doctors")
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var doctors []string
+	for rows.Next() {
+		var doctorID string
+		if err = rows.Scan(&doctorID); err != nil {
+			return nil, p.db.Error(err)
+		}
+		doctors = append(doctors, doctorID)
+	}
+
+	return doctors, rows.Err()
+}
+
+// OfferNext walks the doctor's waitlist in arrival order and, for every
+// waiting entry, holds the earliest free run of slots in its window that
+// is long enough for the visit. Held slots are invisible to other
+// bookings until the offer expires, so each patient gets their own run.
+func (p *waitlistRepo) OfferNext(ctx context.Context, doctorID string, hold time.Duration) ([]*entity.WaitlistEntry, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := p.waitlistSelectQueryPrefix().
+		Where(p.db.Sq.Equal("doctor_id", doctorID)).
+		Where(p.db.Sq.Equal("status", entity.WaitlistStatusWaiting)).
+		Where("to_time > now()").
+		OrderBy("created_at", "id").
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" offer")
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	var waiting []*entity.WaitlistEntry
+	for rows.Next() {
+		var entry entity.WaitlistEntry
+		if err = scanWaitlistEntry(rows, &entry); err != nil {
+			rows.Close()
+			return nil, p.db.Error(err)
+		}
+		waiting = append(waiting, &entry)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	var (
+		offers    []*entity.WaitlistEntry
+		now       = time.Now()
+		expiresAt = now.Add(hold)
+	)
+	for _, entry := range waiting {
+		length, err := p.visitLength(ctx, tx, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		from := entry.FromTime
+		if from.Before(now) {
+			from = now
+		}
+
+		ids, start, err := p.freeRun(ctx, tx, doctorID, entry.BranchID, from, entry.ToTime, length)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		query, args, err := p.db.Sq.Builder.
+			Update(p.tableNameAvailability).
+			Set("held_by", entry.ID).
+			Set("held_until", expiresAt).
+			Set("hold_token", nil).
+			Set("hold_patient_id", nil).
+			Where(p.db.Sq.Equal("id", ids)).
+			ToSql()
+		if err != nil {
+			return nil, p.db.ErrSQLBuild(err, p.tableNameAvailability+" hold")
+		}
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return nil, p.db.Error(err)
+		}
+
+		query, args, err = p.db.Sq.Builder.
+			Update(p.tableName).
+			Set("status", entity.WaitlistStatusOffered).
+			Set("offered_start_time", start).
+			Set("offer_expires_at", expiresAt).
+			Where(p.db.Sq.Equal("id", entry.ID)).
+			ToSql()
+		if err != nil {
+			return nil, p.db.ErrSQLBuild(err, p.tableName+" offer")
+		}
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return nil, p.db.Error(err)
+		}
+
+		entry.Status = entity.WaitlistStatusOffered
+		entry.OfferedStartTime = &start
+		entry.OfferExpiresAt = &expiresAt
+		offers = append(offers, entry)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return offers, nil
+}
+
+// visitLength is the availability a booking for the entry would consume:
+// the resolved duration plus buffer of its appointment type.
+func (p *waitlistRepo) visitLength(ctx context.Context, tx pgx.Tx, entry *entity.WaitlistEntry) (time.Duration, error) {
+	if entry.AppointmentTypeID == 0 {
+		return defaultAppointmentDuration, nil
+	}
+
+	query, args, err := appointmentTypeResolveQuery(p.db, entry.DoctorID, entry.AppointmentTypeID)
+	if err != nil {
+		return 0, err
+	}
+
+	var appointmentType entity.AppointmentType
+	if err = scanAppointmentType(tx.QueryRow(ctx, query, args...), &appointmentType); err != nil {
+		return 0, p.db.Error(err)
+	}
+
+	return time.Duration(appointmentType.DurationMinutes+appointmentType.BufferMinutes) * time.Minute, nil
+}
+
+// freeRun finds the earliest contiguous run of free, unheld and unblocked
+// slots between from and to that lasts at least length, and locks it. A
+// visit is held at a single branch, so the run never spans two; with
+// branchID set it only uses slots at that branch.
+func (p *waitlistRepo) freeRun(ctx context.Context, tx pgx.Tx, doctorID string, branchID int64, from, to time.Time, length time.Duration) ([]int64, time.Time, error) {
+	queryBuilder := p.db.Sq.Builder.
+		Select("id", "start_time", "end_time", "COALESCE(branch_id, 0)").
+		From(p.tableNameAvailability).
+		Where(p.db.Sq.Equal("doctor_id", doctorID)).
+		Where("start_time >= ?", from).
+		Where("end_time <= ?", to).
+		Where("is_booked IS NOT TRUE").
+		Where(notBusy(p.tableNameAvailability)).
+		Where("(held_until IS NULL OR held_until <= now())").
+		OrderBy("start_time").
+		Suffix("FOR UPDATE SKIP LOCKED")
+	if branchID != 0 {
+		queryBuilder = queryBuilder.Where(p.db.Sq.Equal("branch_id", branchID))
+	}
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, time.Time{}, p.db.ErrSQLBuild(err, p.tableNameAvailability+" free run")
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, time.Time{}, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var (
+		ids        []int64
+		start, end time.Time
+		runBranch  int64
+	)
+	for rows.Next() {
+		var (
+			id                 int64
+			slotStart, slotEnd time.Time
+			slotBranch         int64
+		)
+		if err = rows.Scan(&id, &slotStart, &slotEnd, &slotBranch); err != nil {
+			return nil, time.Time{}, p.db.Error(err)
+		}
+
+		if len(ids) == 0 || !slotStart.Equal(end) || slotBranch != runBranch {
+			ids, start, runBranch = ids[:0], slotStart, slotBranch
+		}
+		ids = append(ids, id)
+		end = slotEnd
+
+		if end.Sub(start) >= length {
+			return ids, start, nil
+		}
+	}
+
+	return nil, time.Time{}, p.db.Error(rows.Err())
+}
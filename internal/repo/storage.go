@@ -11,11 +11,47 @@ type StorageI interface{
 	User() interfaces.User
 	Doctor() interfaces.Doctor
 	Appointment() interfaces.Appointment
+	Schedule() interfaces.Schedule
+	AppointmentType() interfaces.AppointmentType
+	CancellationPolicy() interfaces.CancellationPolicy
+	Waitlist() interfaces.Waitlist
+	CalendarFeed() interfaces.CalendarFeed
+	BusyTime() interfaces.BusyTime
+	Reminder() interfaces.Reminder
+	DoctorLeave() interfaces.DoctorLeave
+	Closure() interfaces.Closure
+	Resource() interfaces.Resource
+	Branch() interfaces.Branch
+	Tenant() interfaces.Tenant
+	Encounter() interfaces.Encounter
+	Medication() interfaces.Medication
+	Allergy() interfaces.Allergy
+	Prescription() interfaces.Prescription
+	LabOrder() interfaces.LabOrder
+	Attachment() interfaces.Attachment
 }
 type storagePg struct{
 	user interfaces.User
 	doctor interfaces.Doctor
 	appointment interfaces.Appointment
+	schedule interfaces.Schedule
+	appointmentType interfaces.AppointmentType
+	cancellationPolicy interfaces.CancellationPolicy
+	waitlist interfaces.Waitlist
+	calendarFeed interfaces.CalendarFeed
+	busyTime interfaces.BusyTime
+	reminder interfaces.Reminder
+	doctorLeave interfaces.DoctorLeave
+	closure interfaces.Closure
+	resource interfaces.Resource
+	branch interfaces.Branch
+	tenant interfaces.Tenant
+	encounter interfaces.Encounter
+	medication interfaces.Medication
+	allergy interfaces.Allergy
+	prescription interfaces.Prescription
+	labOrder interfaces.LabOrder
+	attachment interfaces.Attachment
 }
 
 
@@ -24,6 +60,24 @@ func NewStoragePg(db *db.PostgresDB)StorageI{
 		user: postgres.NewUserRepo(db),
 		doctor : postgres.NewDoctorRepo(db),
 		appointment: postgres.NewAppointmentRepo(db),
+		schedule: postgres.NewScheduleRepo(db),
+		appointmentType: postgres.NewAppointmentTypeRepo(db),
+		cancellationPolicy: postgres.NewCancellationPolicyRepo(db),
+		waitlist: postgres.NewWaitlistRepo(db),
+		calendarFeed: postgres.NewCalendarFeedRepo(db),
+		busyTime: postgres.NewBusyTimeRepo(db),
+		reminder: postgres.NewReminderRepo(db),
+		doctorLeave: postgres.NewDoctorLeaveRepo(db),
+		closure: postgres.NewClosureRepo(db),
+		resource: postgres.NewResourceRepo(db),
+		branch: postgres.NewBranchRepo(db),
+		tenant: postgres.NewTenantRepo(db),
+		encounter: postgres.NewEncounterRepo(db),
+		medication: postgres.NewMedicationRepo(db),
+		allergy: postgres.NewAllergyRepo(db),
+		prescription: postgres.NewPrescriptionRepo(db),
+		labOrder: postgres.NewLabOrderRepo(db),
+		attachment: postgres.NewAttachmentRepo(db),
 	}
 }
 
@@ -35,4 +89,75 @@ func (s *storagePg)Doctor()interfaces.Doctor{
 }
 func (s *storagePg)Appointment()interfaces.Appointment{
 	return s.appointment
-}
\ No newline at end of file
+}
+func (s *storagePg)Schedule()interfaces.Schedule{
+	return s.schedule
+}
+
+func (s *storagePg)AppointmentType()interfaces.AppointmentType{
+	return s.appointmentType
+}
+
+func (s *storagePg)CancellationPolicy()interfaces.CancellationPolicy{
+	return s.cancellationPolicy
+}
+
+func (s *storagePg)Waitlist()interfaces.Waitlist{
+	return s.waitlist
+}
+
+func (s *storagePg)CalendarFeed()interfaces.CalendarFeed{
+	return s.calendarFeed
+}
+
+func (s *storagePg)BusyTime()interfaces.BusyTime{
+	return s.busyTime
+}
+
+func (s *storagePg)Reminder()interfaces.Reminder{
+	return s.reminder
+}
+
+func (s *storagePg)DoctorLeave()interfaces.DoctorLeave{
+	return s.doctorLeave
+}
+
+func (s *storagePg)Closure()interfaces.Closure{
+	return s.closure
+}
+
+func (s *storagePg)Resource()interfaces.Resource{
+	return s.resource
+}
+
+func (s *storagePg)Branch()interfaces.Branch{
+	return s.branch
+}
+
+func (s *storagePg)Tenant()interfaces.Tenant{
+	return s.tenant
+}
+
+func (s *storagePg)Encounter()interfaces.Encounter{
+	return s.encounter
+}
+
+func (s *storagePg)Medication()interfaces.Medication{
+	return s.medication
+}
+
+func (s *storagePg)Allergy()interfaces.Allergy{
+	return s.allergy
+}
+
+func (s *storagePg)Prescription()interfaces.Prescription{
+	return s.prescription
+}
+
+func (s *storagePg)LabOrder()interfaces.LabOrder{
+	return s.labOrder
+}
+
+func (s *storagePg)Attachment()interfaces.Attachment{
+	return s.attachment
+}
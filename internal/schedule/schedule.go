@@ -0,0 +1,255 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/logger"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/tenant"
+	timepkg "github.com/Abdulazizxoshimov/Hospital/pkg/time"
+)
+
+// Validate checks that every day of the schedule is well formed and that
+// breaks fall inside their working window.
+func Validate(schedule *entity.Schedule) error {
+	if schedule.SlotMinutes <= 0 {
+		return fmt.Errorf("slot minutes must be positive")
+	}
+
+	seen := make(map[int]bool, len(schedule.Days))
+	for _, day := range schedule.Days {
+		if day.Weekday < 0 || day.Weekday > 6 {
+			return fmt.Errorf("invalid weekday %d", day.Weekday)
+		}
+		if seen[day.Weekday] {
+			return fmt.Errorf("weekday %d is listed twice", day.Weekday)
+		}
+		seen[day.Weekday] = true
+
+		start, end, err := clockRange(day.StartTime, day.EndTime)
+		if err != nil {
+			return err
+		}
+
+		for _, br := range day.Breaks {
+			breakStart, breakEnd, err := clockRange(br.StartTime, br.EndTime)
+			if err != nil {
+				return err
+			}
+			if breakStart < start || breakEnd > end {
+				return fmt.Errorf("break %s-%s is outside working hours %s-%s", br.StartTime, br.EndTime, day.StartTime, day.EndTime)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Slots expands the schedule into availability slots for every day in
+// [from, to). Schedule clock times are wall clock times in loc. Breaks
+// split a working window into separate segments and each segment is cut
+// into slots independently.
+func Slots(schedule *entity.Schedule, from, to time.Time, loc *time.Location) ([]*entity.Availability, error) {
+	days := make(map[time.Weekday]entity.ScheduleDay, len(schedule.Days))
+	for _, day := range schedule.Days {
+		days[time.Weekday(day.Weekday)] = day
+	}
+
+	length := time.Duration(schedule.SlotMinutes) * time.Minute
+	from = from.In(loc)
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+
+	var slots []*entity.Availability
+	for date := from; date.Before(to); date = date.AddDate(0, 0, 1) {
+		day, ok := days[date.Weekday()]
+		if !ok {
+			continue
+		}
+
+		segments, err := segments(day)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, segment := range segments {
+			segmentStart := timepkg.OnDate(date, segment.Start, loc)
+			segmentEnd := timepkg.OnDate(date, segment.End, loc)
+			for _, interval := range timepkg.Split(segmentStart, segmentEnd, length) {
+				slots = append(slots, &entity.Availability{
+					DoctorID:  schedule.DoctorID,
+					StartTime: interval.Start,
+					EndTime:   interval.End,
+					BranchID:  day.BranchID,
+				})
+			}
+		}
+	}
+
+	return slots, nil
+}
+
+type clockInterval struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// segments returns the working window of the day with breaks cut out.
+func segments(day entity.ScheduleDay) ([]clockInterval, error) {
+	start, end, err := clockRange(day.StartTime, day.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []clockInterval{{Start: start, End: end}}
+	for _, br := range day.Breaks {
+		breakStart, breakEnd, err := clockRange(br.StartTime, br.EndTime)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []clockInterval
+		for _, seg := range result {
+			if breakEnd <= seg.Start || breakStart >= seg.End {
+				next = append(next, seg)
+				continue
+			}
+			if breakStart > seg.Start {
+				next = append(next, clockInterval{Start: seg.Start, End: breakStart})
+			}
+			if breakEnd < seg.End {
+				next = append(next, clockInterval{Start: breakEnd, End: seg.End})
+			}
+		}
+		result = next
+	}
+
+	return result, nil
+}
+
+func clockRange(startClock, endClock string) (time.Duration, time.Duration, error) {
+	start, err := timepkg.ParseClock(startClock)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := timepkg.ParseClock(endClock)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end <= start {
+		return 0, 0, fmt.Errorf("end time %s must be after start time %s", endClock, startClock)
+	}
+
+	return start, end, nil
+}
+
+// Generator materialises doctor_availability rows from weekly schedules.
+type Generator struct {
+	storage    repo.StorageI
+	logger     logger.Logger
+	weeksAhead int
+	location   *time.Location
+}
+
+func NewGenerator(storage repo.StorageI, logger logger.Logger, weeksAhead int, location *time.Location) *Generator {
+	return &Generator{
+		storage:    storage,
+		logger:     logger,
+		weeksAhead: weeksAhead,
+		location:   location,
+	}
+}
+
+// Generate tops up the doctor's availability for the configured number of
+// weeks ahead. Slots already present, or overlapping manual slots, are kept.
+// Days worked at a branch are laid out on the branch's wall clock.
+func (g *Generator) Generate(ctx context.Context, schedule *entity.Schedule) (int, error) {
+	byBranch := make(map[int64][]entity.ScheduleDay)
+	for _, day := range schedule.Days {
+		byBranch[day.BranchID] = append(byBranch[day.BranchID], day)
+	}
+
+	now := time.Now()
+	var upcoming []*entity.Availability
+	for branchID, days := range byBranch {
+		loc, err := g.branchLocation(ctx, branchID)
+		if err != nil {
+			return 0, err
+		}
+
+		part := *schedule
+		part.Days = days
+		slots, err := Slots(&part, now, now.AddDate(0, 0, 7*g.weeksAhead), loc)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, slot := range slots {
+			if slot.StartTime.After(now) {
+				upcoming = append(upcoming, slot)
+			}
+		}
+	}
+	if len(upcoming) == 0 {
+		return 0, nil
+	}
+
+	return g.storage.Appointment().FillAvailabilities(ctx, upcoming)
+}
+
+// branchLocation returns the zone of the branch, or the hospital zone for
+// days not tied to a branch.
+func (g *Generator) branchLocation(ctx context.Context, branchID int64) (*time.Location, error) {
+	if branchID == 0 {
+		return g.location, nil
+	}
+
+	branch, err := g.storage.Branch().Get(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	return time.LoadLocation(branch.Timezone)
+}
+
+// TopUp runs Generate for every stored schedule.
+func (g *Generator) TopUp(ctx context.Context) error {
+	schedules, err := g.storage.Schedule().List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		created, err := g.Generate(ctx, schedule)
+		if err != nil {
+			g.logger.Error("schedule generation failed", logger.Error(err))
+			continue
+		}
+		if created != 0 {
+			g.logger.Info(fmt.Sprintf("generated %d slots for doctor %s", created, schedule.DoctorID))
+		}
+	}
+
+	return nil
+}
+
+// Run tops up availability of every tenant immediately and then on every
+// interval until ctx is cancelled.
+func (g *Generator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := tenant.Each(ctx, g.storage.Tenant(), g.TopUp); err != nil {
+			g.logger.Error("schedule top up failed", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package waitlist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Abdulazizxoshimov/Hospital/config"
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+	"github.com/Abdulazizxoshimov/Hospital/internal/repo"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/gmail"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/logger"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/tenant"
+)
+
+const offerTemplate = "./pkg/gmail/waitlistoffer.html"
+
+// Dispatcher hands freed slots to waitlisted patients and notifies them.
+type Dispatcher struct {
+	storage repo.StorageI
+	logger  logger.Logger
+	config  config.Config
+}
+
+func NewDispatcher(storage repo.StorageI, logger logger.Logger, config config.Config) *Dispatcher {
+	return &Dispatcher{
+		storage: storage,
+		logger:  logger,
+		config:  config,
+	}
+}
+
+// Offer holds free slots of the doctor for the next waiting patients and
+// mails each of them their offer.
+func (d *Dispatcher) Offer(ctx context.Context, doctorID string) error {
+	offers, err := d.storage.Waitlist().OfferNext(ctx, doctorID, d.config.Waitlist.HoldDuration)
+	if err != nil {
+		return err
+	}
+
+	for _, offer := range offers {
+		if err := d.notify(ctx, offer); err != nil {
+			d.logger.Error(fmt.Sprintf("waitlist offer %d: notification failed", offer.ID), logger.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// Sweep expires offers that were not accepted in time and offers the
+// released slots, along with any other free slots, to the next patients.
+func (d *Dispatcher) Sweep(ctx context.Context) error {
+	if _, err := d.storage.Waitlist().ExpireOffers(ctx); err != nil {
+		return err
+	}
+
+	doctors, err := d.storage.Waitlist().WaitingDoctors(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, doctorID := range doctors {
+		if err := d.Offer(ctx, doctorID); err != nil {
+			d.logger.Error("waitlist offer failed for doctor "+doctorID, logger.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// Run sweeps the waitlist of every tenant on every interval until ctx is
+// cancelled.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := tenant.Each(ctx, d.storage.Tenant(), d.Sweep); err != nil {
+			d.logger.Error("waitlist sweep failed", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) notify(ctx context.Context, offer *entity.WaitlistEntry) error {
+	user, err := d.storage.User().Get(ctx, map[string]string{"id": offer.PatientID})
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return nil
+	}
+
+	return gmail.SendTemplateGmail(user.Email, "Hospital\n", offerTemplate, struct {
+		EntryID   int64
+		StartTime string
+		ExpiresAt string
+	}{
+		EntryID:   offer.ID,
+		StartTime: offer.OfferedStartTime.In(d.config.Location).Format("2006-01-02 15:04"),
+		ExpiresAt: offer.OfferExpiresAt.In(d.config.Location).Format("15:04"),
+	}, d.config)
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_doctor_availability_doctor_time;
+
+ALTER TABLE doctor_availability DROP CONSTRAINT IF EXISTS doctor_availability_time_check;
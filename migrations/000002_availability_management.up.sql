@@ -0,0 +1,4 @@
+ALTER TABLE doctor_availability
+    ADD CONSTRAINT doctor_availability_time_check CHECK (end_time > start_time);
+
+CREATE INDEX idx_doctor_availability_doctor_time ON doctor_availability(doctor_id, start_time, end_time);
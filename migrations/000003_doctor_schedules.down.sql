@@ -0,0 +1 @@
+DROP TABLE IF EXISTS doctor_schedules;
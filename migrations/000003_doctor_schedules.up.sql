@@ -0,0 +1,8 @@
+CREATE TABLE doctor_schedules (
+    id SERIAL PRIMARY KEY,
+    doctor_id uuid UNIQUE NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    slot_minutes INT NOT NULL CHECK (slot_minutes > 0),
+    days JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
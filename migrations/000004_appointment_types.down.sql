@@ -0,0 +1,8 @@
+ALTER TABLE appointments
+    DROP COLUMN IF EXISTS buffer_minutes,
+    DROP COLUMN IF EXISTS end_time,
+    DROP COLUMN IF EXISTS appointment_type_id;
+
+DROP TABLE IF EXISTS doctor_appointment_types;
+
+DROP TABLE IF EXISTS appointment_types;
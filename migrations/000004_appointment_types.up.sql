@@ -0,0 +1,30 @@
+CREATE TABLE appointment_types (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) UNIQUE NOT NULL,
+    description TEXT,
+    duration_minutes INT NOT NULL CHECK (duration_minutes > 0),
+    buffer_minutes INT NOT NULL DEFAULT 0 CHECK (buffer_minutes >= 0),
+    created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE TABLE doctor_appointment_types (
+    doctor_id uuid NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    appointment_type_id INT NOT NULL REFERENCES appointment_types(id) ON DELETE CASCADE,
+    duration_minutes INT CHECK (duration_minutes > 0),
+    buffer_minutes INT CHECK (buffer_minutes >= 0),
+    PRIMARY KEY (doctor_id, appointment_type_id)
+);
+
+INSERT INTO appointment_types (name, description, duration_minutes, buffer_minutes)
+VALUES ('consultation', 'Initial consultation', 60, 0),
+       ('follow-up', 'Follow-up visit', 30, 0),
+       ('procedure', 'Procedure', 90, 15);
+
+ALTER TABLE appointments
+    ADD COLUMN appointment_type_id INT REFERENCES appointment_types(id) ON DELETE SET NULL,
+    ADD COLUMN end_time TIMESTAMP,
+    ADD COLUMN buffer_minutes INT NOT NULL DEFAULT 0;
+
+UPDATE appointments SET end_time = start_time + INTERVAL '1 hour' WHERE end_time IS NULL;
+
+ALTER TABLE appointments ALTER COLUMN end_time SET NOT NULL;
@@ -0,0 +1,22 @@
+ALTER TABLE appointment_types
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'Asia/Tashkent';
+
+ALTER TABLE doctor_schedules
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'Asia/Tashkent',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'Asia/Tashkent';
+
+ALTER TABLE appointments
+    ALTER COLUMN start_time TYPE TIMESTAMP USING start_time AT TIME ZONE 'Asia/Tashkent',
+    ALTER COLUMN end_time TYPE TIMESTAMP USING end_time AT TIME ZONE 'Asia/Tashkent',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'Asia/Tashkent';
+
+ALTER TABLE doctor_availability
+    ALTER COLUMN start_time TYPE TIMESTAMP USING start_time AT TIME ZONE 'Asia/Tashkent',
+    ALTER COLUMN end_time TYPE TIMESTAMP USING end_time AT TIME ZONE 'Asia/Tashkent',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'Asia/Tashkent';
+
+ALTER TABLE doctors
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'Asia/Tashkent';
+
+ALTER TABLE users
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'Asia/Tashkent';
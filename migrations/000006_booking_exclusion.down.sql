@@ -0,0 +1,3 @@
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_no_overlap;
+
+ALTER TABLE doctor_availability DROP CONSTRAINT IF EXISTS doctor_availability_no_overlap;
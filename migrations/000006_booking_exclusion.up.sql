@@ -0,0 +1,12 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE doctor_availability
+    ADD CONSTRAINT doctor_availability_no_overlap
+    EXCLUDE USING gist (doctor_id WITH =, tstzrange(start_time, end_time) WITH &&);
+
+-- cancelled appointments and no-shows release their slots, so they must
+-- not keep blocking the time range for the next booking either
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_no_overlap
+    EXCLUDE USING gist (doctor_id WITH =, tstzrange(start_time, end_time) WITH &&)
+    WHERE (status NOT IN ('cancelled', 'no_show'));
@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS appointment_status_history;
+
+UPDATE appointments SET status = 'scheduled' WHERE status IN ('confirmed', 'checked_in', 'in_progress');
+UPDATE appointments SET status = 'cancelled' WHERE status = 'no_show';
+
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check;
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_status_check CHECK (status IN ('scheduled', 'completed', 'cancelled'));
+
+-- the unique start time is not restored: cancelled appointments kept since
+-- may share a start time with a live one, and appointments_no_overlap
+-- still guards live bookings until 000006 is rolled back
+
+UPDATE users SET role = 'user' WHERE role = 'receptionist';
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'doctor', 'admin'));
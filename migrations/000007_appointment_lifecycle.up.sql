@@ -0,0 +1,24 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'receptionist', 'doctor', 'admin'));
+
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check;
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_status_check
+    CHECK (status IN ('scheduled', 'confirmed', 'checked_in', 'in_progress', 'completed', 'cancelled', 'no_show'));
+
+-- cancelled appointments now stay in the table, so the same start time can
+-- be booked again; appointments_no_overlap guards live bookings instead
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_doctor_id_start_time_key;
+
+CREATE TABLE appointment_status_history (
+    id SERIAL PRIMARY KEY,
+    appointment_id INT NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    changed_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT,
+    changed_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX idx_appointment_status_history_appointment ON appointment_status_history(appointment_id);
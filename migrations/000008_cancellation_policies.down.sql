@@ -0,0 +1,3 @@
+ALTER TABLE appointments DROP COLUMN IF EXISTS reschedule_count;
+
+DROP TABLE IF EXISTS cancellation_policies;
@@ -0,0 +1,17 @@
+CREATE TABLE cancellation_policies (
+    id SERIAL PRIMARY KEY,
+    appointment_type_id INT UNIQUE REFERENCES appointment_types(id) ON DELETE CASCADE,
+    cutoff_minutes INT NOT NULL DEFAULT 1440 CHECK (cutoff_minutes >= 0),
+    max_reschedules INT NOT NULL DEFAULT -1,
+    late_cancel_as_no_show BOOLEAN NOT NULL DEFAULT FALSE,
+    allow_admin_override BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- the row without an appointment type is the hospital wide default
+CREATE UNIQUE INDEX idx_cancellation_policies_default ON cancellation_policies((appointment_type_id IS NULL)) WHERE appointment_type_id IS NULL;
+
+INSERT INTO cancellation_policies (appointment_type_id, cutoff_minutes, max_reschedules, late_cancel_as_no_show, allow_admin_override)
+VALUES (NULL, 1440, -1, FALSE, TRUE);
+
+ALTER TABLE appointments ADD COLUMN reschedule_count INT NOT NULL DEFAULT 0;
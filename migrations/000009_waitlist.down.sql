@@ -0,0 +1,5 @@
+ALTER TABLE doctor_availability
+    DROP COLUMN IF EXISTS held_until,
+    DROP COLUMN IF EXISTS held_by;
+
+DROP TABLE IF EXISTS waitlist_entries;
@@ -0,0 +1,23 @@
+CREATE TABLE waitlist_entries (
+    id SERIAL PRIMARY KEY,
+    doctor_id uuid NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    patient_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    appointment_type_id INT REFERENCES appointment_types(id) ON DELETE SET NULL,
+    from_time TIMESTAMPTZ NOT NULL,
+    to_time TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting'
+        CHECK (status IN ('waiting', 'offered', 'accepted', 'expired', 'cancelled')),
+    offered_start_time TIMESTAMPTZ,
+    offer_expires_at TIMESTAMPTZ,
+    appointment_id INT REFERENCES appointments(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CHECK (to_time > from_time)
+);
+
+CREATE INDEX idx_waitlist_entries_doctor_status ON waitlist_entries(doctor_id, status, created_at);
+CREATE INDEX idx_waitlist_entries_patient ON waitlist_entries(patient_id);
+
+-- slots offered to a waitlisted patient are held for them until held_until
+ALTER TABLE doctor_availability
+    ADD COLUMN held_by INT REFERENCES waitlist_entries(id) ON DELETE SET NULL,
+    ADD COLUMN held_until TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_doctor_availability_free;
+DROP INDEX IF EXISTS idx_doctors_specialization;
+DROP INDEX IF EXISTS idx_doctors_languages;
+
+ALTER TABLE doctors DROP COLUMN IF EXISTS languages;
@@ -0,0 +1,7 @@
+ALTER TABLE doctors ADD COLUMN languages TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_doctors_languages ON doctors USING GIN (languages);
+CREATE INDEX idx_doctors_specialization ON doctors(LOWER(specialization));
+
+-- the slot search only ever looks at free slots, earliest first
+CREATE INDEX idx_doctor_availability_free ON doctor_availability(start_time) WHERE is_booked IS NOT TRUE;
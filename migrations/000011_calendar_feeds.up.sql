@@ -0,0 +1,5 @@
+CREATE TABLE calendar_feeds (
+    user_id uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token VARCHAR(64) UNIQUE NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
@@ -0,0 +1,14 @@
+CREATE TABLE external_busy_times (
+    id SERIAL PRIMARY KEY,
+    doctor_id uuid NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    source VARCHAR(255) NOT NULL,
+    uid TEXT NOT NULL,
+    summary TEXT NOT NULL DEFAULT '',
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    UNIQUE (doctor_id, source, uid),
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX idx_external_busy_times_doctor_time ON external_busy_times(doctor_id, start_time, end_time);
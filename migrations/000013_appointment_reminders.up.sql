@@ -0,0 +1,7 @@
+-- one row per reminder sent, so restarts never send the same one twice
+CREATE TABLE appointment_reminders (
+    appointment_id INT NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+    offset_minutes INT NOT NULL,
+    sent_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (appointment_id, offset_minutes)
+);
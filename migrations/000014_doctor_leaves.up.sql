@@ -0,0 +1,17 @@
+CREATE TABLE doctor_leaves (
+    id SERIAL PRIMARY KEY,
+    doctor_id uuid NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    -- the leave as instants, from the start of start_date to the end of
+    -- end_date in the zone it was entered in
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CHECK (end_date >= start_date),
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX idx_doctor_leaves_doctor_time ON doctor_leaves(doctor_id, start_time, end_time);
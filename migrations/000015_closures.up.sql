@@ -0,0 +1,19 @@
+CREATE TABLE closures (
+    id SERIAL PRIMARY KEY,
+    -- the branch that is closed, NULL when the whole hospital is
+    branch_id INT,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    -- set for a partial closure of a single day
+    from_time TIME,
+    to_time TIME,
+    reason TEXT NOT NULL DEFAULT '',
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CHECK (end_date >= start_date),
+    CHECK (end_time > start_time),
+    CHECK ((from_time IS NULL) = (to_time IS NULL))
+);
+
+CREATE INDEX idx_closures_time ON closures(start_time, end_time);
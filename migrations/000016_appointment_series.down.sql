@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_appointments_series;
+
+ALTER TABLE appointments DROP COLUMN IF EXISTS series_id;
+
+DROP TABLE IF EXISTS appointment_series;
@@ -0,0 +1,18 @@
+CREATE TABLE appointment_series (
+    id SERIAL PRIMARY KEY,
+    doctor_id uuid NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    patient_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    appointment_type_id INT REFERENCES appointment_types(id) ON DELETE SET NULL,
+    start_time TIMESTAMPTZ NOT NULL,
+    interval_weeks INT NOT NULL CHECK (interval_weeks IN (1, 2)),
+    -- the series ends after occurrences visits or on until, whichever is set
+    occurrences INT,
+    until DATE,
+    mode VARCHAR(20) NOT NULL CHECK (mode IN ('all_or_nothing', 'best_effort')),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CHECK ((occurrences IS NULL) <> (until IS NULL))
+);
+
+ALTER TABLE appointments ADD COLUMN series_id INT REFERENCES appointment_series(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_appointments_series ON appointments(series_id, start_time);
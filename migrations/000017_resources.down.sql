@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS appointment_resources;
+
+ALTER TABLE appointment_types DROP COLUMN IF EXISTS required_resources;
+
+DROP TABLE IF EXISTS resource_hours;
+
+DROP TABLE IF EXISTS resources;
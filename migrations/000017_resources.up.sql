@@ -0,0 +1,35 @@
+CREATE TABLE resources (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('room', 'equipment')),
+    -- what appointment types ask for, e.g. 'exam-room' or 'ultrasound'
+    category VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX idx_resources_category ON resources(category) WHERE active;
+
+-- weekly hours in the hospital zone; a resource without any is always open
+CREATE TABLE resource_hours (
+    resource_id INT NOT NULL REFERENCES resources(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX idx_resource_hours_resource ON resource_hours(resource_id, weekday);
+
+ALTER TABLE appointment_types ADD COLUMN required_resources TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE appointment_resources (
+    appointment_id INT NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+    resource_id INT NOT NULL REFERENCES resources(id) ON DELETE CASCADE,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (appointment_id, resource_id),
+    CONSTRAINT appointment_resources_no_overlap
+        EXCLUDE USING gist (resource_id WITH =, tstzrange(start_time, end_time) WITH &&)
+);
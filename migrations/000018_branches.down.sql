@@ -0,0 +1,11 @@
+ALTER TABLE closures DROP CONSTRAINT IF EXISTS closures_branch_id_fkey;
+
+ALTER TABLE waitlist_entries DROP COLUMN IF EXISTS branch_id;
+ALTER TABLE appointments DROP COLUMN IF EXISTS branch_id;
+ALTER TABLE doctor_availability DROP COLUMN IF EXISTS branch_id;
+
+DROP TABLE IF EXISTS doctor_branches;
+
+DROP TABLE IF EXISTS branch_hours;
+
+DROP TABLE IF EXISTS branches;
@@ -0,0 +1,41 @@
+CREATE TABLE branches (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    address TEXT NOT NULL DEFAULT '',
+    -- IANA zone the branch's opening hours and closures are given in
+    timezone VARCHAR(64) NOT NULL,
+    phone VARCHAR(30) NOT NULL DEFAULT '',
+    email VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- weekly hours in the branch's zone; a branch without any is always open
+CREATE TABLE branch_hours (
+    branch_id INT NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX idx_branch_hours_branch ON branch_hours(branch_id, weekday);
+
+CREATE TABLE doctor_branches (
+    doctor_id uuid NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    branch_id INT NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    PRIMARY KEY (doctor_id, branch_id)
+);
+
+CREATE INDEX idx_doctor_branches_branch ON doctor_branches(branch_id);
+
+-- NULL for slots and appointments not tied to a branch, and for waitlist
+-- entries happy with any branch
+ALTER TABLE doctor_availability ADD COLUMN branch_id INT REFERENCES branches(id) ON DELETE SET NULL;
+ALTER TABLE appointments ADD COLUMN branch_id INT REFERENCES branches(id) ON DELETE SET NULL;
+ALTER TABLE waitlist_entries ADD COLUMN branch_id INT REFERENCES branches(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_doctor_availability_branch ON doctor_availability(branch_id, start_time);
+CREATE INDEX idx_appointments_branch ON appointments(branch_id, start_time);
+
+ALTER TABLE closures
+    ADD CONSTRAINT closures_branch_id_fkey FOREIGN KEY (branch_id) REFERENCES branches(id) ON DELETE CASCADE;
@@ -0,0 +1,62 @@
+DROP TRIGGER IF EXISTS tenants_seed ON tenants;
+DROP FUNCTION IF EXISTS seed_tenant();
+
+-- only the default hospital's data can go back to a single tenant schema
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'users', 'doctors', 'doctor_availability', 'appointments',
+        'doctor_schedules', 'appointment_types', 'doctor_appointment_types',
+        'appointment_status_history', 'cancellation_policies', 'waitlist_entries',
+        'calendar_feeds', 'external_busy_times', 'appointment_reminders',
+        'doctor_leaves', 'closures', 'appointment_series', 'resources',
+        'resource_hours', 'appointment_resources', 'branches', 'branch_hours',
+        'doctor_branches'
+    ] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+        EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('DELETE FROM %I WHERE tenant_id <> 1', t);
+    END LOOP;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_cancellation_policies_default;
+
+ALTER TABLE branches DROP CONSTRAINT branches_name_key;
+ALTER TABLE resources DROP CONSTRAINT resources_name_key;
+ALTER TABLE appointment_types DROP CONSTRAINT appointment_types_name_key;
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+ALTER TABLE users DROP CONSTRAINT users_username_key;
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'users', 'doctors', 'doctor_availability', 'appointments',
+        'doctor_schedules', 'appointment_types', 'doctor_appointment_types',
+        'appointment_status_history', 'cancellation_policies', 'waitlist_entries',
+        'calendar_feeds', 'external_busy_times', 'appointment_reminders',
+        'doctor_leaves', 'closures', 'appointment_series', 'resources',
+        'resource_hours', 'appointment_resources', 'branches', 'branch_hours',
+        'doctor_branches'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I DROP COLUMN tenant_id', t);
+    END LOOP;
+END
+$$;
+
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE appointment_types ADD CONSTRAINT appointment_types_name_key UNIQUE (name);
+ALTER TABLE resources ADD CONSTRAINT resources_name_key UNIQUE (name);
+ALTER TABLE branches ADD CONSTRAINT branches_name_key UNIQUE (name);
+
+CREATE UNIQUE INDEX idx_cancellation_policies_default ON cancellation_policies((appointment_type_id IS NULL)) WHERE appointment_type_id IS NULL;
+
+DROP FUNCTION IF EXISTS current_tenant();
+
+DROP TABLE IF EXISTS tenants;
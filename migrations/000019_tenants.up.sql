@@ -0,0 +1,79 @@
+-- Every hospital hosted on the deployment is a tenant. Rows of every table
+-- belong to one tenant and row-level security hides the other tenants'
+-- rows. The application scopes each connection it hands out by setting
+-- app.tenant_id; a connection without it sees and writes nothing.
+--
+-- Policies are forced on the table owner too, so the service must not
+-- connect as a superuser, which bypasses them. Data fixes run by hand
+-- need `SET app.tenant_id = ...` first.
+CREATE TABLE tenants (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- existing data becomes the first tenant
+INSERT INTO tenants (id, name) VALUES (1, 'default');
+SELECT setval('tenants_id_seq', 1);
+
+CREATE FUNCTION current_tenant() RETURNS INT
+LANGUAGE sql STABLE AS $$
+    SELECT NULLIF(current_setting('app.tenant_id', true), '')::int
+$$;
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'users', 'doctors', 'doctor_availability', 'appointments',
+        'doctor_schedules', 'appointment_types', 'doctor_appointment_types',
+        'appointment_status_history', 'cancellation_policies', 'waitlist_entries',
+        'calendar_feeds', 'external_busy_times', 'appointment_reminders',
+        'doctor_leaves', 'closures', 'appointment_series', 'resources',
+        'resource_hours', 'appointment_resources', 'branches', 'branch_hours',
+        'doctor_branches'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE CASCADE', t);
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN tenant_id SET DEFAULT current_tenant()', t);
+        EXECUTE format('CREATE INDEX idx_%s_tenant ON %I(tenant_id)', t, t);
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('CREATE POLICY tenant_isolation ON %I USING (tenant_id = current_tenant()) WITH CHECK (tenant_id = current_tenant())', t);
+    END LOOP;
+END
+$$;
+
+-- names only have to be unique within a hospital
+ALTER TABLE users DROP CONSTRAINT users_username_key;
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (tenant_id, username);
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (tenant_id, email);
+
+ALTER TABLE appointment_types DROP CONSTRAINT appointment_types_name_key;
+ALTER TABLE appointment_types ADD CONSTRAINT appointment_types_name_key UNIQUE (tenant_id, name);
+
+ALTER TABLE resources DROP CONSTRAINT resources_name_key;
+ALTER TABLE resources ADD CONSTRAINT resources_name_key UNIQUE (tenant_id, name);
+
+ALTER TABLE branches DROP CONSTRAINT branches_name_key;
+ALTER TABLE branches ADD CONSTRAINT branches_name_key UNIQUE (tenant_id, name);
+
+-- every hospital has its own default cancellation policy
+DROP INDEX idx_cancellation_policies_default;
+CREATE UNIQUE INDEX idx_cancellation_policies_default ON cancellation_policies(tenant_id) WHERE appointment_type_id IS NULL;
+
+CREATE FUNCTION seed_tenant() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    previous TEXT := current_setting('app.tenant_id', true);
+BEGIN
+    PERFORM set_config('app.tenant_id', NEW.id::text, true);
+    INSERT INTO cancellation_policies (appointment_type_id) VALUES (NULL);
+    PERFORM set_config('app.tenant_id', COALESCE(previous, ''), true);
+    RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER tenants_seed AFTER INSERT ON tenants
+    FOR EACH ROW EXECUTE FUNCTION seed_tenant();
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS encounter_addenda;
+DROP TABLE IF EXISTS encounters;
+DROP FUNCTION IF EXISTS encounter_addenda_immutable();
+DROP FUNCTION IF EXISTS encounters_signed_immutable();
@@ -0,0 +1,68 @@
+-- the clinical record of a visit, written by its doctor
+CREATE TABLE encounters (
+    id SERIAL PRIMARY KEY,
+    appointment_id INT UNIQUE NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+    status VARCHAR(10) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'signed')),
+    chief_complaint TEXT NOT NULL DEFAULT '',
+    -- SOAP notes
+    subjective TEXT NOT NULL DEFAULT '',
+    objective TEXT NOT NULL DEFAULT '',
+    assessment TEXT NOT NULL DEFAULT '',
+    plan TEXT NOT NULL DEFAULT '',
+    -- [{"Code": "J06.9", "Description": "..."}]
+    diagnoses JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    signed_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    signed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    tenant_id INT NOT NULL DEFAULT current_tenant() REFERENCES tenants(id) ON DELETE CASCADE,
+    CHECK ((status = 'signed') = (signed_at IS NOT NULL))
+);
+
+-- amendments to signed notes; they are only ever added
+CREATE TABLE encounter_addenda (
+    id SERIAL PRIMARY KEY,
+    encounter_id INT NOT NULL REFERENCES encounters(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    created_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    tenant_id INT NOT NULL DEFAULT current_tenant() REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_encounter_addenda_encounter ON encounter_addenda(encounter_id);
+CREATE INDEX idx_encounters_tenant ON encounters(tenant_id);
+CREATE INDEX idx_encounter_addenda_tenant ON encounter_addenda(tenant_id);
+
+ALTER TABLE encounters ENABLE ROW LEVEL SECURITY;
+ALTER TABLE encounters FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON encounters USING (tenant_id = current_tenant()) WITH CHECK (tenant_id = current_tenant());
+
+ALTER TABLE encounter_addenda ENABLE ROW LEVEL SECURITY;
+ALTER TABLE encounter_addenda FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON encounter_addenda USING (tenant_id = current_tenant()) WITH CHECK (tenant_id = current_tenant());
+
+-- signed notes are part of the medical record and never change; the
+-- application adds addenda instead
+CREATE FUNCTION encounters_signed_immutable() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF OLD.status = 'signed' THEN
+        RAISE EXCEPTION 'encounter % is signed', OLD.id;
+    END IF;
+    RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER encounters_signed_immutable BEFORE UPDATE ON encounters
+    FOR EACH ROW EXECUTE FUNCTION encounters_signed_immutable();
+
+CREATE FUNCTION encounter_addenda_immutable() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'addenda cannot be changed';
+END
+$$;
+
+CREATE TRIGGER encounter_addenda_immutable BEFORE UPDATE ON encounter_addenda
+    FOR EACH ROW EXECUTE FUNCTION encounter_addenda_immutable();
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS prescription_items;
+DROP TABLE IF EXISTS prescriptions;
+DROP TABLE IF EXISTS patient_allergies;
+DROP TABLE IF EXISTS medications;
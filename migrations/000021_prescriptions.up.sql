@@ -0,0 +1,65 @@
+-- the hospital's medication catalogue
+CREATE TABLE medications (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    -- active substances in lower case, matched against allergies and
+    -- interaction rules
+    ingredients TEXT[] NOT NULL DEFAULT '{}',
+    form VARCHAR(50) NOT NULL DEFAULT '',
+    strength VARCHAR(50) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    tenant_id INT NOT NULL DEFAULT current_tenant() REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT medications_name_key UNIQUE (tenant_id, name, form, strength)
+);
+
+CREATE TABLE patient_allergies (
+    id SERIAL PRIMARY KEY,
+    patient_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- lower case substance, e.g. 'penicillin'
+    substance VARCHAR(200) NOT NULL,
+    reaction TEXT NOT NULL DEFAULT '',
+    created_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    tenant_id INT NOT NULL DEFAULT current_tenant() REFERENCES tenants(id) ON DELETE CASCADE,
+    UNIQUE (tenant_id, patient_id, substance)
+);
+
+CREATE TABLE prescriptions (
+    id SERIAL PRIMARY KEY,
+    encounter_id INT NOT NULL REFERENCES encounters(id) ON DELETE CASCADE,
+    notes TEXT NOT NULL DEFAULT '',
+    -- allergy and interaction warnings the doctor acknowledged
+    alerts JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    tenant_id INT NOT NULL DEFAULT current_tenant() REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE TABLE prescription_items (
+    id SERIAL PRIMARY KEY,
+    prescription_id INT NOT NULL REFERENCES prescriptions(id) ON DELETE CASCADE,
+    medication_id INT NOT NULL REFERENCES medications(id),
+    dosage VARCHAR(100) NOT NULL,
+    frequency VARCHAR(100) NOT NULL,
+    duration_days INT NOT NULL CHECK (duration_days > 0),
+    instructions TEXT NOT NULL DEFAULT '',
+    tenant_id INT NOT NULL DEFAULT current_tenant() REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_patient_allergies_patient ON patient_allergies(patient_id);
+CREATE INDEX idx_prescriptions_encounter ON prescriptions(encounter_id);
+CREATE INDEX idx_prescription_items_prescription ON prescription_items(prescription_id);
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['medications', 'patient_allergies', 'prescriptions', 'prescription_items'] LOOP
+        EXECUTE format('CREATE INDEX idx_%s_tenant ON %I(tenant_id)', t, t);
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('CREATE POLICY tenant_isolation ON %I USING (tenant_id = current_tenant()) WITH CHECK (tenant_id = current_tenant())', t);
+    END LOOP;
+END
+$$;
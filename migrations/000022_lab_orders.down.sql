@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS lab_results;
+DROP TABLE IF EXISTS lab_orders;
+DROP FUNCTION IF EXISTS lab_results_released_immutable();
+DROP FUNCTION IF EXISTS lab_orders_released_immutable();
+
+UPDATE users SET role = 'user' WHERE role = 'lab';
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'receptionist', 'doctor', 'admin'));
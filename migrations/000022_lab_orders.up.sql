@@ -0,0 +1,83 @@
+-- lab staff record results; they get the role the same way receptionists do
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'receptionist', 'doctor', 'lab', 'admin'));
+
+CREATE TABLE lab_orders (
+    id SERIAL PRIMARY KEY,
+    appointment_id INT NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+    test_code VARCHAR(50) NOT NULL,
+    test_name VARCHAR(200) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'ordered'
+        CHECK (status IN ('ordered', 'resulted', 'released', 'cancelled')),
+    -- narrative report for tests without numeric results, e.g. pathology
+    report TEXT NOT NULL DEFAULT '',
+    ordered_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    resulted_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    resulted_at TIMESTAMPTZ,
+    released_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    released_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    tenant_id INT NOT NULL DEFAULT current_tenant() REFERENCES tenants(id) ON DELETE CASCADE,
+    CHECK ((status = 'released') = (released_at IS NOT NULL))
+);
+
+CREATE TABLE lab_results (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES lab_orders(id) ON DELETE CASCADE,
+    analyte VARCHAR(100) NOT NULL,
+    -- numeric results carry a value and usually a reference range, the
+    -- others a text value such as 'negative'
+    value NUMERIC,
+    text_value TEXT NOT NULL DEFAULT '',
+    unit VARCHAR(30) NOT NULL DEFAULT '',
+    reference_low NUMERIC,
+    reference_high NUMERIC,
+    flag VARCHAR(10) NOT NULL DEFAULT '' CHECK (flag IN ('', 'low', 'high', 'abnormal')),
+    tenant_id INT NOT NULL DEFAULT current_tenant() REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_lab_orders_appointment ON lab_orders(appointment_id);
+CREATE INDEX idx_lab_orders_status ON lab_orders(status);
+CREATE INDEX idx_lab_results_order ON lab_results(order_id);
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['lab_orders', 'lab_results'] LOOP
+        EXECUTE format('CREATE INDEX idx_%s_tenant ON %I(tenant_id)', t, t);
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('CREATE POLICY tenant_isolation ON %I USING (tenant_id = current_tenant()) WITH CHECK (tenant_id = current_tenant())', t);
+    END LOOP;
+END
+$$;
+
+-- released results are part of the medical record and never change
+CREATE FUNCTION lab_orders_released_immutable() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF OLD.status IN ('released', 'cancelled') THEN
+        RAISE EXCEPTION 'lab order % is %', OLD.id, OLD.status;
+    END IF;
+    RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER lab_orders_released_immutable BEFORE UPDATE ON lab_orders
+    FOR EACH ROW EXECUTE FUNCTION lab_orders_released_immutable();
+
+CREATE FUNCTION lab_results_released_immutable() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM lab_orders WHERE id = NEW.order_id AND status = 'released') THEN
+        RAISE EXCEPTION 'results of lab order % are released', NEW.order_id;
+    END IF;
+    RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER lab_results_released_immutable BEFORE INSERT OR UPDATE ON lab_results
+    FOR EACH ROW EXECUTE FUNCTION lab_results_released_immutable();
@@ -0,0 +1,26 @@
+-- files uploaded for a user, an appointment or an encounter; the file
+-- itself lives in the file store under object_key
+CREATE TABLE attachments (
+    id SERIAL PRIMARY KEY,
+    user_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    appointment_id INT REFERENCES appointments(id) ON DELETE CASCADE,
+    encounter_id INT REFERENCES encounters(id) ON DELETE CASCADE,
+    object_key TEXT NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL CHECK (size >= 0),
+    uploaded_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    tenant_id INT NOT NULL DEFAULT current_tenant() REFERENCES tenants(id) ON DELETE CASCADE,
+    CHECK (num_nonnulls(user_id, appointment_id, encounter_id) = 1),
+    UNIQUE (tenant_id, object_key)
+);
+
+CREATE INDEX idx_attachments_user ON attachments(user_id);
+CREATE INDEX idx_attachments_appointment ON attachments(appointment_id);
+CREATE INDEX idx_attachments_encounter ON attachments(encounter_id);
+CREATE INDEX idx_attachments_tenant ON attachments(tenant_id);
+
+ALTER TABLE attachments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE attachments FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON attachments USING (tenant_id = current_tenant()) WITH CHECK (tenant_id = current_tenant());
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_doctor_availability_hold_token;
+
+ALTER TABLE doctor_availability
+    DROP COLUMN IF EXISTS hold_patient_id,
+    DROP COLUMN IF EXISTS hold_token;
@@ -0,0 +1,8 @@
+-- slots held for a patient's checkout: like waitlist offers they stay out
+-- of reach of everybody else until held_until, so every booking path sees
+-- the hold and not only the ones that ask for it
+ALTER TABLE doctor_availability
+    ADD COLUMN hold_token uuid,
+    ADD COLUMN hold_patient_id uuid REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_doctor_availability_hold_token ON doctor_availability(hold_token);
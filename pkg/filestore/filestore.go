@@ -0,0 +1,45 @@
+// Package filestore keeps uploaded files in a MinIO/S3 bucket or, for
+// development and tests, in a local directory. Files are addressed by
+// slash separated keys chosen by the caller.
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Abdulazizxoshimov/Hospital/config"
+)
+
+// Backends selectable in the configuration.
+const (
+	BackendLocal = "local"
+	BackendMinio = "minio"
+)
+
+var ErrNotFound = errors.New("file not found")
+
+type Store interface {
+	// Put stores size bytes read from r under key, replacing any file
+	// stored there before.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the file stored under key; it returns ErrNotFound when
+	// there is none.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the file stored under key. Missing files are not an
+	// error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the store of the configured backend.
+func New(ctx context.Context, cfg *config.Config) (Store, error) {
+	switch cfg.Attachment.Backend {
+	case BackendLocal:
+		return NewLocal(cfg.Attachment.LocalDir)
+	case BackendMinio:
+		return NewMinio(ctx, cfg)
+	}
+
+	return nil, fmt.Errorf("unknown file storage backend %q", cfg.Attachment.Backend)
+}
@@ -0,0 +1,71 @@
+package filestore
+
+import (
+	"context"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/Abdulazizxoshimov/Hospital/config"
+)
+
+// Minio keeps files in a bucket of a MinIO or other S3 compatible server.
+type Minio struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewMinio connects to the configured server and creates the file upload
+// bucket when it does not exist yet.
+func NewMinio(ctx context.Context, cfg *config.Config) (*Minio, error) {
+	client, err := minio.New(cfg.Minio.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.Minio.AccessKeyID, cfg.Minio.SecretAcessKey, ""),
+		Secure: cfg.Minio.UseSSL,
+		Region: cfg.Minio.Location,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := cfg.Minio.FileUploadBucketName
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err = client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{Region: cfg.Minio.Location}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Minio{client: client, bucket: bucket}, nil
+}
+
+func (m *Minio) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := m.client.PutObject(ctx, m.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Get stats the object before handing it out, since minio only reports a
+// missing object on the first read.
+func (m *Minio) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := m.client.GetObject(ctx, m.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = object.Stat(); err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return object, nil
+}
+
+func (m *Minio) Delete(ctx context.Context, key string) error {
+	return m.client.RemoveObject(ctx, m.bucket, key, minio.RemoveObjectOptions{})
+}
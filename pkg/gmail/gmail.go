@@ -43,3 +43,23 @@ func SendCodeGmail(userEmail string, subject string, htmlpath string, cfg config
 	err = smtp.SendMail(cfg.SMTP.SMTPHost+":"+cfg.SMTP.SMTPPort, auth, cfg.SMTP.Email, to, msg)
 	return code, err
 }
+
+// SendTemplateGmail renders the html template at htmlpath with data and
+// mails it to userEmail.
+func SendTemplateGmail(userEmail string, subject string, htmlpath string, data any, cfg config.Config) error {
+	t, err := template.ParseFiles(htmlpath)
+	if err != nil {
+		return err
+	}
+
+	var k bytes.Buffer
+	if err = t.Execute(&k, data); err != nil {
+		return err
+	}
+
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	msg := []byte(fmt.Sprintf("Subject: %s", subject) + mime + k.String())
+	auth := smtp.PlainAuth("", cfg.SMTP.Email, cfg.SMTP.EmailPassword, cfg.SMTP.SMTPHost)
+
+	return smtp.SendMail(cfg.SMTP.SMTPHost+":"+cfg.SMTP.SMTPPort, auth, cfg.SMTP.Email, []string{userEmail}, msg)
+}
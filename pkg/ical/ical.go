@@ -0,0 +1,125 @@
+// Package ical writes RFC 5545 iCalendar documents.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+
+	utcLayout = "20060102T150405Z"
+)
+
+type Calendar struct {
+	Name string
+	// Timezone is the IANA zone clients should display the calendar in.
+	// Event times are always written in UTC, which is unambiguous.
+	Timezone string
+	Events   []Event
+}
+
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Location     string
+	Start        time.Time
+	End          time.Time
+	Status       string
+	Sequence     int
+	LastModified time.Time
+
+	// The fields below are only filled in by Parse and are not written.
+	AllDay       bool
+	Transparent  bool
+	RRule        string
+	ExDates      []time.Time
+	RecurrenceID time.Time
+}
+
+// Write renders the calendar with CRLF line endings and folds lines longer
+// than 75 octets as the RFC requires.
+func Write(w io.Writer, calendar *Calendar) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Hospital//Appointments//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if calendar.Name != "" {
+		line("X-WR-CALNAME", Escape(calendar.Name))
+	}
+	if calendar.Timezone != "" {
+		line("X-WR-TIMEZONE", calendar.Timezone)
+	}
+
+	stamp := time.Now().UTC().Format(utcLayout)
+	for _, event := range calendar.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", event.UID)
+		line("DTSTAMP", stamp)
+		line("DTSTART", event.Start.UTC().Format(utcLayout))
+		line("DTEND", event.End.UTC().Format(utcLayout))
+		line("SUMMARY", Escape(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", Escape(event.Description))
+		}
+		if event.Location != "" {
+			line("LOCATION", Escape(event.Location))
+		}
+		if event.Status != "" {
+			line("STATUS", event.Status)
+		}
+		line("SEQUENCE", strconv.Itoa(event.Sequence))
+		if !event.LastModified.IsZero() {
+			line("LAST-MODIFIED", event.LastModified.UTC().Format(utcLayout))
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+
+	return bw.Flush()
+}
+
+// Escape escapes a TEXT property value.
+func Escape(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
+
+// writeFolded writes one content line, continuing it on lines that start
+// with a space whenever it would exceed 75 octets. UTF-8 sequences are
+// never split.
+func writeFolded(w *bufio.Writer, content string) {
+	// continuation lines lose one octet to the leading space
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(content[:cut])
+		w.WriteString("\r\n ")
+		content = content[cut:]
+		limit = 74
+	}
+	w.WriteString(content)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,447 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout        = "20060102"
+	localLayout       = "20060102T150405"
+	maxOccurrences    = 10000
+	transparentTransp = "TRANSPARENT"
+)
+
+var (
+	ErrUnsupportedRule = errors.New("unsupported recurrence rule")
+	// ErrTooManyOccurrences is returned instead of silently dropping the
+	// occurrences of a rule beyond maxOccurrences.
+	ErrTooManyOccurrences = errors.New("recurrence rule has too many occurrences")
+)
+
+// Parse reads the events of an iCalendar document. Floating times, and
+// times whose TZID is not a known IANA zone, are read in loc. Time zone
+// definitions in the document itself are not interpreted.
+func Parse(r io.Reader, loc *time.Location) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		calendar Calendar
+		event    *Event
+		duration time.Duration
+		depth    int
+	)
+	for number, line := range lines {
+		name, params, value, ok := splitLine(line)
+		if !ok {
+			return nil, fmt.Errorf("ical: line %d: malformed content line", number+1)
+		}
+
+		switch {
+		case name == "BEGIN":
+			depth++
+			if value == "VEVENT" {
+				event, duration = &Event{}, 0
+			}
+			continue
+		case name == "END":
+			depth--
+			if value == "VEVENT" && event != nil {
+				if event.Start.IsZero() {
+					return nil, fmt.Errorf("ical: event %q has no DTSTART", event.UID)
+				}
+				// DURATION may come before DTSTART, so it is applied
+				// once the whole event has been read
+				if event.End.IsZero() && duration != 0 {
+					event.End = event.Start.Add(duration)
+				}
+				if event.End.IsZero() {
+					event.End = event.Start
+					if event.AllDay {
+						event.End = event.Start.AddDate(0, 0, 1)
+					}
+				}
+				calendar.Events = append(calendar.Events, *event)
+				event = nil
+			}
+			continue
+		case event == nil:
+			if name == "X-WR-CALNAME" {
+				calendar.Name = unescape(value)
+			} else if name == "X-WR-TIMEZONE" {
+				calendar.Timezone = value
+			}
+			continue
+		}
+
+		switch name {
+		case "UID":
+			event.UID = value
+		case "SUMMARY":
+			event.Summary = unescape(value)
+		case "DESCRIPTION":
+			event.Description = unescape(value)
+		case "LOCATION":
+			event.Location = unescape(value)
+		case "STATUS":
+			event.Status = strings.ToUpper(value)
+		case "TRANSP":
+			event.Transparent = strings.ToUpper(value) == transparentTransp
+		case "SEQUENCE":
+			event.Sequence, _ = strconv.Atoi(value)
+		case "RRULE":
+			event.RRule = value
+		case "DTSTART":
+			event.Start, event.AllDay, err = parseTime(value, params, loc)
+		case "DTEND":
+			event.End, _, err = parseTime(value, params, loc)
+		case "DURATION":
+			duration, err = parseDuration(value)
+		case "RECURRENCE-ID":
+			event.RecurrenceID, _, err = parseTime(value, params, loc)
+		case "EXDATE":
+			for _, part := range strings.Split(value, ",") {
+				var exdate time.Time
+				if exdate, _, err = parseTime(part, params, loc); err != nil {
+					break
+				}
+				event.ExDates = append(event.ExDates, exdate)
+			}
+		case "LAST-MODIFIED":
+			event.LastModified, _, err = parseTime(value, params, loc)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ical: line %d: %s: %w", number+1, name, err)
+		}
+	}
+	if depth != 0 {
+		return nil, errors.New("ical: unterminated component")
+	}
+
+	return &calendar, nil
+}
+
+// Expand returns every occurrence of the calendar's events that overlaps
+// [from, to), recurring events expanded one event per occurrence. The UID
+// is kept and each occurrence of a recurring event gets its original start
+// as RecurrenceID, so UID and RecurrenceID together identify it. Rescheduled
+// instances (RECURRENCE-ID) replace the occurrence they override.
+//
+// RRULE supports FREQ=DAILY, WEEKLY, MONTHLY and YEARLY with INTERVAL,
+// COUNT, UNTIL and, for weekly rules, BYDAY and WKST. Other rule parts fail
+// with ErrUnsupportedRule rather than being silently misread, and a rule
+// with more than maxOccurrences occurrences in the window fails with
+// ErrTooManyOccurrences rather than being cut short.
+func (c *Calendar) Expand(from, to time.Time) ([]Event, error) {
+	overridden := map[string]bool{}
+	for _, event := range c.Events {
+		if !event.RecurrenceID.IsZero() {
+			overridden[occurrenceKey(event.UID, event.RecurrenceID)] = true
+		}
+	}
+
+	var occurrences []Event
+	for _, event := range c.Events {
+		starts := []time.Time{event.Start}
+		if event.RRule != "" && event.RecurrenceID.IsZero() {
+			var err error
+			if starts, err = recurrences(&event, from, to); err != nil {
+				return nil, fmt.Errorf("ical: event %q: %w", event.UID, err)
+			}
+		}
+
+		length := event.End.Sub(event.Start)
+		for _, start := range starts {
+			if event.RecurrenceID.IsZero() && overridden[occurrenceKey(event.UID, start)] {
+				continue
+			}
+			if excluded(start, event.ExDates) {
+				continue
+			}
+
+			end := start.Add(length)
+			if !start.Before(to) || !end.After(from) {
+				continue
+			}
+
+			occurrence := event
+			occurrence.Start, occurrence.End = start, end
+			occurrence.RRule, occurrence.ExDates = "", nil
+			if event.RRule != "" && event.RecurrenceID.IsZero() {
+				occurrence.RecurrenceID = start
+			}
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	return occurrences, nil
+}
+
+// recurrences lists the starts of the occurrences the event's RRULE
+// produces that overlap [from, to), including DTSTART itself.
+func recurrences(event *Event, from, to time.Time) ([]time.Time, error) {
+	var (
+		freq      string
+		interval  = 1
+		count     int
+		until     time.Time
+		byDay     []time.Weekday
+		weekStart = time.Monday
+	)
+	for _, part := range strings.Split(event.RRule, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			freq = strings.ToUpper(value)
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL=%s", ErrUnsupportedRule, value)
+			}
+			interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: COUNT=%s", ErrUnsupportedRule, value)
+			}
+			count = n
+		case "UNTIL":
+			t, _, err := parseTime(value, nil, event.Start.Location())
+			if err != nil {
+				return nil, fmt.Errorf("%w: UNTIL=%s", ErrUnsupportedRule, value)
+			}
+			until = t
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[strings.ToUpper(day)]
+				if !ok {
+					return nil, fmt.Errorf("%w: BYDAY=%s", ErrUnsupportedRule, value)
+				}
+				byDay = append(byDay, weekday)
+			}
+		case "WKST":
+			weekday, ok := weekdays[strings.ToUpper(value)]
+			if !ok {
+				return nil, fmt.Errorf("%w: WKST=%s", ErrUnsupportedRule, value)
+			}
+			weekStart = weekday
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedRule, part)
+		}
+	}
+	if len(byDay) > 0 && freq != "WEEKLY" {
+		return nil, fmt.Errorf("%w: BYDAY with FREQ=%s", ErrUnsupportedRule, freq)
+	}
+
+	var step func(t time.Time, n int) time.Time
+	switch freq {
+	case "DAILY":
+		step = func(t time.Time, n int) time.Time { return t.AddDate(0, 0, n*interval) }
+	case "WEEKLY":
+		step = func(t time.Time, n int) time.Time { return t.AddDate(0, 0, 7*n*interval) }
+	case "MONTHLY":
+		step = func(t time.Time, n int) time.Time { return t.AddDate(0, n*interval, 0) }
+	case "YEARLY":
+		step = func(t time.Time, n int) time.Time { return t.AddDate(n*interval, 0, 0) }
+	default:
+		return nil, fmt.Errorf("%w: FREQ=%s", ErrUnsupportedRule, freq)
+	}
+
+	// weekly rules with BYDAY repeat on each listed day of every period,
+	// periods starting on the WKST day (Monday by default) of DTSTART's week
+	base := []time.Time{event.Start}
+	if len(byDay) > 0 {
+		base = base[:0]
+		first := event.Start.AddDate(0, 0, -daysFrom(weekStart, event.Start.Weekday()))
+		for _, weekday := range byDay {
+			base = append(base, first.AddDate(0, 0, daysFrom(weekStart, weekday)))
+		}
+		sort.Slice(base, func(i, j int) bool { return base[i].Before(base[j]) })
+	}
+
+	var (
+		length    = event.End.Sub(event.Start)
+		starts    []time.Time
+		generated int
+	)
+	for n := 0; step(base[0], n).Before(to); n++ {
+		for _, first := range base {
+			start := step(first, n)
+			// monthly and yearly steps skip dates the month does not have
+			skipped := (freq == "MONTHLY" || freq == "YEARLY") && start.Day() != first.Day()
+			if skipped || start.Before(event.Start) {
+				continue
+			}
+			if !start.Before(to) || (!until.IsZero() && start.After(until)) || (count > 0 && generated >= count) {
+				return starts, nil
+			}
+			// COUNT counts every occurrence, also those before the window
+			generated++
+			if !start.Add(length).After(from) {
+				continue
+			}
+			if len(starts) == maxOccurrences {
+				return nil, fmt.Errorf("%w: more than %d", ErrTooManyOccurrences, maxOccurrences)
+			}
+			starts = append(starts, start)
+		}
+	}
+
+	return starts, nil
+}
+
+// daysFrom is the number of days from the weekday start to the weekday
+// day, going forward.
+func daysFrom(start, day time.Weekday) int {
+	return (int(day) - int(start) + 7) % 7
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+func excluded(start time.Time, exdates []time.Time) bool {
+	for _, exdate := range exdates {
+		if exdate.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+func occurrenceKey(uid string, start time.Time) string {
+	return uid + "@" + start.UTC().Format(utcLayout)
+}
+
+// unfold joins continuation lines and drops empty ones.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// splitLine splits "NAME;PARAM=VALUE:content" into its parts. Colons in
+// quoted parameter values do not end the name.
+func splitLine(line string) (string, map[string]string, string, bool) {
+	quoted := false
+	for i, r := range line {
+		switch r {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if quoted {
+				continue
+			}
+
+			params := map[string]string{}
+			head := strings.Split(line[:i], ";")
+			for _, param := range head[1:] {
+				key, value, _ := strings.Cut(param, "=")
+				params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+			}
+			return strings.ToUpper(head[0]), params, line[i+1:], true
+		}
+	}
+
+	return "", nil, "", false
+}
+
+// parseTime reads a DATE or DATE-TIME value. The second result reports
+// whether the value was a whole date.
+func parseTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if tzid := params["TZID"]; tzid != "" {
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			loc = zone
+		}
+	}
+
+	switch {
+	case params["VALUE"] == "DATE" || len(value) == len(dateLayout):
+		t, err := time.ParseInLocation(dateLayout, value, loc)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse(utcLayout, value)
+		return t, false, err
+	default:
+		t, err := time.ParseInLocation(localLayout, value, loc)
+		return t, false, err
+	}
+}
+
+// parseDuration reads a positive RFC 5545 duration such as P1DT2H or PT15M.
+func parseDuration(value string) (time.Duration, error) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(value, "+"), "P")
+	if !ok {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var (
+		duration time.Duration
+		inTime   bool
+		number   int
+	)
+	for _, r := range rest {
+		switch {
+		case r >= '0' && r <= '9':
+			number = number*10 + int(r-'0')
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		case r == 'W':
+			duration += time.Duration(number) * 7 * 24 * time.Hour
+		case r == 'D':
+			duration += time.Duration(number) * 24 * time.Hour
+		case r == 'H' && inTime:
+			duration += time.Duration(number) * time.Hour
+		case r == 'M' && inTime:
+			duration += time.Duration(number) * time.Minute
+		case r == 'S' && inTime:
+			duration += time.Duration(number) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		number = 0
+	}
+
+	return duration, nil
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(value)
+}
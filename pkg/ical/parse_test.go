@@ -0,0 +1,148 @@
+package ical
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parse(t *testing.T, lines ...string) *Calendar {
+	t.Helper()
+
+	document := "BEGIN:VCALENDAR\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VCALENDAR\r\n"
+	calendar, err := Parse(strings.NewReader(document), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return calendar
+}
+
+func TestParseDurationBeforeStart(t *testing.T) {
+	calendar := parse(t,
+		"BEGIN:VEVENT",
+		"UID:a",
+		"DURATION:PT1H30M",
+		"DTSTART:20300105T090000Z",
+		"END:VEVENT",
+	)
+
+	event := calendar.Events[0]
+	want := time.Date(2030, 1, 5, 10, 30, 0, 0, time.UTC)
+	if !event.End.Equal(want) {
+		t.Fatalf("got end %s, want %s", event.End, want)
+	}
+}
+
+func TestExpandWeekStart(t *testing.T) {
+	// the RFC 5545 example: WKST changes which days an every other week
+	// rule picks
+	tests := []struct {
+		wkst string
+		want []int
+	}{
+		{"MO", []int{5, 10, 19, 24}},
+		{"SU", []int{5, 17, 19, 31}},
+		// Monday is the default week start
+		{"", []int{5, 10, 19, 24}},
+	}
+	for _, tt := range tests {
+		rule := "RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU"
+		if tt.wkst != "" {
+			rule += ";WKST=" + tt.wkst
+		}
+		calendar := parse(t,
+			"BEGIN:VEVENT",
+			"UID:a",
+			"DTSTART:19970805T090000Z",
+			"DTEND:19970805T100000Z",
+			rule,
+			"END:VEVENT",
+		)
+
+		events, err := calendar.Expand(time.Date(1997, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(1997, 10, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var days []int
+		for _, event := range events {
+			days = append(days, event.Start.Day())
+		}
+		if len(days) != len(tt.want) {
+			t.Fatalf("WKST=%s: got days %v, want %v", tt.wkst, days, tt.want)
+		}
+		for i := range days {
+			if days[i] != tt.want[i] {
+				t.Fatalf("WKST=%s: got days %v, want %v", tt.wkst, days, tt.want)
+			}
+		}
+	}
+}
+
+func TestExpandTooManyOccurrences(t *testing.T) {
+	calendar := parse(t,
+		"BEGIN:VEVENT",
+		"UID:a",
+		"DTSTART:20300101T000000Z",
+		"DTEND:20300101T000100Z",
+		"RRULE:FREQ=DAILY",
+		"END:VEVENT",
+	)
+
+	from := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := calendar.Expand(from, from.AddDate(0, 0, maxOccurrences+1)); !errors.Is(err, ErrTooManyOccurrences) {
+		t.Fatalf("got %v, want ErrTooManyOccurrences", err)
+	}
+
+	// occurrences before the window do not count against the limit
+	later := from.AddDate(0, 0, maxOccurrences+1)
+	events, err := calendar.Expand(later, later.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 7 {
+		t.Fatalf("got %d occurrences in a week, want 7", len(events))
+	}
+}
+
+func TestWriteParseRoundTrip(t *testing.T) {
+	want := &Calendar{
+		Name:     "Dr. Smith; Cardiology, room 2",
+		Timezone: "Asia/Tashkent",
+		Events: []Event{{
+			UID:          "appointment-1@hospital",
+			Summary:      "Check-up, bring results; fasting",
+			Description:  "Line one\nLine two with a long text that has to be folded over more than one line of seventy five octets",
+			Location:     "Главный корпус, кабинет 12",
+			Start:        time.Date(2030, 3, 1, 4, 0, 0, 0, time.UTC),
+			End:          time.Date(2030, 3, 1, 5, 0, 0, 0, time.UTC),
+			Status:       StatusConfirmed,
+			Sequence:     2,
+			LastModified: time.Date(2030, 2, 1, 12, 0, 0, 0, time.UTC),
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(&buf, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Name != want.Name || got.Timezone != want.Timezone {
+		t.Fatalf("got calendar %q/%q, want %q/%q", got.Name, got.Timezone, want.Name, want.Timezone)
+	}
+	if len(got.Events) != 1 {
+		t.Fatalf("got %d events, want 1", len(got.Events))
+	}
+	g, w := got.Events[0], want.Events[0]
+	if g.UID != w.UID || g.Summary != w.Summary || g.Description != w.Description || g.Location != w.Location ||
+		!g.Start.Equal(w.Start) || !g.End.Equal(w.End) || g.Status != w.Status || g.Sequence != w.Sequence ||
+		!g.LastModified.Equal(w.LastModified) {
+		t.Fatalf("got event %+v, want %+v", g, w)
+	}
+}
@@ -0,0 +1,37 @@
+// Package signature signs and verifies expiring links with HMAC-SHA256.
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalid = errors.New("invalid signature")
+	ErrExpired = errors.New("link has expired")
+)
+
+// Sign returns the hex signature of payload valid until expires.
+func Sign(key, payload string, expires time.Time) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(payload + "|" + strconv.FormatInt(expires.Unix(), 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that signature was made by Sign for payload and expires,
+// and that expires has not passed yet.
+func Verify(key, payload string, expires time.Time, signature string) error {
+	expected := Sign(key, payload, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalid
+	}
+	if time.Now().After(expires) {
+		return ErrExpired
+	}
+
+	return nil
+}
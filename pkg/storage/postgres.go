@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
+
 	"github.com/Abdulazizxoshimov/Hospital/entity"
 	configpkg "github.com/Abdulazizxoshimov/Hospital/config"
+	"github.com/Abdulazizxoshimov/Hospital/pkg/tenant"
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
@@ -16,12 +20,19 @@ import (
 type PostgresDB struct {
 	*pgxpool.Pool
 	Sq Squirrel
+	// Location is the hospital zone used for calendar dates such as
+	// doctor_availability.available_date.
+	Location *time.Location
 }
 
 func New(config *configpkg.Config) (*PostgresDB, error) {
 	var db PostgresDB
 
 	db.Sq = *NewSquirrel()
+	db.Location = config.Location
+	if db.Location == nil {
+		db.Location = time.UTC
+	}
 
 	if err := db.connectDB(config); err != nil {
 		return nil, err
@@ -35,6 +46,10 @@ func (p *PostgresDB) connectDB(config *configpkg.Config) error {
 	if err != nil {
 		return fmt.Errorf("unable to parse db conifg: %s", err.Error())
 	}
+	if len(config.Timezone) != 0 {
+		pgxConfig.ConnConfig.RuntimeParams["timezone"] = config.Timezone
+	}
+	pgxConfig.BeforeAcquire = scopeToTenant
 
 	pgxPool, err := pgxpool.ConnectConfig(context.Background(), pgxConfig)
 	if err != nil {
@@ -46,6 +61,19 @@ func (p *PostgresDB) connectDB(config *configpkg.Config) error {
 	return nil
 }
 
+// scopeToTenant points row level security at the tenant of the context the
+// connection is acquired with. Without one the connection sees no rows, so
+// a query that forgot its tenant fails closed rather than leaking.
+func scopeToTenant(ctx context.Context, conn *pgx.Conn) bool {
+	var id string
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		id = strconv.FormatInt(tenantID, 10)
+	}
+
+	_, err := conn.Exec(ctx, "SELECT set_config('app.tenant_id', $1, false)", id)
+	return err == nil
+}
+
 func (p *PostgresDB) configToString(config *configpkg.Config) string {
 	var conn []string
 	if len(config.DB.Host) != 0 {
@@ -85,6 +113,12 @@ func (p *PostgresDB) Error(err error) error {
 		switch pgErr.Code {
 		case "23505":
 			return entity.ErrorConflict
+		case "23P01":
+			// exclusion constraints only guard time ranges
+			if pgErr.ConstraintName == "appointment_resources_no_overlap" {
+				return entity.ErrorResourceUnavailable
+			}
+			return entity.ErrorSlotOverlap
 		}
 	}
 
@@ -0,0 +1,58 @@
+// Package tenant carries the hospital a request or job works for. The
+// database scopes every connection to the tenant found on its context.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Abdulazizxoshimov/Hospital/entity"
+)
+
+// Header and Query name the tenant of requests made without a token.
+const (
+	Header = "X-Tenant-ID"
+	Query  = "tenant"
+	// Key stores the resolved tenant on the gin context.
+	Key = "tenant_id"
+)
+
+type contextKey struct{}
+
+// NewContext returns ctx scoped to the tenant.
+func NewContext(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ctx is scoped to.
+func FromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(contextKey{}).(int64)
+	return id, ok && id > 0
+}
+
+// Lister lists the hosted tenants.
+type Lister interface {
+	List(ctx context.Context) ([]*entity.Tenant, error)
+}
+
+// Each runs fn once for every tenant with ctx scoped to it. One tenant
+// failing does not stop the others; the errors are returned together.
+func Each(ctx context.Context, lister Lister, fn func(context.Context) error) error {
+	tenants, err := lister.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, t := range tenants {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := fn(NewContext(ctx, t.ID)); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %d: %w", t.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
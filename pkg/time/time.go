@@ -25,3 +25,43 @@ func ParseWorkTime(workTime string) (time.Time, time.Time, error) {
 
 	return startTime, endTime, nil
 }
+
+// Interval is a half-open [Start, End) time range.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ParseClock parses an "HH:MM" wall clock value into its offset from midnight.
+func ParseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock value %q, expected HH:MM", clock)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Split cuts [start, end) into consecutive intervals of the given length.
+// A trailing remainder shorter than length is dropped.
+func Split(start, end time.Time, length time.Duration) []Interval {
+	var intervals []Interval
+	if length <= 0 {
+		return intervals
+	}
+
+	for cur := start; !cur.Add(length).After(end); cur = cur.Add(length) {
+		intervals = append(intervals, Interval{Start: cur, End: cur.Add(length)})
+	}
+
+	return intervals
+}
+
+// OnDate returns the instant at the given wall clock offset on the calendar
+// day of date, in loc. It goes through time.Date rather than adding the
+// offset to midnight so that days with a DST change still get the right
+// wall clock time.
+func OnDate(date time.Time, clock time.Duration, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(),
+		int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, loc)
+}
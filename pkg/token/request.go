@@ -8,16 +8,21 @@ import (
 	"github.com/spf13/cast"
 )
 
-func GetIdFromToken(r *http.Request, cfg *config.Config) (string, int) {
-	var softToken string
+// bearerToken returns the token of the Authorization header, with or
+// without its Bearer prefix, or "" when there is none.
+func bearerToken(r *http.Request) string {
 	token := r.Header.Get("Authorization")
+	if strings.Contains(token, "Bearer") {
+		return strings.TrimPrefix(token, "Bearer ")
+	}
 
-	if token == "" {
+	return token
+}
+
+func GetIdFromToken(r *http.Request, cfg *config.Config) (string, int) {
+	softToken := bearerToken(r)
+	if softToken == "" {
 		return "unauthorized", http.StatusUnauthorized
-	} else if strings.Contains(token, "Bearer") {
-		softToken = strings.TrimPrefix(token, "Bearer ")
-	} else {
-		softToken = token
 	}
 
 	claims, err := ExtractClaim(softToken, []byte(cfg.Token.SignInKey))
@@ -29,15 +34,9 @@ func GetIdFromToken(r *http.Request, cfg *config.Config) (string, int) {
 }
 
 func GetRoleFromToken(r *http.Request, cfg *config.Config) (string, int) {
-	var softToken string
-	token := r.Header.Get("Authorization")
-
-	if token == "" {
+	softToken := bearerToken(r)
+	if softToken == "" {
 		return "unauthorized", http.StatusUnauthorized
-	} else if strings.Contains(token, "Bearer") {
-		softToken = strings.TrimPrefix(token, "Bearer ")
-	} else {
-		softToken = token
 	}
 
 	claims, err := ExtractClaim(softToken, []byte(cfg.Token.SignInKey))
@@ -46,4 +45,21 @@ func GetRoleFromToken(r *http.Request, cfg *config.Config) (string, int) {
 	}
 
 	return cast.ToString(claims["role"]), 0
-}
\ No newline at end of file
+}
+
+// GetTenantFromToken returns the tenant the access token was issued for
+// and whether the request carries a token at all. The tenant is 0 when
+// the token is invalid or has no tenant_id claim.
+func GetTenantFromToken(r *http.Request, cfg *config.Config) (int64, bool) {
+	token := bearerToken(r)
+	if token == "" {
+		return 0, false
+	}
+
+	claims, err := ExtractClaim(token, []byte(cfg.Token.SignInKey))
+	if err != nil {
+		return 0, true
+	}
+
+	return cast.ToInt64(claims["tenant_id"]), true
+}
@@ -15,6 +15,7 @@ type JWTHandler struct {
 	Name       string
 	Aud        []string
 	Role       string
+	TenantID   int64
 	SigningKey string
 	Log        logger.Logger
 	Token      string
@@ -23,10 +24,11 @@ type JWTHandler struct {
 
 type CustomClaims struct {
 	*jwt.Token
-	Sub  string  `json:"sub"`
-	Exp  float64 `json:"exp"`
-	Iat  float64 `json:"iat"`
-	Role string  `json:"role"`
+	Sub      string  `json:"sub"`
+	Exp      float64 `json:"exp"`
+	Iat      float64 `json:"iat"`
+	Role     string  `json:"role"`
+	TenantID int64   `json:"tenant_id"`
 }
 
 func (JWTHandler *JWTHandler) GenerateAuthJWT() (access, refresh string, err error) {
@@ -43,6 +45,7 @@ func (JWTHandler *JWTHandler) GenerateAuthJWT() (access, refresh string, err err
 	claims["exp"] = time.Now().Add(time.Hour * 3).Unix()
 	claims["iat"] = time.Now().Unix()
 	claims["role"] = JWTHandler.Role
+	claims["tenant_id"] = JWTHandler.TenantID
 
 	access, err = accessToken.SignedString([]byte(JWTHandler.SigningKey))
 	if err != nil {
@@ -55,6 +58,7 @@ func (JWTHandler *JWTHandler) GenerateAuthJWT() (access, refresh string, err err
 	rtClaims["exp"] = time.Now().Add(time.Hour * 12).Unix()
 	rtClaims["iat"] = time.Now().Unix()
 	rtClaims["role"] = JWTHandler.Role
+	rtClaims["tenant_id"] = JWTHandler.TenantID
 
 	refresh, err = refreshToken.SignedString([]byte(JWTHandler.SigningKey))
 